@@ -1,6 +1,7 @@
 package block
 
 import (
+	"github.com/flywave/go-dxf/entity"
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/table"
 )
@@ -14,6 +15,7 @@ type Block struct {
 	layer       *table.Layer
 	Flag        int
 	Coord       []float64
+	Entities    entity.Entities
 }
 
 // NewBlock create a new Block.
@@ -25,6 +27,7 @@ func NewBlock(name, desc string) *Block {
 		layer:       table.LY_0,
 		Flag:        0,
 		Coord:       []float64{0.0, 0.0, 0.0},
+		Entities:    entity.New(),
 	}
 	return b
 }
@@ -43,6 +46,9 @@ func (b *Block) Format(f format.Formatter) {
 	}
 	f.WriteString(3, b.Name)
 	f.WriteString(1, b.Description)
+	for _, e := range b.Entities {
+		e.Format(f)
+	}
 	f.WriteString(0, "ENDBLK")
 	f.WriteHex(5, b.endhandle)
 	f.WriteString(100, "AcDbEntity")
@@ -67,10 +73,17 @@ func (b *Block) Handle() int {
 	return b.handle
 }
 
-// SetHandle sets handles to BLOCK and ENDBLK.
+// SetHandle sets handles to BLOCK, its entities and ENDBLK.
 func (b *Block) SetHandle(v *int) {
 	b.handle = *v
 	(*v)++
+	b.Entities.SetHandle(v)
+	b.endhandle = *v
+	(*v)++
+}
+
+// SetEndHandle sets a handle to ENDBLK only.
+func (b *Block) SetEndHandle(v *int) {
 	b.endhandle = *v
 	(*v)++
 }
@@ -84,3 +97,8 @@ func (b *Block) Layer() *table.Layer {
 func (b *Block) SetLayer(layer *table.Layer) {
 	b.layer = layer
 }
+
+// AddEntity adds a new entity to BLOCK.
+func (b *Block) AddEntity(e entity.Entity) {
+	b.Entities = b.Entities.Add(e)
+}
@@ -2,16 +2,61 @@
 package class
 
 import (
+	"fmt"
+
 	"github.com/flywave/go-dxf/format"
 )
 
+// Proxy capabilities flags (code 90)
+const (
+	PROXY_NO_OPERATIONS_ALLOWED   = 0
+	PROXY_ERASE_ALLOWED           = 1
+	PROXY_TRANSFORM_ALLOWED       = 2
+	PROXY_COLOR_CHANGE_ALLOWED    = 4
+	PROXY_LAYER_CHANGE_ALLOWED    = 8
+	PROXY_LINETYPE_CHANGE_ALLOWED = 16
+	PROXY_LINETYPE_SCALE_ALLOWED  = 32
+	PROXY_VISIBILITY_ALLOWED      = 64
+	PROXY_CLONING_ALLOWED         = 128
+	PROXY_LINEWEIGHT_ALLOWED      = 256
+	PROXY_PLOT_STYLE_NAME_ALLOWED = 512
+	PROXY_ALL_OPERATIONS_ALLOWED  = 1023
+	PROXY_DISABLES_PROXY_WARNING  = 1024
+	PROXY_R13_FORMAT_PROXY        = 32768
+)
+
 // Class represents each CLASS.
 type Class struct {
+	Name      string // 1: DXF record name
+	ClassName string // 2: C++ class name
+	AppName   string // 3: application name
+	ProxyFlag int    // 90: proxy capabilities flag
+	Count     int    // 91: instance count
+	WasProxy  bool   // 280: was-a-proxy flag
+	IsEntity  bool   // 281: is-an-entity flag
+}
+
+// NewClass creates a new Class.
+func NewClass(name, className, appName string, isEntity bool) *Class {
+	c := &Class{
+		Name:      name,
+		ClassName: className,
+		AppName:   appName,
+		IsEntity:  isEntity,
+	}
+	return c
 }
 
 // Format writes data to formatter.
 func (c *Class) Format(f format.Formatter) {
 	f.WriteString(0, "CLASS")
+	f.WriteString(1, c.Name)
+	f.WriteString(2, c.ClassName)
+	f.WriteString(3, c.AppName)
+	f.WriteInt(90, c.ProxyFlag)
+	f.WriteInt(91, c.Count)
+	f.WriteInt(280, boolInt(c.WasProxy))
+	f.WriteInt(281, boolInt(c.IsEntity))
 }
 
 // String outputs data using default formatter.
@@ -49,3 +94,26 @@ func (cs Classes) Format(f format.Formatter) {
 func (cs Classes) SetHandle(v *int) {
 	return
 }
+
+// Add adds a new class.
+func (cs Classes) Add(c *Class) Classes {
+	cs = append(cs, c)
+	return cs
+}
+
+// Contains reports if CLASSES has the class of given DXF record name.
+func (cs Classes) Contains(name string) (*Class, error) {
+	for _, c := range cs {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("class %s doesn't exist", name)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
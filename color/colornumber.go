@@ -288,3 +288,19 @@ var (
 		[]uint8{255, 255, 255}, // 255
 	}
 )
+
+// RGB returns 24-bit color value 0x00RRGGBB (code 420).
+func RGB(r, g, b uint8) int {
+	return int(r)<<16 | int(g)<<8 | int(b)
+}
+
+// Transparency returns transparency value (code 440)
+// from ratio t, 0.0 (opaque) to 1.0 (fully transparent).
+func Transparency(t float64) int {
+	if t < 0.0 {
+		t = 0.0
+	} else if t > 1.0 {
+		t = 1.0
+	}
+	return 0x02000000 | int(255.0*(1.0-t)+0.5)
+}
@@ -6,15 +6,22 @@ import (
 
 	dxf "github.com/flywave/go-dxf"
 	"github.com/flywave/go-dxf/entity"
+	"github.com/flywave/go-dxf/geometry"
 	geom "github.com/flywave/go-geom"
 	"github.com/pborman/uuid"
 )
 
+// ArcTolerance is the maximum deviation allowed when arc segments are converted to lines.
+var ArcTolerance = 0.01
+
 func ConvertToGeomFeatures(inputFile string, ty string) (map[string]*geom.FeatureCollection, error) {
 	draw, err := dxf.FromFile(inputFile)
 	if err != nil {
 		return nil, err
 	}
+	if err := draw.ExplodeAll(); err != nil {
+		return nil, err
+	}
 
 	geomMap := make(map[string]*geom.FeatureCollection)
 	ents := draw.Entities()
@@ -42,13 +49,20 @@ func ConvertToGeomFeatures(inputFile string, ty string) (map[string]*geom.Featur
 			l := [][]float64{ety.Start, ety.End}
 			f = geom.NewLineStringFeature(l)
 		case *entity.LwPolyline:
-			f = geom.NewLineStringFeature(ety.Vertices)
+			ocs := geometry.OCS(ety.Direction)
+			ps := ety.Points(ArcTolerance)
+			for i, p := range ps {
+				w := ocs.Apply([]float64{p[0], p[1], ety.Elevation})
+				ps[i] = []float64{w[0], w[1]}
+			}
+			f = geom.NewLineStringFeature(ps)
 			fn(layerName, f)
 			continue
 		case *entity.Text:
 			v := strings.ReplaceAll(ety.Value, " ", "")
 			if val, err := strconv.ParseFloat(v, 64); err == nil {
-				c := []float64{ety.Coord1[0], ety.Coord1[1]}
+				w := geometry.OCS(ety.Direction).Apply(ety.Coord1)
+				c := []float64{w[0], w[1]}
 				f = geom.NewPointFeature(c)
 				f.Properties["value"] = val
 			}
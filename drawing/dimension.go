@@ -0,0 +1,402 @@
+package drawing
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/flywave/go-dxf/entity"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/table"
+)
+
+// LinearDimension creates a new linear DIMENSION measuring p1-p2 along rotation (Degree).
+// The dimension line passes through loc.
+func (d *Drawing) LinearDimension(p1, p2, loc []float64, rotation float64) (*entity.Dimension, error) {
+	dm := entity.NewDimension(entity.DIM_LINEAR)
+	dm.Rotation = rotation
+	u := direction(rotation * math.Pi / 180.0)
+	dm.DefPoint2 = copyPoint3(p1)
+	dm.DefPoint3 = copyPoint3(p2)
+	dm.DefPoint = onLine(p2, loc, u)
+	dm.Measurement = math.Abs(dot2(sub2(p2, p1), u))
+	return dm, d.addDimension(dm)
+}
+
+// AlignedDimension creates a new aligned DIMENSION measuring p1-p2.
+// The dimension line passes through loc.
+func (d *Drawing) AlignedDimension(p1, p2, loc []float64) (*entity.Dimension, error) {
+	dm := entity.NewDimension(entity.DIM_ALIGNED)
+	u := unit2(sub2(p2, p1))
+	dm.DefPoint2 = copyPoint3(p1)
+	dm.DefPoint3 = copyPoint3(p2)
+	dm.DefPoint = onLine(p2, loc, u)
+	dm.Measurement = math.Hypot(p2[0]-p1[0], p2[1]-p1[1])
+	return dm, d.addDimension(dm)
+}
+
+// RadialDimension creates a new radial DIMENSION from center to point p on the curve.
+func (d *Drawing) RadialDimension(center, p []float64, leader float64) (*entity.Dimension, error) {
+	dm := entity.NewDimension(entity.DIM_RADIUS)
+	dm.DefPoint = copyPoint3(center)
+	dm.DefPoint4 = copyPoint3(p)
+	dm.LeaderLength = leader
+	dm.Measurement = math.Hypot(p[0]-center[0], p[1]-center[1])
+	return dm, d.addDimension(dm)
+}
+
+// DiameterDimension creates a new diameter DIMENSION between opposite points p1 and p2 on the curve.
+func (d *Drawing) DiameterDimension(p1, p2 []float64, leader float64) (*entity.Dimension, error) {
+	dm := entity.NewDimension(entity.DIM_DIAMETER)
+	dm.DefPoint = copyPoint3(p2)
+	dm.DefPoint4 = copyPoint3(p1)
+	dm.LeaderLength = leader
+	dm.Measurement = math.Hypot(p2[0]-p1[0], p2[1]-p1[1])
+	return dm, d.addDimension(dm)
+}
+
+// AngularDimension creates a new 3 point angular DIMENSION measuring
+// counterclockwise from p1 to p2 around vertex. The dimension arc passes through loc.
+func (d *Drawing) AngularDimension(vertex, p1, p2, loc []float64) (*entity.Dimension, error) {
+	dm := entity.NewDimension(entity.DIM_ANGULAR3P)
+	dm.DefPoint = copyPoint3(loc)
+	dm.DefPoint2 = copyPoint3(p1)
+	dm.DefPoint3 = copyPoint3(p2)
+	dm.DefPoint4 = copyPoint3(vertex)
+	a1, a2 := angle2(sub2(p1, vertex)), angle2(sub2(p2, vertex))
+	dm.Measurement = normalizeAngle(a2 - a1)
+	return dm, d.addDimension(dm)
+}
+
+// OrdinateDimension creates a new ordinate DIMENSION of feature measured from origin.
+// If xtype is true, it measures X coordinate, otherwise Y coordinate. The leader ends at leader.
+func (d *Drawing) OrdinateDimension(origin, feature, leader []float64, xtype bool) (*entity.Dimension, error) {
+	dm := entity.NewDimension(entity.DIM_ORDINATE)
+	dm.DefPoint = copyPoint3(origin)
+	dm.DefPoint2 = copyPoint3(feature)
+	dm.DefPoint3 = copyPoint3(leader)
+	if xtype {
+		dm.Type |= entity.DIM_ORDINATE_X
+		dm.Measurement = feature[0] - origin[0]
+	} else {
+		dm.Measurement = feature[1] - origin[1]
+	}
+	return dm, d.addDimension(dm)
+}
+
+// addDimension sets current layer and dimension style, generates its block and adds it.
+func (d *Drawing) addDimension(dm *entity.Dimension) error {
+	dm.SetLayer(d.CurrentLayer)
+	dm.DimStyle = d.dimStyle("Standard")
+	if err := d.RenderDimension(dm); err != nil {
+		return err
+	}
+	d.AddEntity(dm)
+	return nil
+}
+
+// RenderDimension generates the anonymous block (*Dn) drawing Dimension,
+// and sets BlockName and TextPoint of Dimension.
+// Only dimensions on XY plane are supported: the geometry is built from X and Y
+// of the definition points, and an error is returned for other extrusion directions.
+func (d *Drawing) RenderDimension(dm *entity.Dimension) error {
+	if n := geometry.Normalize(dm.Direction); math.Abs(n[0]) > 1e-9 || math.Abs(n[1]) > 1e-9 || n[2] <= 0.0 {
+		return fmt.Errorf("dimension with extrusion direction %v is not supported", dm.Direction)
+	}
+	name := d.anonymousBlockName("*D")
+	b, err := d.AddBlock(name, "", 0.0, 0.0, 0.0)
+	if err != nil {
+		return err
+	}
+	b.Flag = 1 // anonymous
+	r := &dimRenderer{dm: dm, style: dm.DimStyle}
+	switch dm.DimType() {
+	case entity.DIM_LINEAR, entity.DIM_ALIGNED:
+		r.linear()
+	case entity.DIM_RADIUS:
+		r.radial(dm.DefPoint, dm.DefPoint4, "R")
+	case entity.DIM_DIAMETER:
+		r.diameter()
+	case entity.DIM_ANGULAR3P:
+		r.angular()
+	case entity.DIM_ORDINATE:
+		r.ordinate()
+	default:
+		return fmt.Errorf("dimension type %d is not supported", dm.DimType())
+	}
+	for _, e := range r.entities {
+		b.AddEntity(e)
+	}
+	dm.BlockName = name
+	dm.Type |= entity.DIM_BLOCK_EXCLUSIVE
+	return nil
+}
+
+// anonymousBlockName returns an unused block name of prefix and number.
+func (d *Drawing) anonymousBlockName(prefix string) string {
+	n := 0
+	for _, b := range d.Blocks() {
+		if !strings.HasPrefix(strings.ToUpper(b.Name), prefix) {
+			continue
+		}
+		if v, err := strconv.Atoi(b.Name[len(prefix):]); err == nil && v >= n {
+			n = v + 1
+		}
+	}
+	return prefix + strconv.Itoa(n)
+}
+
+// dimRenderer generates entities of dimension block.
+type dimRenderer struct {
+	dm       *entity.Dimension
+	style    *table.DimStyle
+	entities entity.Entities
+}
+
+// size returns a dimension style size multiplied by DIMSCALE.
+func (r *dimRenderer) size(v float64) float64 {
+	if r.style.Scale > 0.0 {
+		return v * r.style.Scale
+	}
+	return v
+}
+
+func (r *dimRenderer) line(p1, p2 []float64) {
+	l := entity.NewLine()
+	l.Start = []float64{p1[0], p1[1], 0.0}
+	l.End = []float64{p2[0], p2[1], 0.0}
+	l.SetLayer(table.LY_0)
+	r.entities = append(r.entities, l)
+}
+
+// arrow draws a filled arrow head whose tip is at p pointing to direction u.
+func (r *dimRenderer) arrow(p, u []float64) {
+	size := r.size(r.style.ArrowSize)
+	l := entity.NewLwPolyline(2)
+	l.Vertices[0] = []float64{p[0], p[1]}
+	l.Vertices[1] = []float64{p[0] - u[0]*size, p[1] - u[1]*size}
+	l.SetWidth(0, 0.0, size/3.0)
+	l.SetLayer(table.LY_0)
+	r.entities = append(r.entities, l)
+}
+
+// text puts the measurement text centered at p with rotation (Degree).
+func (r *dimRenderer) text(p []float64, rotation float64, prefix, suffix string) {
+	t := entity.NewMText()
+	t.Coord = []float64{p[0], p[1], 0.0}
+	t.Height = r.size(r.style.TextHeight)
+	t.Attachment = entity.MTEXT_MIDDLE_CENTER
+	t.SetRotation(rotation)
+	t.Value = r.label(prefix, suffix)
+	t.SetLayer(table.LY_0)
+	r.entities = append(r.entities, t)
+	if r.dm.Type&entity.DIM_USER_TEXT == 0 {
+		r.dm.TextPoint = []float64{p[0], p[1], 0.0}
+	}
+}
+
+// label returns the dimension text, applying text override.
+func (r *dimRenderer) label(prefix, suffix string) string {
+	v := strconv.FormatFloat(r.dm.Measurement, 'f', r.style.Decimals, 64)
+	if r.dm.DimType() == entity.DIM_ANGULAR3P || r.dm.DimType() == entity.DIM_ANGULAR {
+		v = strconv.FormatFloat(r.dm.Measurement*180.0/math.Pi, 'f', r.style.Decimals, 64)
+	}
+	if r.dm.DimType() == entity.DIM_ORDINATE {
+		v = strconv.FormatFloat(math.Abs(r.dm.Measurement), 'f', r.style.Decimals, 64)
+	}
+	measured := prefix + v + suffix
+	switch {
+	case r.dm.Text == "":
+		return measured
+	case r.dm.Text == " ":
+		return ""
+	default:
+		return strings.ReplaceAll(r.dm.Text, "<>", measured)
+	}
+}
+
+// offset returns the distance from dimension line to the center of text.
+func (r *dimRenderer) offset() float64 {
+	return r.size(r.style.TextGap) + r.size(r.style.TextHeight)/2.0
+}
+
+// linear draws extension lines, dimension line with arrows and text.
+func (r *dimRenderer) linear() {
+	dm := r.dm
+	var u []float64
+	if dm.DimType() == entity.DIM_LINEAR {
+		u = direction(dm.Rotation * math.Pi / 180.0)
+	} else {
+		u = unit2(sub2(dm.DefPoint3, dm.DefPoint2))
+	}
+	d2 := dm.DefPoint
+	d1 := onLine(dm.DefPoint2, d2, u)
+	r.extension(dm.DefPoint2, d1)
+	r.extension(dm.DefPoint3, d2)
+	r.line(d1, d2)
+	v := unit2(sub2(d2, d1))
+	if math.Hypot(v[0], v[1]) == 0.0 {
+		v = u
+	}
+	r.arrow(d1, []float64{-v[0], -v[1]})
+	r.arrow(d2, v)
+	n := []float64{-u[1], u[0]}
+	mid := []float64{(d1[0] + d2[0]) / 2.0, (d1[1] + d2[1]) / 2.0}
+	rot := readableAngle(angle2(u))
+	if rot != angle2(u) {
+		n = []float64{-n[0], -n[1]}
+	}
+	o := r.offset()
+	r.text([]float64{mid[0] + n[0]*o, mid[1] + n[1]*o}, rot*180.0/math.Pi, "", "")
+}
+
+// extension draws an extension line from origin p to the dimension line point q.
+func (r *dimRenderer) extension(p, q []float64) {
+	v := sub2(q, p)
+	l := math.Hypot(v[0], v[1])
+	if l == 0.0 {
+		return
+	}
+	u := []float64{v[0] / l, v[1] / l}
+	exo, exe := r.size(r.style.ExtensionOffset), r.size(r.style.ExtensionExtend)
+	if l <= exo {
+		return
+	}
+	r.line([]float64{p[0] + u[0]*exo, p[1] + u[1]*exo}, []float64{q[0] + u[0]*exe, q[1] + u[1]*exe})
+}
+
+// radial draws a line from center to p with an arrow at p and text beyond p.
+func (r *dimRenderer) radial(center, p []float64, prefix string) {
+	u := unit2(sub2(p, center))
+	r.line(center, p)
+	r.arrow(p, u)
+	r.leaderText(p, u, prefix)
+}
+
+// leaderText draws a leader of LeaderLength from p along u and text at its end.
+func (r *dimRenderer) leaderText(p, u []float64, prefix string) {
+	leader := r.dm.LeaderLength
+	if leader <= 0.0 {
+		leader = 2.0 * r.size(r.style.ArrowSize)
+	}
+	q := []float64{p[0] + u[0]*leader, p[1] + u[1]*leader}
+	r.line(p, q)
+	rot := readableAngle(angle2(u))
+	w := float64(len([]rune(r.label(prefix, "")))) * r.size(r.style.TextHeight) * 0.5
+	o := r.size(r.style.TextGap) + w
+	r.text([]float64{q[0] + u[0]*o, q[1] + u[1]*o}, rot*180.0/math.Pi, prefix, "")
+}
+
+// diameter draws the diameter line with arrows at both ends and text beyond DefPoint4.
+func (r *dimRenderer) diameter() {
+	p1, p2 := r.dm.DefPoint4, r.dm.DefPoint
+	u := unit2(sub2(p1, p2))
+	r.line(p2, p1)
+	r.arrow(p1, u)
+	r.arrow(p2, []float64{-u[0], -u[1]})
+	r.leaderText(p1, u, "%%c")
+}
+
+// angular draws the dimension arc with arrows, extension lines and text.
+func (r *dimRenderer) angular() {
+	dm := r.dm
+	c := dm.DefPoint4
+	radius := math.Hypot(dm.DefPoint[0]-c[0], dm.DefPoint[1]-c[1])
+	a1 := angle2(sub2(dm.DefPoint2, c))
+	a2 := a1 + dm.Measurement
+	arc := entity.NewArc(nil)
+	arc.Center = []float64{c[0], c[1], 0.0}
+	arc.Radius = radius
+	arc.Angle = []float64{a1 * 180.0 / math.Pi, a2 * 180.0 / math.Pi}
+	arc.SetLayer(table.LY_0)
+	r.entities = append(r.entities, arc)
+	e1 := []float64{c[0] + radius*math.Cos(a1), c[1] + radius*math.Sin(a1)}
+	e2 := []float64{c[0] + radius*math.Cos(a2), c[1] + radius*math.Sin(a2)}
+	r.extension(dm.DefPoint2, e1)
+	r.extension(dm.DefPoint3, e2)
+	r.arrow(e1, []float64{math.Sin(a1), -math.Cos(a1)})
+	r.arrow(e2, []float64{-math.Sin(a2), math.Cos(a2)})
+	m := (a1 + a2) / 2.0
+	o := radius + r.offset()
+	rot := readableAngle(m - math.Pi/2.0)
+	r.text([]float64{c[0] + o*math.Cos(m), c[1] + o*math.Sin(m)}, rot*180.0/math.Pi, "", "%%d")
+}
+
+// ordinate draws the leader from feature to leader end and text.
+func (r *dimRenderer) ordinate() {
+	dm := r.dm
+	f, q := dm.DefPoint2, dm.DefPoint3
+	u := []float64{0.0, 1.0}
+	if dm.Type&entity.DIM_ORDINATE_X == 0 {
+		u = []float64{1.0, 0.0}
+	}
+	if dot2(sub2(q, f), u) < 0.0 {
+		u = []float64{-u[0], -u[1]}
+	}
+	r.extension(f, q)
+	rot := 0.0
+	if dm.Type&entity.DIM_ORDINATE_X != 0 {
+		rot = 90.0
+	}
+	w := float64(len([]rune(r.label("", "")))) * r.size(r.style.TextHeight) * 0.5
+	o := r.size(r.style.TextGap) + w
+	r.text([]float64{q[0] + u[0]*o, q[1] + u[1]*o}, rot, "", "")
+}
+
+// direction returns 2D unit vector of angle (Radian).
+func direction(a float64) []float64 {
+	return []float64{math.Cos(a), math.Sin(a)}
+}
+
+// sub2 returns 2D vector p - q.
+func sub2(p, q []float64) []float64 {
+	return []float64{p[0] - q[0], p[1] - q[1]}
+}
+
+// dot2 returns 2D dot product.
+func dot2(a, b []float64) float64 {
+	return a[0]*b[0] + a[1]*b[1]
+}
+
+// unit2 returns 2D unit vector of v.
+func unit2(v []float64) []float64 {
+	n := geometry.Normalize([]float64{v[0], v[1], 0.0})
+	return n[:2]
+}
+
+// angle2 returns angle of 2D vector v (Radian).
+func angle2(v []float64) float64 {
+	return math.Atan2(v[1], v[0])
+}
+
+// onLine returns the projection of p onto the line through q along u.
+func onLine(p, q, u []float64) []float64 {
+	t := dot2(sub2(p, q), u)
+	return []float64{q[0] + u[0]*t, q[1] + u[1]*t, 0.0}
+}
+
+// normalizeAngle returns angle a in range [0, 2*Pi).
+func normalizeAngle(a float64) float64 {
+	a = math.Mod(a, 2.0*math.Pi)
+	if a < 0.0 {
+		a += 2.0 * math.Pi
+	}
+	return a
+}
+
+// readableAngle returns angle a turned by Pi if text would be upside down.
+func readableAngle(a float64) float64 {
+	a = normalizeAngle(a)
+	if a > math.Pi/2.0+1e-9 && a <= 3.0*math.Pi/2.0+1e-9 {
+		a -= math.Pi
+	}
+	return a
+}
+
+// copyPoint3 returns a 3D copy of p.
+func copyPoint3(p []float64) []float64 {
+	c := []float64{0.0, 0.0, 0.0}
+	copy(c, p)
+	return c
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/flywave/go-dxf/block"
 	"github.com/flywave/go-dxf/class"
@@ -25,6 +26,7 @@ type Drawing struct {
 	Layers       map[string]*table.Layer
 	Groups       map[string]*object.Group
 	Styles       map[string]*table.Style
+	DimStyles    map[string]*table.DimStyle
 	CurrentLayer *table.Layer
 	CurrentStyle *table.Style
 	formatter    format.Formatter
@@ -32,6 +34,12 @@ type Drawing struct {
 	dictionary   *object.Dictionary
 	groupdict    *object.Dictionary
 	PlotStyle    handle.Handler
+	handles      map[int]handle.Handler // elements by handle in the source file
+	endhandles   map[handle.Handler]int // handles of SEQEND and ENDBLK in the source file by their owners
+	current      map[int]handle.Handler // elements by their current handles for ByHandle
+	// PreserveHandles keeps handles read from the file on writing.
+	// New elements are given handles above $HANDSEED of the file.
+	PreserveHandles bool
 	// savebuff is used internally for the io.Reader options.
 	savebuff *bytes.Buffer
 }
@@ -46,6 +54,10 @@ func New() *Drawing {
 	d.Styles = make(map[string]*table.Style)
 	d.Styles["STANDARD"] = table.ST_STANDARD
 	d.CurrentStyle = d.Styles["STANDARD"]
+	d.DimStyles = make(map[string]*table.DimStyle)
+	d.handles = make(map[int]handle.Handler)
+	d.endhandles = make(map[handle.Handler]int)
+	d.current = make(map[int]handle.Handler)
 	d.formatter = format.NewASCII()
 	d.formatter.SetPrecision(16)
 	d.Sections = []Section{
@@ -57,13 +69,13 @@ func New() *Drawing {
 		object.New(),
 	}
 	d.dictionary = object.NewDictionary()
-	d.addObject(d.dictionary)
+	d.AddObject(d.dictionary)
 	wd, ph := object.NewAcDbDictionaryWDFLT(d.dictionary)
 	d.dictionary.AddItem("ACAD_PLOTSTYLENAME", wd)
-	d.addObject(wd)
-	d.addObject(ph)
+	d.AddObject(wd)
+	d.AddObject(ph)
 	d.groupdict = object.NewDictionary()
-	d.addObject(d.groupdict)
+	d.AddObject(d.groupdict)
 	d.dictionary.AddItem("ACAD_GROUP", d.groupdict)
 	d.PlotStyle = ph
 	d.Layers["0"].SetPlotStyle(d.PlotStyle)
@@ -95,13 +107,250 @@ func (d *Drawing) SaveAs(filename string) error {
 	return d.saveFile(filename)
 }
 
+// SetFormatter sets the formatter used by WriteTo.
+// Use format.NewBinary() to write binary DXF.
+func (d *Drawing) SetFormatter(f format.Formatter) {
+	d.formatter = f
+	d.savebuff = nil
+}
+
+// SetVersion sets the DXF version to write, from format.R12 to format.R2018.
+// Without SetVersion, the version of $ACADVER read from the file is written.
+// Each section is written with the group codes the version expects:
+// for example R12 has no subclass markers, handles, CLASSES and OBJECTS,
+// LWPOLYLINE, ELLIPSE, SPLINE and LEADER are converted into POLYLINE and MTEXT into TEXT,
+// HATCH before R14 is converted into POLYLINEs of the boundaries,
+// and MULTILEADER before R2007 into LINEs and MTEXT.
+// Records and group codes are kept according to tables of the versions which introduced them:
+// R12 output has only the codes of the R12 reference for each record type and header variable,
+// true color and transparency are written for R2007 and later, and gradient of HATCH for R2004 and later.
+// Codes of record types the tables don't list follow the version of their code range,
+// so R2010, R2013 and R2018 output differs from R2007 only in $ACADVER
+// unless the drawing has codes introduced in those versions, such as ones read from a file.
+// Strings are written in UTF-8 for R2007 and later, otherwise in the code page of $DWGCODEPAGE.
+func (d *Drawing) SetVersion(v format.Version) error {
+	if v.String() == "" {
+		return fmt.Errorf("unknown version: %d", v)
+	}
+	d.Header().Version = v.String()
+	return nil
+}
+
+// Version returns the DXF version of $ACADVER.
+func (d *Drawing) Version() (format.Version, error) {
+	return format.ParseVersion(d.Header().Version)
+}
+
 // setHandle sets all the handles contained in Drawing.
 func (d *Drawing) setHandle() {
+	if d.PreserveHandles && len(d.handles) > 0 {
+		d.SetSourceHandles()
+		return
+	}
 	h := 1
 	for _, s := range d.Sections[1:] {
 		s.SetHandle(&h)
 	}
 	d.Sections[0].SetHandle(&h)
+	d.indexHandles()
+}
+
+// SetSourceHandles sets the handles read from the file to the elements,
+// including SEQEND and ENDBLK, and new handles above $HANDSEED of the file to the others.
+// It is called after reading a file, and on writing if PreserveHandles is set.
+// Handles set by containers to their children are overwritten by visiting children after them.
+func (d *Drawing) SetSourceHandles() {
+	source := make(map[handle.Handler]int, len(d.handles))
+	next := d.Header().HandSeed()
+	for h, e := range d.handles {
+		if s, exist := source[e]; !exist || h < s {
+			source[e] = h
+		}
+		if h >= next {
+			next = h + 1
+		}
+	}
+	for _, h := range d.endhandles {
+		if h >= next {
+			next = h + 1
+		}
+	}
+	d.current = make(map[int]handle.Handler, len(d.handles))
+	set := func(e handle.Handler) {
+		if h, exist := source[e]; exist {
+			e.SetHandle(&h)
+		} else {
+			e.SetHandle(&next)
+		}
+		d.current[e.Handle()] = e
+	}
+	setEnd := func(e handle.Handler, s interface{ SetEndHandle(*int) }) {
+		if h, exist := d.endhandles[e]; exist {
+			s.SetEndHandle(&h)
+		} else {
+			s.SetEndHandle(&next)
+		}
+	}
+	setEntity := func(e entity.Entity) {
+		set(e)
+		if p, ok := e.(*entity.Polyline); ok {
+			for _, v := range p.Vertices {
+				set(v)
+			}
+			setEnd(p, p)
+		}
+		if i, ok := e.(*entity.Insert); ok && len(i.Attributes) > 0 {
+			for _, a := range i.Attributes {
+				set(a)
+			}
+			setEnd(i, i)
+		}
+	}
+	var setObject func(o handle.Handler)
+	setObject = func(o handle.Handler) {
+		set(o)
+		if dict, ok := o.(*object.Dictionary); ok {
+			for _, key := range dict.Keys() {
+				item, _ := dict.Item(key)
+				setObject(item)
+			}
+		}
+	}
+	for _, t := range d.Sections[TABLES].(table.Tables) {
+		set(t)
+		for _, st := range t.Records() {
+			set(st)
+		}
+	}
+	for _, b := range d.Blocks() {
+		set(b)
+		for _, e := range b.Entities {
+			setEntity(e)
+		}
+		setEnd(b, b)
+	}
+	for _, e := range d.Entities() {
+		setEntity(e)
+	}
+	for _, o := range d.Objects() {
+		setObject(o)
+	}
+	d.Sections[HEADER].SetHandle(&next)
+}
+
+// ByHandle returns the entity, table record or object which currently has handle h.
+// After reading a file, elements have the handles read from the file
+// until WriteTo assigns new ones, unless PreserveHandles is set.
+// Elements added after reading or writing have no handle until the next WriteTo.
+func (d *Drawing) ByHandle(h int) (handle.Handler, error) {
+	if e, exist := d.current[h]; exist && h != 0 && e.Handle() == h {
+		return e, nil
+	}
+	return nil, fmt.Errorf("handle %X doesn't exist", h)
+}
+
+// indexHandles records the elements by their current handles for ByHandle.
+func (d *Drawing) indexHandles() {
+	d.current = make(map[int]handle.Handler, len(d.current))
+	add := func(e handle.Handler) {
+		if h := e.Handle(); h != 0 {
+			d.current[h] = e
+		}
+	}
+	for _, t := range d.Sections[TABLES].(table.Tables) {
+		add(t)
+		for _, st := range t.Records() {
+			add(st)
+		}
+	}
+	es := d.Entities()
+	for _, b := range d.Blocks() {
+		add(b)
+		es = append(es[:len(es):len(es)], b.Entities...)
+	}
+	for _, e := range es {
+		add(e)
+		if p, ok := e.(*entity.Polyline); ok {
+			for _, v := range p.Vertices {
+				add(v)
+			}
+		}
+		if i, ok := e.(*entity.Insert); ok {
+			for _, a := range i.Attributes {
+				add(a)
+			}
+		}
+	}
+	for _, o := range d.Objects() {
+		add(o)
+	}
+}
+
+// registerAppIDs adds applications which have XDATA to APPID table.
+func (d *Drawing) registerAppIDs() {
+	type holder interface {
+		XDataApps() []string
+	}
+	apps := make([]string, 0)
+	collect := func(x interface{}) {
+		if h, ok := x.(holder); ok {
+			apps = append(apps, h.XDataApps()...)
+		}
+		if i, ok := x.(*entity.Insert); ok {
+			for _, a := range i.Attributes {
+				apps = append(apps, a.XDataApps()...)
+			}
+		}
+	}
+	for _, e := range d.Entities() {
+		collect(e)
+	}
+	for _, b := range d.Blocks() {
+		for _, e := range b.Entities {
+			collect(e)
+		}
+	}
+	tables := d.Sections[TABLES].(table.Tables)
+	for _, t := range tables {
+		for _, st := range t.Records() {
+			collect(st)
+		}
+	}
+	for _, app := range apps {
+		if _, err := tables[table.APPID].Contains(app); err != nil {
+			tables[table.APPID].Add(table.NewAppID(app))
+		}
+	}
+}
+
+// RegisterHandle records that element had handle h in the source file,
+// so that references to h can be resolved by SourceHandle after reading.
+func (d *Drawing) RegisterHandle(h int, element handle.Handler) {
+	if h != 0 {
+		d.handles[h] = element
+		d.current[h] = element
+	}
+}
+
+// RegisterEndHandle records that SEQEND or ENDBLK of element had handle h in the source file,
+// so that it is kept by SetSourceHandles.
+func (d *Drawing) RegisterEndHandle(h int, element handle.Handler) {
+	if h != 0 {
+		d.endhandles[element] = h
+	}
+}
+
+// SourceHandle returns the element which had handle h in the source file.
+func (d *Drawing) SourceHandle(h int) (handle.Handler, error) {
+	if e, exist := d.handles[h]; exist {
+		return e, nil
+	}
+	return nil, fmt.Errorf("handle %X doesn't exist", h)
+}
+
+// RootDictionary returns the root dictionary of OBJECTS section.
+func (d *Drawing) RootDictionary() *object.Dictionary {
+	return d.dictionary
 }
 
 func (d *Drawing) Header() *header.Header {
@@ -180,6 +429,39 @@ func (d *Drawing) AddStyle(name string, fontname, bigfontname string, setcurrent
 	return s, nil
 }
 
+// DimStyle returns the named dimension style.
+// Names are compared case-insensitively, as in DIMSTYLE table.
+func (d *Drawing) DimStyle(name string) (*table.DimStyle, error) {
+	if s, exist := d.DimStyles[name]; exist {
+		return s, nil
+	}
+	s, err := d.Sections[TABLES].(table.Tables)[table.DIMSTYLE].Contains(name)
+	if err != nil {
+		return nil, fmt.Errorf("dimstyle %s", err.Error())
+	}
+	return s.(*table.DimStyle), nil
+}
+
+// AddDimStyle adds a new dimension style with default values.
+// If a dimension style of the same name in any case exists, it is returned with an error.
+func (d *Drawing) AddDimStyle(name string) (*table.DimStyle, error) {
+	if s, err := d.DimStyle(name); err == nil {
+		return s, fmt.Errorf("dimstyle %s already exists", name)
+	}
+	return d.dimStyle(name), nil
+}
+
+// dimStyle returns the named dimension style, adding it with default values if it doesn't exist.
+func (d *Drawing) dimStyle(name string) *table.DimStyle {
+	if s, err := d.DimStyle(name); err == nil {
+		return s
+	}
+	s := table.NewDimStyle(name)
+	d.DimStyles[name] = s
+	d.Sections[TABLES].(table.Tables)[table.DIMSTYLE].Add(s)
+	return s
+}
+
 // LineType returns the named line type if exists.
 func (d *Drawing) LineType(name string) (*table.LineType, error) {
 	lt, err := d.Sections[TABLES].(table.Tables)[table.LTYPE].Contains(name)
@@ -200,6 +482,34 @@ func (d *Drawing) AddLineType(name string, desc string, ls ...float64) (*table.L
 	return newlt, nil
 }
 
+// Blocks returns slice of all blocks contained in Drawing.
+func (d *Drawing) Blocks() block.Blocks {
+	return d.Sections[BLOCKS].(block.Blocks)
+}
+
+// Block returns the named block if exists.
+func (d *Drawing) Block(name string) (*block.Block, error) {
+	for _, b := range d.Blocks() {
+		if strings.EqualFold(b.Name, name) {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("block %s doesn't exist", name)
+}
+
+// AddBlock adds a new block with given name, description and base point (x, y, z).
+// BLOCK_RECORD for the block is also added.
+func (d *Drawing) AddBlock(name, desc string, x, y, z float64) (*block.Block, error) {
+	if b, err := d.Block(name); err == nil {
+		return b, fmt.Errorf("block %s already exists", name)
+	}
+	b := block.NewBlock(name, desc)
+	b.Coord = []float64{x, y, z}
+	d.Sections[BLOCKS] = d.Blocks().Add(b)
+	d.Sections[TABLES].(table.Tables)[table.BLOCK_RECORD].Add(table.NewBlockRecord(name))
+	return b, nil
+}
+
 // Entities returns slice of all entities contained in Drawing.
 func (d *Drawing) Entities() entity.Entities {
 	return d.Sections[ENTITIES].(entity.Entities)
@@ -252,6 +562,20 @@ func (d *Drawing) Arc(x, y, z, r, start, end float64) (*entity.Arc, error) {
 	return a, nil
 }
 
+// Ellipse creates a new ELLIPSE at (x, y, z) on XY plane.
+// Major axis is (mx, my, 0) relative to the center, and start and end are parameters (Radian).
+func (d *Drawing) Ellipse(x, y, z, mx, my, ratio, start, end float64) (*entity.Ellipse, error) {
+	e := entity.NewEllipse()
+	e.Center = []float64{x, y, z}
+	e.MajorAxis = []float64{mx, my, 0.0}
+	e.Ratio = ratio
+	e.Start = start
+	e.End = end
+	e.SetLayer(d.CurrentLayer)
+	d.AddEntity(e)
+	return e, nil
+}
+
 // Polyline creates a new POLYLINE with given vertices.
 func (d *Drawing) Polyline(closed bool, vertices ...[]float64) (*entity.Polyline, error) {
 	p := entity.NewPolyline()
@@ -281,6 +605,20 @@ func (d *Drawing) LwPolyline(closed bool, vertices ...[]float64) (*entity.LwPoly
 	return l, nil
 }
 
+// Insert creates a new INSERT of the named block at (x, y, z).
+// If the named block doesn't exist, returns error.
+func (d *Drawing) Insert(name string, x, y, z float64) (*entity.Insert, error) {
+	b, err := d.Block(name)
+	if err != nil {
+		return nil, err
+	}
+	i := entity.NewInsert(b.Name)
+	i.Coord = []float64{x, y, z}
+	i.SetLayer(d.CurrentLayer)
+	d.AddEntity(i)
+	return i, nil
+}
+
 // ThreeDFace creates a new 3DFACE with given points.
 func (d *Drawing) ThreeDFace(points [][]float64) (*entity.ThreeDFace, error) {
 	f := entity.New3DFace()
@@ -300,6 +638,62 @@ func (d *Drawing) ThreeDFace(points [][]float64) (*entity.ThreeDFace, error) {
 	return f, nil
 }
 
+// Hatch creates a new solid HATCH bounded by a closed polyline of given vertices.
+func (d *Drawing) Hatch(vertices ...[]float64) (*entity.Hatch, error) {
+	h := entity.NewHatch()
+	h.AddPolylinePath(vertices...)
+	h.SetLayer(d.CurrentLayer)
+	d.AddEntity(h)
+	return h, nil
+}
+
+// MText creates a new MTEXT at (x, y, z).
+// Width is the reference rectangle width, and 0 means no wrapping.
+func (d *Drawing) MText(str string, x, y, z, height, width float64) (*entity.MText, error) {
+	t := entity.NewMText()
+	t.Coord = []float64{x, y, z}
+	t.Height = height
+	t.Width = width
+	t.Value = str
+	t.SetLayer(d.CurrentLayer)
+	t.Style = d.CurrentStyle
+	d.AddEntity(t)
+	return t, nil
+}
+
+// Leader creates a new LEADER through vertices with an arrow at the first vertex.
+func (d *Drawing) Leader(vertices ...[]float64) (*entity.Leader, error) {
+	if len(vertices) < 2 {
+		return nil, fmt.Errorf("leader needs at least 2 vertices")
+	}
+	l := entity.NewLeader()
+	for _, v := range vertices {
+		l.AddVertex(v[0], v[1], v[2])
+	}
+	l.DimStyle = d.dimStyle("Standard")
+	l.SetLayer(d.CurrentLayer)
+	d.AddEntity(l)
+	return l, nil
+}
+
+// MLeader creates a new MULTILEADER with text str at (x, y, z)
+// and a leader line through vertices, where the last vertex is the landing point.
+func (d *Drawing) MLeader(str string, x, y, z, height float64, vertices ...[]float64) (*entity.MLeader, error) {
+	if len(vertices) < 2 {
+		return nil, fmt.Errorf("multileader needs at least 2 vertices")
+	}
+	l := entity.NewMLeader()
+	l.Context.TextHeight = height
+	l.Context.BasePoint = []float64{x, y, z}
+	l.Context.Text.Value = str
+	l.Context.Text.Location = []float64{x, y, z}
+	n := len(vertices) - 1
+	l.AddLeader(vertices[n], vertices[:n]...)
+	l.SetLayer(d.CurrentLayer)
+	d.AddEntity(l)
+	return l, nil
+}
+
 // Text creates a new TEXT str at (x, y, z) with given height.
 func (d *Drawing) Text(str string, x, y, z, height float64) (*entity.Text, error) {
 	t := entity.NewText()
@@ -315,7 +709,28 @@ func (d *Drawing) Text(str string, x, y, z, height float64) (*entity.Text, error
 	return t, nil
 }
 
-func (d *Drawing) addObject(o object.Object) {
+// Classes returns CLASSES section.
+func (d *Drawing) Classes() class.Classes {
+	return d.Sections[CLASSES].(class.Classes)
+}
+
+// AddClass adds a new class.
+// If the class of the same DXF record name exists, it returns the existing one with an error.
+func (d *Drawing) AddClass(c *class.Class) (*class.Class, error) {
+	if e, err := d.Classes().Contains(c.Name); err == nil {
+		return e, fmt.Errorf("class %s already exists", c.Name)
+	}
+	d.Sections[CLASSES] = d.Classes().Add(c)
+	return c, nil
+}
+
+// Objects returns OBJECTS section.
+func (d *Drawing) Objects() object.Objects {
+	return d.Sections[OBJECTS].(object.Objects)
+}
+
+// AddObject adds a new object.
+func (d *Drawing) AddObject(o object.Object) {
 	d.Sections[5] = d.Sections[5].(object.Objects).Add(o)
 }
 
@@ -329,10 +744,22 @@ func (d *Drawing) Group(name, desc string, es ...entity.Entity) (*object.Group,
 	g := object.NewGroup(name, desc, es...)
 	d.Groups[name] = g
 	g.SetOwner(d.groupdict)
-	d.addObject(g)
+	d.AddObject(g)
 	return g, nil
 }
 
+// AddGroup adds a group made outside of Drawing, such as one read from a file.
+// If the group of the same name exists, returns error.
+func (d *Drawing) AddGroup(g *object.Group) error {
+	if _, exist := d.Groups[g.Name]; exist {
+		return fmt.Errorf("group %s already exists", g.Name)
+	}
+	d.Groups[g.Name] = g
+	g.SetOwner(d.groupdict)
+	d.AddObject(g)
+	return nil
+}
+
 // AddToGroup adds given entities to the named group.
 // If the named group doesn't exist, returns error.
 func (d *Drawing) AddToGroup(name string, es ...entity.Entity) error {
@@ -350,13 +777,26 @@ func (d *Drawing) WriteTo(w io.Writer) (n int64, err error) {
 	if d == nil {
 		return 0, nil
 	}
+	d.registerAppIDs()
 	d.setHandle()
-	d.formatter.Reset()
-	for _, s := range d.Sections {
-		s.Format(d.formatter)
+	v, err := d.Version()
+	if err != nil {
+		v = format.R2000
+	}
+	if b, ok := d.formatter.(interface{ SetVersion(format.Version) }); ok {
+		b.SetVersion(v)
+	}
+	f := d.formatter
+	if v < format.R2007 {
+		f = newEncodingFormatter(f, d.Header().DwgCodePage)
+	}
+	f = newVersionFormatter(f, v)
+	f.Reset()
+	for _, s := range d.downgrade(v) {
+		s.Format(f)
 	}
-	d.formatter.WriteString(0, "EOF")
-	return d.formatter.WriteTo(w)
+	f.WriteString(0, "EOF")
+	return f.WriteTo(w)
 }
 
 var _ io.WriterTo = &Drawing{}
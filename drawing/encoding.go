@@ -0,0 +1,58 @@
+package drawing
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+
+	"github.com/flywave/go-dxf/format"
+)
+
+// encodingFormatter writes strings in the code page of $DWGCODEPAGE for versions before R2007.
+// Characters which the code page doesn't have are escaped as \U+XXXX.
+type encodingFormatter struct {
+	format.Formatter
+	enc *encoding.Encoder
+}
+
+// newEncodingFormatter creates encodingFormatter which writes to f in code page cp,
+// or in DefaultCodePage if cp is unknown.
+func newEncodingFormatter(f format.Formatter, cp string) *encodingFormatter {
+	e, err := format.CodePage(cp)
+	if err != nil {
+		e, _ = format.CodePage(format.DefaultCodePage)
+	}
+	return &encodingFormatter{
+		Formatter: f,
+		enc:       e.NewEncoder(),
+	}
+}
+
+// WriteString writes a string value encoded in the code page.
+func (ef *encodingFormatter) WriteString(code int, s string) {
+	ef.Formatter.WriteString(code, ef.encode(s))
+}
+
+// encode returns s encoded in the code page.
+func (ef *encodingFormatter) encode(s string) string {
+	for _, r := range s {
+		if r < 0x80 {
+			continue
+		}
+		var b strings.Builder
+		for _, r := range s {
+			if r < 0x80 {
+				b.WriteRune(r)
+				continue
+			}
+			if c, err := ef.enc.String(string(r)); err == nil {
+				b.WriteString(c)
+			} else {
+				fmt.Fprintf(&b, "\\U+%04X", r)
+			}
+		}
+		return b.String()
+	}
+	return s
+}
@@ -0,0 +1,197 @@
+package drawing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flywave/go-dxf/entity"
+	"github.com/flywave/go-dxf/handle"
+)
+
+// maxExplodeDepth limits nesting of blocks to detect recursive block references.
+const maxExplodeDepth = 64
+
+// Explode returns the entities of the block referenced by the INSERT,
+// transformed into WCS. Nested INSERTs are exploded recursively and
+// MINSERT arrays are expanded into each column and row.
+// Entities on layer "0" inside the block take the layer of the INSERT.
+// Visible attributes of the INSERT are returned as TEXT or MTEXT.
+// Circles and arcs of non-uniformly scaled INSERTs are converted into ellipses.
+// An error is returned if the block contains an entity which cannot be placed,
+// see entity.CanTransform.
+// The block itself and the INSERT are not modified.
+func (d *Drawing) Explode(i *entity.Insert) (entity.Entities, error) {
+	return d.explode(i, 0)
+}
+
+func (d *Drawing) explode(i *entity.Insert, depth int) (entity.Entities, error) {
+	if depth > maxExplodeDepth {
+		return nil, fmt.Errorf("block %s is nested too deeply", i.BlockName)
+	}
+	b, err := d.Block(i.BlockName)
+	if err != nil {
+		return nil, err
+	}
+	// entities of the block, where nested INSERTs are exploded in block coordinates
+	content := entity.New()
+	for _, e := range b.Entities {
+		ins, ok := e.(*entity.Insert)
+		if !ok {
+			content = append(content, e)
+			continue
+		}
+		if ins.Layer().Name() == "0" {
+			ins = ins.Clone().(*entity.Insert)
+			ins.SetLayer(i.Layer())
+		}
+		sub, err := d.explode(ins, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, sub...)
+	}
+	rows, columns := i.Rows, i.Columns
+	if rows < 1 {
+		rows = 1
+	}
+	if columns < 1 {
+		columns = 1
+	}
+	es := entity.New()
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			for _, e := range content {
+				c, err := i.Place(e, b.Coord, column, row)
+				if err != nil {
+					return nil, fmt.Errorf("block %s: %w", b.Name, err)
+				}
+				if c.Layer().Name() == "0" {
+					c.SetLayer(i.Layer())
+				}
+				es = es.Add(c)
+			}
+		}
+	}
+	for _, a := range i.Attributes {
+		if t := d.attributeText(a); t != nil {
+			es = es.Add(t)
+		}
+	}
+	// exploded entities belong to where the INSERT is
+	for _, e := range es {
+		if o, ok := e.(interface{ SetOwner(handle.Handler) }); ok {
+			o.SetOwner(i.Owner())
+		}
+	}
+	return es, nil
+}
+
+// attributeText converts ATTRIB into TEXT, or into MTEXT if it is a multiline attribute,
+// which has the text embedded after code 101.
+// It returns nil if ATTRIB is invisible.
+func (d *Drawing) attributeText(a *entity.Unknown) entity.Entity {
+	t := entity.NewText()
+	t.WidthFactor = 1.0
+	t.SetLayer(a.Layer())
+	var m *entity.MText
+	flag := 0
+	for _, tag := range a.Tags {
+		if tag.Code == 101 {
+			m = entity.NewMText()
+			m.SetLayer(a.Layer())
+			continue
+		}
+		val, _ := strconv.ParseFloat(strings.TrimSpace(tag.Value), 64)
+		if m != nil {
+			switch tag.Code {
+			case 10, 20, 30:
+				m.Coord[tag.Code/10-1] = val
+			case 11, 21, 31:
+				m.XAxis[(tag.Code-11)/10] = val
+			case 40:
+				m.Height = val
+			case 41:
+				m.Width = val
+			case 71:
+				m.Attachment = int(val)
+			case 72:
+				m.DrawingDirection = int(val)
+			case 1, 3:
+				m.Value += tag.Value
+			case 7:
+				if s, ok := d.Styles[tag.Value]; ok {
+					m.Style = s
+				}
+			}
+			continue
+		}
+		switch tag.Code {
+		case 10, 20, 30:
+			t.Coord1[tag.Code/10-1] = val
+		case 11, 21, 31:
+			t.Coord2[(tag.Code-11)/10] = val
+		case 40:
+			t.Height = val
+		case 41:
+			t.WidthFactor = val
+		case 50:
+			t.Rotation = val
+		case 51:
+			t.ObliqueAngle = val
+		case 1:
+			t.Value = tag.Value
+		case 7:
+			if s, ok := d.Styles[tag.Value]; ok {
+				t.Style = s
+			}
+		case 62:
+			t.SetColor(int(val))
+		case 420:
+			t.SetTrueColor(int(val))
+		case 440:
+			t.SetTransparency(int(val))
+		case 70:
+			flag = int(val)
+		case 71:
+			t.GenFlag = int(val)
+		case 72:
+			t.HorizontalFlag = int(val)
+		case 74:
+			t.VerticalFlag = int(val)
+		case 210, 220, 230:
+			t.Direction[tag.Code/10-21] = val
+		}
+	}
+	if flag&1 != 0 {
+		return nil
+	}
+	if m != nil {
+		m.SetColor(t.Color())
+		m.SetTrueColor(t.TrueColor())
+		m.SetTransparency(t.Transparency())
+		m.Direction = t.Direction
+		return m
+	}
+	return t
+}
+
+// ExplodeAll replaces every INSERT in ENTITIES section
+// with the entities of the referenced block in WCS.
+func (d *Drawing) ExplodeAll() error {
+	es := entity.New()
+	for _, e := range d.Entities() {
+		i, ok := e.(*entity.Insert)
+		if !ok {
+			es = es.Add(e)
+			continue
+		}
+		sub, err := d.Explode(i)
+		if err != nil {
+			return err
+		}
+		es = append(es, sub...)
+	}
+	d.Sections[ENTITIES] = es
+	return nil
+}
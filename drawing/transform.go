@@ -0,0 +1,44 @@
+package drawing
+
+import (
+	"github.com/flywave/go-dxf/entity"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/object"
+)
+
+// Transform transforms every entity in ENTITIES section by m.
+// Entities are converted as entity.TransformEntity does;
+// circles and arcs scaled non-uniformly are replaced with ellipses,
+// also in the groups they belong to.
+// Blocks are not changed, as INSERTs carry their own transformation.
+// If any entity cannot be transformed by m, an error is returned and nothing is changed.
+func (d *Drawing) Transform(m geometry.Matrix) error {
+	es := d.Entities()
+	for _, e := range es {
+		if err := entity.CanTransform(e, m); err != nil {
+			return err
+		}
+	}
+	for i, e := range es {
+		n, err := entity.TransformEntity(e, m)
+		if err != nil {
+			return err
+		}
+		if n == e {
+			continue
+		}
+		es[i] = n
+		for _, o := range d.Objects() {
+			if g, ok := o.(*object.Group); ok {
+				g.ReplaceEntity(e, n)
+			}
+		}
+	}
+	return nil
+}
+
+// Translate moves every entity in ENTITIES section by (x, y, z).
+// An error is returned for an entity which cannot be transformed, as Transform does.
+func (d *Drawing) Translate(x, y, z float64) error {
+	return d.Transform(geometry.Translation(x, y, z))
+}
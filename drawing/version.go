@@ -0,0 +1,782 @@
+package drawing
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/flywave/go-dxf/block"
+	"github.com/flywave/go-dxf/entity"
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/mtext"
+)
+
+// Kinds of buffered tag value.
+const (
+	kindString = iota
+	kindHex
+	kindInt
+	kindFloat
+)
+
+// versionTag is a tag buffered by versionFormatter.
+type versionTag struct {
+	code int
+	kind int
+	s    string
+	i    int
+	f    float64
+}
+
+// float returns the value as a floating point.
+func (t versionTag) float() float64 {
+	switch t.kind {
+	case kindFloat:
+		return t.f
+	case kindInt, kindHex:
+		return float64(t.i)
+	}
+	v, _ := strconv.ParseFloat(strings.TrimSpace(t.s), 64)
+	return v
+}
+
+// str returns the value as a string.
+func (t versionTag) str() string {
+	if t.kind == kindString {
+		return t.s
+	}
+	return ""
+}
+
+// versionRecord is a record which starts with code 0.
+type versionRecord []versionTag
+
+// typ returns the record type (value of code 0).
+func (r versionRecord) typ() string {
+	if len(r) == 0 || r[0].code != 0 {
+		return ""
+	}
+	return r[0].s
+}
+
+// value returns the first string value of code, or "" if not exist.
+func (r versionRecord) value(code int) string {
+	for _, t := range r[1:] {
+		if t.code == code {
+			return t.str()
+		}
+	}
+	return ""
+}
+
+// versionFormatter wraps a Formatter to write data which the version expects.
+// It buffers all the records, and converts and filters them on output.
+type versionFormatter struct {
+	format.Formatter
+	version format.Version
+	records []versionRecord
+	seed    *versionTag // $HANDSEED
+}
+
+// newVersionFormatter creates a new versionFormatter writing to f.
+func newVersionFormatter(f format.Formatter, v format.Version) *versionFormatter {
+	return &versionFormatter{
+		Formatter: f,
+		version:   v,
+	}
+}
+
+// Reset resets the buffer.
+func (vf *versionFormatter) Reset() {
+	vf.records = nil
+	vf.Formatter.Reset()
+}
+
+// WriteTo writes converted data to w.
+func (vf *versionFormatter) WriteTo(w io.Writer) (int64, error) {
+	vf.flush()
+	return vf.Formatter.WriteTo(w)
+}
+
+// Output outputs converted data.
+func (vf *versionFormatter) Output() string {
+	vf.flush()
+	return vf.Formatter.Output()
+}
+
+// WriteString appends string data to the buffer.
+func (vf *versionFormatter) WriteString(num int, val string) {
+	vf.append(versionTag{code: num, kind: kindString, s: val})
+}
+
+// WriteHex appends hex data to the buffer.
+func (vf *versionFormatter) WriteHex(num int, h int) {
+	vf.append(versionTag{code: num, kind: kindHex, i: h})
+}
+
+// WriteInt appends int data to the buffer.
+func (vf *versionFormatter) WriteInt(num int, val int) {
+	vf.append(versionTag{code: num, kind: kindInt, i: val})
+}
+
+// WriteFloat appends floating point data to the buffer.
+func (vf *versionFormatter) WriteFloat(num int, val float64) {
+	vf.append(versionTag{code: num, kind: kindFloat, f: val})
+}
+
+// append appends a tag to the last record, or a new record if code is 0.
+func (vf *versionFormatter) append(t versionTag) {
+	if t.code == 0 || len(vf.records) == 0 {
+		vf.records = append(vf.records, versionRecord{t})
+		return
+	}
+	last := len(vf.records) - 1
+	vf.records[last] = append(vf.records[last], t)
+}
+
+// flush converts buffered records and writes them to the underlying formatter.
+func (vf *versionFormatter) flush() {
+	records := vf.convert(vf.records)
+	vf.records = nil
+	for _, r := range records {
+		for _, t := range r {
+			vf.write(t)
+		}
+	}
+}
+
+// write writes a tag to the underlying formatter.
+func (vf *versionFormatter) write(t versionTag) {
+	switch t.kind {
+	case kindString:
+		vf.Formatter.WriteString(t.code, t.s)
+	case kindHex:
+		vf.Formatter.WriteHex(t.code, t.i)
+	case kindInt:
+		vf.Formatter.WriteInt(t.code, t.i)
+	case kindFloat:
+		vf.Formatter.WriteFloat(t.code, t.f)
+	}
+}
+
+// convert converts records into those of the version.
+func (vf *versionFormatter) convert(records []versionRecord) []versionRecord {
+	rtn := make([]versionRecord, 0, len(records))
+	section := ""
+	skip := "" // record type which ends skipping
+	dropped := vf.droppedObjects(records)
+	for _, r := range records {
+		typ := r.typ()
+		if skip != "" {
+			if typ == skip {
+				skip = ""
+			}
+			continue
+		}
+		switch typ {
+		case "SECTION":
+			section = r.value(2)
+			if vf.version == format.R12 && (section == "CLASSES" || section == "OBJECTS") {
+				skip = "ENDSEC"
+				continue
+			}
+			if section == "HEADER" {
+				// values are filtered, and $HANDSEED is updated after conversion
+				rtn = append(rtn, vf.header(r))
+				continue
+			}
+		case "ENDSEC":
+			section = ""
+		case "TABLE":
+			if !hasRecord(vf.version, r.value(2)) {
+				skip = "ENDTAB"
+				continue
+			}
+		case "LTYPE":
+			// BYLAYER and BYBLOCK are implicit in R12
+			name := strings.ToUpper(r.value(2))
+			if vf.version == format.R12 && (name == "BYLAYER" || name == "BYBLOCK") {
+				continue
+			}
+		case "CLASS":
+			if !hasRecord(vf.version, r.value(1)) {
+				continue
+			}
+		case "BLOCK":
+			name := strings.ToUpper(r.value(2))
+			if vf.version == format.R12 && (strings.HasPrefix(name, "*MODEL_SPACE") || strings.HasPrefix(name, "*PAPER_SPACE")) {
+				skip = "ENDBLK"
+				continue
+			}
+		case "ENDBLK":
+		default:
+			switch section {
+			case "ENTITIES", "BLOCKS":
+				for _, c := range vf.entity(r) {
+					rtn = append(rtn, vf.filter(c))
+				}
+				continue
+			case "OBJECTS":
+				if !hasRecord(vf.version, typ) {
+					continue
+				}
+				r = r.withoutItems(dropped)
+			}
+		}
+		rtn = append(rtn, vf.filter(r))
+	}
+	return rtn
+}
+
+// droppedObjects returns the handles of objects which the version doesn't have.
+func (vf *versionFormatter) droppedObjects(records []versionRecord) map[int]bool {
+	dropped := make(map[int]bool)
+	section := ""
+	for _, r := range records {
+		switch typ := r.typ(); typ {
+		case "SECTION":
+			section = r.value(2)
+		case "ENDSEC":
+			section = ""
+		default:
+			if h := r.handle(); section == "OBJECTS" && h != 0 && !hasRecord(vf.version, typ) {
+				dropped[h] = true
+			}
+		}
+	}
+	return dropped
+}
+
+// withoutItems removes entries of dictionary (3 and the following 350 or 360)
+// which refer to dropped objects.
+func (r versionRecord) withoutItems(dropped map[int]bool) versionRecord {
+	if len(dropped) == 0 {
+		return r
+	}
+	rtn := r[:0:0]
+	for i := 0; i < len(r); i++ {
+		if r[i].code == 3 && i+1 < len(r) && (r[i+1].code == 350 || r[i+1].code == 360) && dropped[r[i+1].i] {
+			i++
+			continue
+		}
+		rtn = append(rtn, r[i])
+	}
+	return rtn
+}
+
+// header removes variables which the version doesn't have.
+// Values of variables with group codes the version doesn't have are also removed.
+func (vf *versionFormatter) header(r versionRecord) versionRecord {
+	rtn := versionRecord{}
+	i := 0
+	for i < len(r) && r[i].code != 9 {
+		rtn = append(rtn, r[i])
+		i++
+	}
+	for i < len(r) {
+		name := r[i]
+		values := versionRecord{}
+		for i++; i < len(r) && r[i].code != 9; i++ {
+			if vf.version == format.R12 || hasCode(vf.version, "", r[i].code) {
+				values = append(values, r[i])
+			}
+		}
+		if len(values) == 0 || !hasVariable(vf.version, name.s) {
+			continue
+		}
+		rtn = append(rtn, name)
+		rtn = append(rtn, values...)
+	}
+	for i := range rtn {
+		if rtn[i].code == 9 && rtn[i].s == "$HANDSEED" && i+1 < len(rtn) {
+			vf.seed = &rtn[i+1]
+		}
+	}
+	return rtn
+}
+
+// entity converts an entity record into records of the version.
+// It returns nil if the version doesn't have the entity.
+func (vf *versionFormatter) entity(r versionRecord) []versionRecord {
+	switch r.typ() {
+	case "LWPOLYLINE":
+		if vf.version < format.R14 {
+			return vf.lwpolyline(r)
+		}
+	case "ELLIPSE":
+		if vf.version < format.R13 {
+			points, closed := ellipsePoints(r)
+			return vf.polyline(r, points, closed)
+		}
+	case "SPLINE":
+		if vf.version < format.R13 {
+			points, closed := splinePoints(r)
+			return vf.polyline(r, points, closed)
+		}
+	case "LEADER":
+		if vf.version < format.R13 {
+			points, closed := leaderPoints(r)
+			return vf.polyline(r, points, closed)
+		}
+	case "MTEXT":
+		if vf.version < format.R13 {
+			return []versionRecord{mtextToText(r)}
+		}
+	}
+	if !hasRecord(vf.version, r.typ()) {
+		return nil
+	}
+	return []versionRecord{r}
+}
+
+// downgrade returns sections in which entities the version doesn't have are replaced:
+// HATCH before R14 with its boundaries as POLYLINEs, and MULTILEADER before R2007
+// with LINEs and MTEXT. The replacements are given handles above $HANDSEED,
+// and the entities and blocks of the drawing are not modified.
+func (d *Drawing) downgrade(v format.Version) []Section {
+	if v >= format.R2007 {
+		return d.Sections
+	}
+	next := d.Header().HandSeed()
+	convert := func(es entity.Entities) entity.Entities {
+		var rtn entity.Entities // nil until an entity is replaced
+		for i, e := range es {
+			var sub entity.Entities
+			switch c := e.(type) {
+			case *entity.Hatch:
+				if v < format.R14 {
+					sub = c.Boundaries(hatchTolerance(c))
+				}
+			case *entity.MLeader:
+				sub = c.Explode()
+			}
+			if sub == nil {
+				if rtn != nil {
+					rtn = append(rtn, e)
+				}
+				continue
+			}
+			if rtn == nil {
+				rtn = append(entity.New(), es[:i]...)
+			}
+			sub.SetHandle(&next)
+			rtn = append(rtn, sub...)
+		}
+		if rtn == nil {
+			return es
+		}
+		return rtn
+	}
+	sections := append([]Section(nil), d.Sections...)
+	blocks := make(block.Blocks, 0, len(d.Blocks()))
+	for _, b := range d.Blocks() {
+		c := *b
+		c.Entities = convert(b.Entities)
+		blocks = blocks.Add(&c)
+	}
+	sections[BLOCKS] = blocks
+	sections[ENTITIES] = convert(d.Entities())
+	d.Sections[HEADER].SetHandle(&next)
+	return sections
+}
+
+// hatchTolerance returns the tolerance to tessellate boundaries of h,
+// which is 1/1000 of the size of h.
+func hatchTolerance(h *entity.Hatch) float64 {
+	mins, maxs := h.BBox()
+	size := geometry.Length([]float64{maxs[0] - mins[0], maxs[1] - mins[1], maxs[2] - mins[2]})
+	if math.IsInf(size, 0) || math.IsNaN(size) || size == 0.0 {
+		return 1e-6
+	}
+	return size * 1e-3
+}
+
+// common returns the common part of entity record before the first subclass marker
+// other than AcDbEntity, and the rest.
+func (r versionRecord) common() (versionRecord, versionRecord) {
+	for i, t := range r {
+		if t.code == 100 && t.s != "AcDbEntity" {
+			return r[:i:i], r[i:]
+		}
+	}
+	return r, nil
+}
+
+// xdata splits the record into data and XDATA.
+func (r versionRecord) xdata() (versionRecord, versionRecord) {
+	for i, t := range r {
+		if t.code == 1001 {
+			return r[:i:i], r[i:]
+		}
+	}
+	return r, nil
+}
+
+// keep returns the record which has only given codes.
+func (r versionRecord) keep(codes ...int) versionRecord {
+	rtn := versionRecord{}
+	for _, t := range r {
+		for _, c := range codes {
+			if t.code == c {
+				rtn = append(rtn, t)
+				break
+			}
+		}
+	}
+	return rtn
+}
+
+// newHandle returns a new handle above $HANDSEED for records which are added on conversion.
+func (vf *versionFormatter) newHandle() int {
+	if vf.seed == nil {
+		return 0
+	}
+	h := vf.seed.i
+	vf.seed.i++
+	return h
+}
+
+// lwpolyline converts LWPOLYLINE into POLYLINE, VERTEX and SEQEND.
+func (vf *versionFormatter) lwpolyline(r versionRecord) []versionRecord {
+	common, rest := r.common()
+	data, xdata := rest.xdata()
+	poly := versionRecord{{code: 0, kind: kindString, s: "POLYLINE"}}
+	poly = append(poly, common[1:]...)
+	poly = append(poly, versionTag{code: 100, kind: kindString, s: "AcDb2dPolyline"})
+	poly = append(poly, versionTag{code: 66, kind: kindInt, i: 1})
+	elevation := 0.0
+	flag := 0
+	width := 0.0
+	vertices := make([]versionRecord, 0) // 10, 20, 40, 41, 42 of each vertex
+	extra := versionRecord{}
+	for _, t := range data {
+		switch t.code {
+		case 38:
+			elevation = t.float()
+		case 43:
+			width = t.float()
+		case 70:
+			flag = t.i
+		case 39:
+			extra = append(extra, t)
+		case 10:
+			vertices = append(vertices, versionRecord{t})
+		case 20, 40, 41, 42:
+			if len(vertices) > 0 {
+				vertices[len(vertices)-1] = append(vertices[len(vertices)-1], t)
+			}
+		case 210, 220, 230:
+			extra = append(extra, t)
+		}
+	}
+	poly = append(poly,
+		versionTag{code: 10, kind: kindFloat},
+		versionTag{code: 20, kind: kindFloat},
+		versionTag{code: 30, kind: kindFloat, f: elevation},
+		versionTag{code: 70, kind: kindInt, i: flag},
+	)
+	if width != 0.0 {
+		poly = append(poly,
+			versionTag{code: 40, kind: kindFloat, f: width},
+			versionTag{code: 41, kind: kindFloat, f: width},
+		)
+	}
+	poly = append(poly, extra...)
+	poly = append(poly, xdata...)
+	rtn := []versionRecord{poly}
+	for _, v := range vertices {
+		vertex := vf.vertex(common, "AcDb2dVertex")
+		vertex = append(vertex, v.keep(10, 20)...)
+		vertex = append(vertex, versionTag{code: 30, kind: kindFloat, f: elevation})
+		vertex = append(vertex, v.keep(40, 41, 42)...)
+		vertex = append(vertex, versionTag{code: 70, kind: kindInt})
+		rtn = append(rtn, vertex)
+	}
+	return append(rtn, vf.seqend(common))
+}
+
+// polyline converts an entity record into 3D POLYLINE, VERTEX and SEQEND
+// which pass through given points.
+func (vf *versionFormatter) polyline(r versionRecord, points [][]float64, closed bool) []versionRecord {
+	common, rest := r.common()
+	_, xdata := rest.xdata()
+	flag := 8
+	if closed {
+		flag |= 1
+	}
+	poly := versionRecord{{code: 0, kind: kindString, s: "POLYLINE"}}
+	poly = append(poly, common[1:]...)
+	poly = append(poly,
+		versionTag{code: 100, kind: kindString, s: "AcDb3dPolyline"},
+		versionTag{code: 66, kind: kindInt, i: 1},
+		versionTag{code: 10, kind: kindFloat},
+		versionTag{code: 20, kind: kindFloat},
+		versionTag{code: 30, kind: kindFloat},
+		versionTag{code: 70, kind: kindInt, i: flag},
+	)
+	poly = append(poly, xdata...)
+	rtn := []versionRecord{poly}
+	for _, p := range points {
+		vertex := vf.vertex(common, "AcDb3dPolylineVertex")
+		for i := 0; i < 3; i++ {
+			vertex = append(vertex, versionTag{code: (i + 1) * 10, kind: kindFloat, f: p[i]})
+		}
+		vertex = append(vertex, versionTag{code: 70, kind: kindInt, i: 32})
+		rtn = append(rtn, vertex)
+	}
+	return append(rtn, vf.seqend(common))
+}
+
+// vertex returns the first part of VERTEX record which belongs to the entity of common.
+func (vf *versionFormatter) vertex(common versionRecord, subclass string) versionRecord {
+	return versionRecord{
+		{code: 0, kind: kindString, s: "VERTEX"},
+		{code: 5, kind: kindHex, i: vf.newHandle()},
+		{code: 330, kind: kindHex, i: common.handle()},
+		{code: 100, kind: kindString, s: "AcDbEntity"},
+		{code: 8, kind: kindString, s: common.value(8)},
+		{code: 100, kind: kindString, s: "AcDbVertex"},
+		{code: 100, kind: kindString, s: subclass},
+	}
+}
+
+// seqend returns SEQEND record which belongs to the entity of common.
+func (vf *versionFormatter) seqend(common versionRecord) versionRecord {
+	return versionRecord{
+		{code: 0, kind: kindString, s: "SEQEND"},
+		{code: 5, kind: kindHex, i: vf.newHandle()},
+		{code: 330, kind: kindHex, i: common.handle()},
+		{code: 100, kind: kindString, s: "AcDbEntity"},
+		{code: 8, kind: kindString, s: common.value(8)},
+	}
+}
+
+// handle returns the value of code 5, or 0 if not exist.
+func (r versionRecord) handle() int {
+	for _, t := range r[1:] {
+		if t.code == 5 {
+			return t.i
+		}
+	}
+	return 0
+}
+
+// points returns the points of code, code+10 and code+20 in the record.
+func (r versionRecord) points(code int) [][]float64 {
+	rtn := make([][]float64, 0)
+	for _, t := range r {
+		switch t.code {
+		case code:
+			rtn = append(rtn, []float64{t.float(), 0.0, 0.0})
+		case code + 10, code + 20:
+			if len(rtn) > 0 {
+				rtn[len(rtn)-1][t.code/10-code/10] = t.float()
+			}
+		}
+	}
+	return rtn
+}
+
+// floats returns the values of code in the record.
+func (r versionRecord) floats(code int) []float64 {
+	rtn := make([]float64, 0)
+	for _, t := range r {
+		if t.code == code {
+			rtn = append(rtn, t.float())
+		}
+	}
+	return rtn
+}
+
+// ellipsePoints approximates ELLIPSE record by points.
+// It returns true if the ellipse is closed.
+func ellipsePoints(r versionRecord) ([][]float64, bool) {
+	_, rest := r.common()
+	data, _ := rest.xdata()
+	center := []float64{0.0, 0.0, 0.0}
+	major := []float64{1.0, 0.0, 0.0}
+	direction := []float64{0.0, 0.0, 1.0}
+	ratio := 1.0
+	start, end := 0.0, 2.0*math.Pi
+	for _, t := range data {
+		switch t.code {
+		case 10, 20, 30:
+			center[t.code/10-1] = t.float()
+		case 11, 21, 31:
+			major[t.code/10-1] = t.float()
+		case 210, 220, 230:
+			direction[(t.code-200)/10-1] = t.float()
+		case 40:
+			ratio = t.float()
+		case 41:
+			start = t.float()
+		case 42:
+			end = t.float()
+		}
+	}
+	l := geometry.Length(major) * ratio
+	minor := geometry.Normalize(geometry.Cross(geometry.Normalize(direction), major))
+	sweep := math.Mod(end-start, 2.0*math.Pi)
+	if sweep <= 1e-12 {
+		sweep += 2.0 * math.Pi
+	}
+	closed := math.Abs(sweep-2.0*math.Pi) < 1e-9
+	n := int(math.Ceil(sweep / (2.0 * math.Pi) * 64))
+	if n < 2 {
+		n = 2
+	}
+	last := n
+	if closed {
+		last = n - 1
+	}
+	rtn := make([][]float64, 0, last+1)
+	for i := 0; i <= last; i++ {
+		a := start + sweep*float64(i)/float64(n)
+		c, s := math.Cos(a), math.Sin(a)
+		p := make([]float64, 3)
+		for j := 0; j < 3; j++ {
+			p[j] = center[j] + major[j]*c + minor[j]*l*s
+		}
+		rtn = append(rtn, p)
+	}
+	return rtn, closed
+}
+
+// splinePoints approximates SPLINE record by points.
+// If it has no valid control points, fit points are returned.
+// It returns true if the spline is closed.
+func splinePoints(r versionRecord) ([][]float64, bool) {
+	_, rest := r.common()
+	data, _ := rest.xdata()
+	flag, degree := 0, 3
+	for _, t := range data {
+		switch t.code {
+		case 70:
+			flag = t.i
+		case 71:
+			degree = t.i
+		}
+	}
+	closed := flag&1 != 0
+	controls := data.points(10)
+	n, err := geometry.NewNURBS(degree, data.floats(40), controls, data.floats(41))
+	if err != nil {
+		return data.points(11), closed
+	}
+	mins, maxs := n.BBox()
+	tolerance := geometry.Length([]float64{maxs[0] - mins[0], maxs[1] - mins[1], maxs[2] - mins[2]}) * 1e-3
+	if tolerance == 0.0 {
+		tolerance = 1e-6
+	}
+	rtn := n.Tessellate(tolerance)
+	if closed && len(rtn) > 1 && geometry.Length([]float64{
+		rtn[0][0] - rtn[len(rtn)-1][0], rtn[0][1] - rtn[len(rtn)-1][1], rtn[0][2] - rtn[len(rtn)-1][2],
+	}) < 1e-9 {
+		rtn = rtn[:len(rtn)-1]
+	}
+	return rtn, closed
+}
+
+// leaderPoints returns the vertices of LEADER record.
+func leaderPoints(r versionRecord) ([][]float64, bool) {
+	_, rest := r.common()
+	data, _ := rest.xdata()
+	return data.points(10), false
+}
+
+// mtextToText converts MTEXT into TEXT of plain text.
+// Attachment point is converted into alignment.
+func mtextToText(r versionRecord) versionRecord {
+	common, rest := r.common()
+	data, xdata := rest.xdata()
+	coord := []float64{0.0, 0.0, 0.0}
+	xaxis := []float64{1.0, 0.0, 0.0}
+	direction := []float64{0.0, 0.0, 1.0}
+	height := 1.0
+	attachment := 1
+	angle := math.NaN()
+	style := ""
+	var value strings.Builder
+	last := ""
+	for _, t := range data {
+		switch t.code {
+		case 10, 20, 30:
+			coord[t.code/10-1] = t.float()
+		case 11, 21, 31:
+			xaxis[t.code/10-1] = t.float()
+			angle = math.NaN()
+		case 50:
+			angle = t.float()
+		case 210, 220, 230:
+			direction[(t.code-200)/10-1] = t.float()
+		case 40:
+			height = t.float()
+		case 71:
+			attachment = t.i
+		case 3:
+			value.WriteString(t.str())
+		case 1:
+			last = t.str()
+		case 7:
+			style = t.str()
+		}
+	}
+	value.WriteString(last)
+	if !math.IsNaN(angle) {
+		// rotation angle in radians is used instead of direction vector
+		xaxis = geometry.OCS(direction).ApplyVector([]float64{math.Cos(angle), math.Sin(angle), 0.0})
+	}
+	ocs := geometry.OCS(direction).Transpose()
+	coord = ocs.Apply(coord)
+	x := ocs.ApplyVector(xaxis)
+	rotation := math.Atan2(x[1], x[0]) * 180.0 / math.Pi
+	text := strings.ReplaceAll(mtext.PlainText(value.String()), "\n", " ")
+
+	rtn := versionRecord{{code: 0, kind: kindString, s: "TEXT"}}
+	rtn = append(rtn, common[1:]...)
+	rtn = append(rtn, versionTag{code: 100, kind: kindString, s: "AcDbText"})
+	for i := 0; i < 3; i++ {
+		rtn = append(rtn, versionTag{code: (i + 1) * 10, kind: kindFloat, f: coord[i]})
+	}
+	rtn = append(rtn,
+		versionTag{code: 40, kind: kindFloat, f: height},
+		versionTag{code: 1, kind: kindString, s: text},
+		versionTag{code: 50, kind: kindFloat, f: rotation},
+	)
+	if style != "" {
+		rtn = append(rtn, versionTag{code: 7, kind: kindString, s: style})
+	}
+	// attachment: 1-3 top, 4-6 middle, 7-9 bottom; left, center, right
+	if attachment < 1 || attachment > 9 {
+		attachment = 1
+	}
+	horizontal := (attachment - 1) % 3
+	vertical := 3 - (attachment-1)/3
+	rtn = append(rtn, versionTag{code: 72, kind: kindInt, i: horizontal})
+	for i := 0; i < 3; i++ {
+		rtn = append(rtn, versionTag{code: (i+1)*10 + 1, kind: kindFloat, f: coord[i]})
+	}
+	if direction[0] != 0.0 || direction[1] != 0.0 || direction[2] != 1.0 {
+		for i := 0; i < 3; i++ {
+			rtn = append(rtn, versionTag{code: 200 + (i+1)*10, kind: kindFloat, f: direction[i]})
+		}
+	}
+	rtn = append(rtn, versionTag{code: 100, kind: kindString, s: "AcDbText"})
+	rtn = append(rtn, versionTag{code: 73, kind: kindInt, i: vertical})
+	rtn = append(rtn, xdata...)
+	return rtn
+}
+
+// filter removes tags which the version doesn't have from the record.
+func (vf *versionFormatter) filter(r versionRecord) versionRecord {
+	typ := r.typ()
+	rtn := r[:0:0]
+	for _, t := range r {
+		if t.code == 0 || hasCode(vf.version, typ, t.code) {
+			rtn = append(rtn, t)
+		}
+	}
+	return rtn
+}
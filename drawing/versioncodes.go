@@ -0,0 +1,258 @@
+package drawing
+
+import (
+	"github.com/flywave/go-dxf/format"
+)
+
+// codeSet is a set of group codes.
+type codeSet map[int]bool
+
+// codes returns a codeSet of given group codes.
+func codes(cs ...int) codeSet {
+	s := make(codeSet, len(cs))
+	for _, c := range cs {
+		s[c] = true
+	}
+	return s
+}
+
+// r12Entity returns a codeSet of entity codes of R12 with the common ones
+// (linetype, layer, elevation, thickness, color, paper space and extrusion direction).
+func r12Entity(cs ...int) codeSet {
+	return codes(append([]int{6, 8, 38, 39, 62, 67, 210, 220, 230}, cs...)...)
+}
+
+// r12Codes is the set of group codes of each record type of R12,
+// except comments (999) and XDATA, which every record may have.
+// Record types not in r12Codes are not written for R12.
+var r12Codes = map[string]codeSet{
+	"SECTION": codes(2),
+	"ENDSEC":  codes(),
+	"EOF":     codes(),
+	"TABLE":   codes(2, 70),
+	"ENDTAB":  codes(),
+	// tables
+	"VPORT": codes(2, 70, 10, 20, 11, 21, 12, 22, 13, 23, 14, 24, 15, 25, 16, 26, 36, 17, 27, 37,
+		40, 41, 42, 43, 44, 50, 51, 71, 72, 73, 74, 75, 76, 77, 78),
+	"LTYPE": codes(2, 70, 3, 72, 73, 40, 49),
+	"LAYER": codes(2, 70, 62, 6),
+	"STYLE": codes(2, 70, 40, 41, 50, 71, 42, 3, 4),
+	"VIEW":  codes(2, 70, 40, 10, 20, 41, 11, 21, 31, 12, 22, 32, 42, 43, 44, 50, 71),
+	"UCS":   codes(2, 70, 10, 20, 30, 11, 21, 31, 12, 22, 32),
+	"APPID": codes(2, 70),
+	"DIMSTYLE": codes(2, 70, 3, 4, 5, 6, 7, 40, 41, 42, 43, 44, 45, 46, 47, 48,
+		140, 141, 142, 143, 144, 145, 146, 147, 71, 72, 73, 74, 75, 76, 77, 78, 170, 171, 172, 173, 174, 175),
+	// blocks
+	"BLOCK":  codes(1, 2, 3, 8, 10, 20, 30, 70),
+	"ENDBLK": codes(8),
+	// entities
+	"LINE":     r12Entity(10, 20, 30, 11, 21, 31),
+	"POINT":    r12Entity(10, 20, 30, 50),
+	"CIRCLE":   r12Entity(10, 20, 30, 40),
+	"ARC":      r12Entity(10, 20, 30, 40, 50, 51),
+	"TRACE":    r12Entity(10, 20, 30, 11, 21, 31, 12, 22, 32, 13, 23, 33),
+	"SOLID":    r12Entity(10, 20, 30, 11, 21, 31, 12, 22, 32, 13, 23, 33),
+	"TEXT":     r12Entity(1, 7, 10, 20, 30, 11, 21, 31, 40, 41, 50, 51, 71, 72, 73),
+	"SHAPE":    r12Entity(2, 10, 20, 30, 40, 41, 50, 51),
+	"INSERT":   r12Entity(2, 10, 20, 30, 41, 42, 43, 44, 45, 50, 66, 70, 71),
+	"ATTDEF":   r12Entity(1, 2, 3, 7, 10, 20, 30, 11, 21, 31, 40, 41, 50, 51, 70, 71, 72, 73, 74),
+	"ATTRIB":   r12Entity(1, 2, 7, 10, 20, 30, 11, 21, 31, 40, 41, 50, 51, 70, 71, 72, 73, 74),
+	"POLYLINE": r12Entity(10, 20, 30, 40, 41, 66, 70, 71, 72, 73, 74, 75),
+	"VERTEX":   r12Entity(10, 20, 30, 40, 41, 42, 50, 70, 71, 72, 73, 74),
+	"SEQEND":   r12Entity(),
+	"3DFACE":   r12Entity(10, 20, 30, 11, 21, 31, 12, 22, 32, 13, 23, 33, 70),
+	"VIEWPORT": r12Entity(10, 20, 30, 40, 41, 68, 69),
+	"DIMENSION": r12Entity(1, 2, 3, 10, 20, 30, 11, 21, 31, 12, 22, 32, 13, 23, 33,
+		14, 24, 34, 15, 25, 35, 16, 26, 36, 40, 50, 51, 52, 53, 70),
+}
+
+// recordSince maps record types introduced after R12 to the version which introduced them.
+// Record types in neither r12Codes nor recordSince are taken as introduced in R13.
+var recordSince = map[string]format.Version{
+	"LWPOLYLINE":          format.R14,
+	"HATCH":               format.R14,
+	"IMAGE":               format.R14,
+	"IMAGEDEF":            format.R14,
+	"IMAGEDEF_REACTOR":    format.R14,
+	"RASTERVARIABLES":     format.R14,
+	"LAYOUT":              format.R2000,
+	"PLOTSETTINGS":        format.R2000,
+	"ACDBDICTIONARYWDFLT": format.R2000,
+	"ACDBPLACEHOLDER":     format.R2000,
+	"WIPEOUT":             format.R2000,
+	"ACAD_TABLE":          format.R2004,
+	"TABLESTYLE":          format.R2004,
+	"MULTILEADER":         format.R2007,
+	"MLEADERSTYLE":        format.R2007,
+	"HELIX":               format.R2007,
+	"LIGHT":               format.R2007,
+	"SUN":                 format.R2007,
+	"MATERIAL":            format.R2007,
+	"VISUALSTYLE":         format.R2007,
+	"SCALE":               format.R2007,
+	"MESH":                format.R2010,
+	"GEODATA":             format.R2010,
+	"PDFUNDERLAY":         format.R2010,
+	"PDFDEFINITION":       format.R2010,
+}
+
+// codeSince maps group codes of each record type to the version which introduced them,
+// where they differ from the common ones given by commonSince.
+var codeSince = map[string]map[int]format.Version{
+	"CLASS":        {90: format.R2000, 91: format.R2004},
+	"TABLE":        {71: format.R2000, 340: format.R2000},
+	"BLOCK_RECORD": {70: format.R2000, 280: format.R2000, 281: format.R2000, 310: format.R2000, 340: format.R2000},
+	"LAYER":        {347: format.R2007, 348: format.R2010},
+	"DIMSTYLE": {
+		79: format.R2000, 276: format.R2000, 277: format.R2000, 278: format.R2000, 279: format.R2000, 289: format.R2000,
+		341: format.R2000, 342: format.R2000, 343: format.R2000, 344: format.R2000, 345: format.R2000,
+		69: format.R2007,
+	},
+	"VPORT": {
+		65: format.R2000, 79: format.R2000, 146: format.R2000, 345: format.R2000, 346: format.R2000,
+		110: format.R2000, 120: format.R2000, 130: format.R2000,
+		111: format.R2000, 121: format.R2000, 131: format.R2000,
+		112: format.R2000, 122: format.R2000, 132: format.R2000,
+		60: format.R2007, 61: format.R2007, 141: format.R2007, 142: format.R2007, 63: format.R2007,
+	},
+	"DICTIONARY": {280: format.R2000, 281: format.R2000},
+	"XRECORD":    {280: format.R2000},
+	"MTEXT":      {44: format.R2000, 73: format.R2000},
+	"DIMENSION":  {42: format.R2000},
+	"VERTEX":     {91: format.R2010},
+	"LWPOLYLINE": {91: format.R2010},
+	// gradient fill, including its colors
+	"HATCH": {
+		450: format.R2004, 451: format.R2004, 452: format.R2004, 453: format.R2004,
+		460: format.R2004, 461: format.R2004, 462: format.R2004, 463: format.R2004,
+		421: format.R2004, 470: format.R2004,
+	},
+}
+
+// commonSince returns the version which introduced group code in records of R13 and later.
+func commonSince(code int) format.Version {
+	switch {
+	case code >= 160 && code < 170, code >= 290 && code < 300, code >= 370 && code < 400:
+		// 64-bit integers, booleans, lineweights and plot styles
+		return format.R2000
+	case code >= 420 && code < 460, code == 480 || code == 481:
+		// true colors, color names, transparencies and their handles
+		return format.R2007
+	case code >= 460 && code < 480:
+		return format.R2004
+	}
+	return format.R13
+}
+
+// variableSince maps header variables introduced after R12 to the version which introduced them.
+// Variables in neither r12Variables nor variableSince are written for every version but R12.
+var variableSince = map[string]format.Version{
+	"$CELTSCALE": format.R13, "$DIMTOLJ": format.R13, "$DIMTZIN": format.R13,
+	"$DIMALTZ": format.R13, "$DIMALTTZ": format.R13, "$DIMUPT": format.R13,
+	"$DIMDEC": format.R13, "$DIMTDEC": format.R13, "$DIMALTU": format.R13,
+	"$DIMALTTD": format.R13, "$DIMTXSTY": format.R13, "$DIMAUNIT": format.R13,
+	"$DIMJUST": format.R13, "$DIMSD1": format.R13, "$DIMSD2": format.R13,
+	"$CHAMFERC": format.R13, "$CHAMFERD": format.R13, "$CMLSTYLE": format.R13,
+	"$CMLJUST": format.R13, "$CMLSCALE": format.R13, "$PROXYGRAPHICS": format.R13,
+	"$TREEDEPTH": format.R13, "$PELLIPSE": format.R13, "$PINSBASE": format.R13,
+	"$MEASUREMENT":   format.R14,
+	"$INSUNITS":      format.R2000,
+	"$CEPSNTYPE":     format.R2000,
+	"$CELWEIGHT":     format.R2000,
+	"$LWDISPLAY":     format.R2000,
+	"$PSTYLEMODE":    format.R2000,
+	"$EXTNAMES":      format.R2000,
+	"$XEDIT":         format.R2000,
+	"$TDUCREATE":     format.R2000,
+	"$TDUUPDATE":     format.R2000,
+	"$HYPERLINKBASE": format.R2000,
+	"$STYLESHEET":    format.R2000,
+	"$ENDCAPS":       format.R2000,
+	"$JOINSTYLE":     format.R2000,
+	"$DIMADEC":       format.R2000, "$DIMALTRND": format.R2000, "$DIMAZIN": format.R2000,
+	"$DIMDSEP": format.R2000, "$DIMATFIT": format.R2000, "$DIMFRAC": format.R2000,
+	"$DIMLDRBLK": format.R2000, "$DIMLUNIT": format.R2000, "$DIMLWD": format.R2000,
+	"$DIMLWE": format.R2000, "$DIMTMOVE": format.R2000,
+	"$FINGERPRINTGUID":  format.R2000,
+	"$VERSIONGUID":      format.R2000,
+	"$PROJECTNAME":      format.R2004,
+	"$CAMERADISPLAY":    format.R2007,
+	"$CAMERAHEIGHT":     format.R2007,
+	"$LATITUDE":         format.R2007,
+	"$LONGITUDE":        format.R2007,
+	"$NORTHDIRECTION":   format.R2007,
+	"$CSHADOW":          format.R2007,
+	"$REQUIREDVERSIONS": format.R2013,
+}
+
+// r12Variables is the set of header variables of R12.
+// $HANDSEED and $HANDLING are not included, as handles are not written for R12.
+var r12Variables = map[string]bool{
+	"$ACADVER": true, "$ANGBASE": true, "$ANGDIR": true, "$ATTDIA": true, "$ATTMODE": true,
+	"$ATTREQ": true, "$AUNITS": true, "$AUPREC": true, "$AXISMODE": true, "$AXISUNIT": true,
+	"$BLIPMODE": true, "$CECOLOR": true, "$CELTYPE": true, "$CHAMFERA": true, "$CHAMFERB": true,
+	"$CLAYER": true, "$COORDS": true, "$DIMALT": true, "$DIMALTD": true, "$DIMALTF": true,
+	"$DIMAPOST": true, "$DIMASO": true, "$DIMASZ": true, "$DIMBLK": true, "$DIMBLK1": true,
+	"$DIMBLK2": true, "$DIMCEN": true, "$DIMCLRD": true, "$DIMCLRE": true, "$DIMCLRT": true,
+	"$DIMDLE": true, "$DIMDLI": true, "$DIMEXE": true, "$DIMEXO": true, "$DIMGAP": true,
+	"$DIMLFAC": true, "$DIMLIM": true, "$DIMPOST": true, "$DIMRND": true, "$DIMSAH": true,
+	"$DIMSCALE": true, "$DIMSE1": true, "$DIMSE2": true, "$DIMSHO": true, "$DIMSOXD": true,
+	"$DIMSTYLE": true, "$DIMTAD": true, "$DIMTFAC": true, "$DIMTIH": true, "$DIMTIX": true,
+	"$DIMTM": true, "$DIMTOFL": true, "$DIMTOH": true, "$DIMTOL": true, "$DIMTP": true,
+	"$DIMTSZ": true, "$DIMTVP": true, "$DIMTXT": true, "$DIMZIN": true, "$DRAGMODE": true,
+	"$DWGCODEPAGE": true, "$ELEVATION": true, "$EXTMAX": true, "$EXTMIN": true, "$FASTZOOM": true,
+	"$FILLETRAD": true, "$FILLMODE": true, "$GRIDMODE": true, "$GRIDUNIT": true, "$INSBASE": true,
+	"$LIMCHECK": true, "$LIMMAX": true, "$LIMMIN": true, "$LTSCALE": true, "$LUNITS": true,
+	"$LUPREC": true, "$MAXACTVP": true, "$MENU": true, "$MIRRTEXT": true, "$ORTHOMODE": true,
+	"$OSMODE": true, "$PDMODE": true, "$PDSIZE": true, "$PELEVATION": true, "$PEXTMAX": true,
+	"$PEXTMIN": true, "$PLIMCHECK": true, "$PLIMMAX": true, "$PLIMMIN": true, "$PLINEGEN": true,
+	"$PLINEWID": true, "$PSLTSCALE": true, "$PUCSNAME": true, "$PUCSORG": true, "$PUCSXDIR": true,
+	"$PUCSYDIR": true, "$QTEXTMODE": true, "$REGENMODE": true, "$SHADEDGE": true, "$SHADEDIF": true,
+	"$SKETCHINC": true, "$SKPOLY": true, "$SNAPANG": true, "$SNAPBASE": true, "$SNAPISOPAIR": true,
+	"$SNAPMODE": true, "$SNAPSTYLE": true, "$SNAPUNIT": true, "$SPLFRAME": true, "$SPLINESEGS": true,
+	"$SPLINETYPE": true, "$SURFTAB1": true, "$SURFTAB2": true, "$SURFTYPE": true, "$SURFU": true,
+	"$SURFV": true, "$TDCREATE": true, "$TDINDWG": true, "$TDUPDATE": true, "$TDUSRTIMER": true,
+	"$TEXTSIZE": true, "$TEXTSTYLE": true, "$THICKNESS": true, "$TILEMODE": true, "$TRACEWID": true,
+	"$UCSNAME": true, "$UCSORG": true, "$UCSXDIR": true, "$UCSYDIR": true, "$UNITMODE": true,
+	"$USERI1": true, "$USERI2": true, "$USERI3": true, "$USERI4": true, "$USERI5": true,
+	"$USERR1": true, "$USERR2": true, "$USERR3": true, "$USERR4": true, "$USERR5": true,
+	"$USRTIMER": true, "$VISRETAIN": true, "$WORLDVIEW": true,
+}
+
+// hasRecord reports whether the version has records of typ.
+func hasRecord(v format.Version, typ string) bool {
+	if _, exist := r12Codes[typ]; exist {
+		return true
+	}
+	if v == format.R12 {
+		return false
+	}
+	since, exist := recordSince[typ]
+	return !exist || v >= since
+}
+
+// hasCode reports whether the version has group code in records of typ.
+func hasCode(v format.Version, typ string, code int) bool {
+	switch {
+	case code == 999:
+		return true
+	case code >= 1000:
+		// handles in XDATA are not written for R12, as the other handles
+		return code != 1005 || v > format.R12
+	case v == format.R12:
+		return r12Codes[typ][code]
+	}
+	if since, exist := codeSince[typ][code]; exist {
+		return v >= since
+	}
+	return v >= commonSince(code)
+}
+
+// hasVariable reports whether the version has header variable of name.
+func hasVariable(v format.Version, name string) bool {
+	if v == format.R12 {
+		return r12Variables[name]
+	}
+	since, exist := variableSince[name]
+	return !exist || v >= since
+}
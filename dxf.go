@@ -1,16 +1,18 @@
 // Package dxf is a DXF(Drawing Exchange Format) library for golang.
-// ACAD2000(AC1015), ASCII format is only supported.
+// R12(AC1009) through R2018(AC1032) are supported for reading and writing,
+// both in ASCII and binary format.
 // http://www.autodesk.com/techpubs/autocad/acad2000/dxf/index.htm
 package dxf
 
 import (
-	"bufio"
+	"errors"
 	"io"
 	"os"
 	"strings"
 
 	"github.com/flywave/go-dxf/color"
 	"github.com/flywave/go-dxf/drawing"
+	"github.com/flywave/go-dxf/entity"
 	"github.com/flywave/go-dxf/table"
 )
 
@@ -42,79 +44,120 @@ func FromStringData(d string) (*drawing.Drawing, error) {
 	return FromReader(sr)
 }
 
-// Main logic to create a drawing
+// Main logic to create a drawing.
+// Both ASCII and binary DXF are accepted; binary DXF is detected by its sentinel.
 func FromReader(r io.Reader) (*drawing.Drawing, error) {
-	var err error
-	scanner := bufio.NewScanner(r)
 	d := NewDrawing()
-	var code, value string
-	parsers := []func(*drawing.Drawing, int, [][2]string) error{
+	return d, ReadDrawing(d, NewTagReader(r))
+}
+
+// FromFileWithOptions creates a drawing from file with options.
+// Warnings are returned in Lenient mode.
+func FromFileWithOptions(fn string, opts ParseOptions) (*drawing.Drawing, []Warning, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return FromReaderWithOptions(f, opts)
+}
+
+// FromReaderWithOptions creates a drawing from r with options.
+// In Lenient mode, malformed records are skipped and returned as warnings.
+func FromReaderWithOptions(r io.Reader, opts ParseOptions) (*drawing.Drawing, []Warning, error) {
+	tr := NewTagReader(r)
+	tr.SetMode(opts.Mode)
+	if opts.CodePage != "" {
+		if err := tr.SetCodePage(opts.CodePage); err != nil {
+			return nil, nil, err
+		}
+	}
+	d := NewDrawing()
+	err := ReadDrawing(d, tr)
+	return d, tr.Warnings(), err
+}
+
+// ReadDrawing reads every section from the TagReader into the drawing.
+// Unknown sections are skipped.
+// References between elements are resolved after reading all sections,
+// and the elements are given the handles read from the file.
+func ReadDrawing(d *drawing.Drawing, r *TagReader) error {
+	if err := readSections(d, r, ParseEntities); err != nil {
+		return err
+	}
+	resolveReferences(d)
+	d.SetSourceHandles()
+	return nil
+}
+
+// StopWalk is used as a return value from the function passed to WalkEntities
+// to stop reading the rest of the file. It is not returned as an error by WalkEntities.
+var StopWalk = errors.New("stop walking entities")
+
+// WalkEntities reads DXF from r and calls fn for each entity in ENTITIES section
+// as soon as it is read, without keeping entities in a Drawing.
+// Layers and styles are resolved from TABLES section, which precedes ENTITIES.
+// If fn returns an error, reading stops and the error is returned,
+// except for StopWalk or an error wrapping it, for which WalkEntities returns nil.
+func WalkEntities(r io.Reader, fn func(entity.Entity) error) error {
+	d := NewDrawing()
+	err := readSections(d, NewTagReader(r), func(d *drawing.Drawing, r *TagReader) error {
+		return parseEntities(d, r, func(e entity.Entity, h int) error {
+			return fn(e)
+		})
+	})
+	if errors.Is(err, StopWalk) {
+		return nil
+	}
+	return err
+}
+
+// readSections reads every section using given parser for ENTITIES section.
+func readSections(d *drawing.Drawing, r *TagReader, entities func(*drawing.Drawing, *TagReader) error) error {
+	parsers := []func(*drawing.Drawing, *TagReader) error{
 		ParseHeader,
 		ParseClasses,
 		ParseTables,
 		ParseBlocks,
-		ParseEntities,
+		entities,
 		ParseObjects,
 	}
-	data := make([][2]string, 0)
-	setparser := false
-	var parser func(*drawing.Drawing, int, [][2]string) error
-	line := 0
-	startline := 0
-	for scanner.Scan() {
-		line++
-		if line%2 == 1 {
-			code = strings.TrimSpace(scanner.Text())
+	for {
+		t, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if t.Code != 0 {
+			continue
+		}
+		switch strings.ToUpper(t.Value) {
+		case "EOF":
+			return nil
+		case "SECTION":
+			t, err = r.Next()
 			if err != nil {
-				return d, err
+				return err
 			}
-		} else {
-			value = scanner.Text()
-			if setparser {
-				if code != "2" {
-					// return d, fmt.Errorf("FromReader line %d: invalid group code: %s", line, code)
-					continue
-				}
-				ind := drawing.SectionTypeValue(strings.ToUpper(value))
-				if ind < 0 {
-					continue
+			if t.Code != 2 {
+				if err := r.skip("", []Tag{t}, errors.New("invalid section header: expected section name (code 2)")); err != nil {
+					return err
 				}
-				parser = parsers[ind]
-				startline = line + 1
-				setparser = false
+				continue
+			}
+			ind := drawing.SectionTypeValue(strings.ToUpper(t.Value))
+			if ind < 0 {
+				err = skipSection(r)
 			} else {
-				if code == "0" {
-					switch strings.ToUpper(value) {
-					case "EOF":
-						return d, nil
-					case "SECTION":
-						setparser = true
-					case "ENDSEC":
-						err := parser(d, startline, data)
-						if err != nil {
-							return d, err
-						}
-						data = make([][2]string, 0)
-						startline = line + 1
-					default:
-						data = append(data, [2]string{code, scanner.Text()})
-					}
-				} else {
-					data = append(data, [2]string{code, scanner.Text()})
-				}
+				err = parsers[ind](d, r)
+			}
+			if err != nil {
+				return err
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return d, err
-	}
-	if len(data) > 0 {
-		err := parser(d, startline, data)
-		if err != nil {
-			return d, err
-		}
-	}
-	return d, nil
 }
 
 // Open is deprecated, please use FromFile
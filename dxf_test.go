@@ -3,22 +3,34 @@ package dxf_test
 import (
 	"bytes"
 	"crypto/sha1"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/flywave/go-dxf"
+	"github.com/flywave/go-dxf/class"
 	"github.com/flywave/go-dxf/color"
 	geom "github.com/flywave/go-dxf/convert_geom"
 	"github.com/flywave/go-dxf/insunit"
+	"github.com/flywave/go-dxf/mtext"
+	"github.com/flywave/go-dxf/object"
 	"github.com/flywave/go-dxf/table"
 	"github.com/flywave/go-geom/general"
 
 	"github.com/flywave/go-dxf/drawing"
 	"github.com/flywave/go-dxf/entity"
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/header"
+	"github.com/flywave/go-dxf/xdata"
 	vec2d "github.com/flywave/go3d/float64/vec2"
 )
 
@@ -334,6 +346,33 @@ func TestDistance(t *testing.T) {
 	d2 := math.Pow(dis, 2)
 	fmt.Println(math.Sqrt(d2 * 2))
 }
+func TestConvertOCS(t *testing.T) {
+	d := drawing.New()
+	l, _ := d.LwPolyline(false, []float64{1.0, 2.0}, []float64{3.0, 4.0})
+	l.Direction = []float64{0.0, 0.0, -1.0}
+	text, _ := d.Text("12.5", 1.0, 2.0, 0.0, 1.0)
+	text.Direction = []float64{0.0, 0.0, -1.0}
+	filename := filepath.Join(t.TempDir(), "ocs.dxf")
+	if err := d.SaveAs(filename); err != nil {
+		t.Fatalf("save, expected nil got %v", err)
+	}
+	fs, err := geom.ConvertToGeomFeatures(filename, "")
+	if err != nil {
+		t.Fatalf("convert, expected nil got %v", err)
+	}
+	features := fs["0"].Features
+	if len(features) != 2 {
+		t.Fatalf("features, expected 2 got %d", len(features))
+	}
+	ls := features[0].GeometryData.LineString
+	if len(ls) != 2 || !cmpF64(ls[0][0], -1.0) || !cmpF64(ls[0][1], 2.0) || !cmpF64(ls[1][0], -3.0) || !cmpF64(ls[1][1], 4.0) {
+		t.Errorf("lwpolyline, expected (-1, 2)-(-3, 4) got %v", ls)
+	}
+	if p := features[1].GeometryData.Point; len(p) != 2 || !cmpF64(p[0], -1.0) || !cmpF64(p[1], 2.0) {
+		t.Errorf("text, expected (-1, 2) got %v", p)
+	}
+}
+
 func TestDwg(t *testing.T) {
 	d, err := geom.ConvertToGeomFeatures("testdata/修改余吾煤业采掘工程平面图2023.9.26.dxf", "")
 	if err != nil {
@@ -427,3 +466,2244 @@ func TestMegerClosed(t *testing.T) {
 	bt, _ := col2.MarshalJSON()
 	os.WriteFile("testdata/meger5.json", bt, os.ModePerm)
 }
+
+func TestBinary(t *testing.T) {
+	d := drawing.New()
+	d.Point(0.0, 0.0, 0.0)
+	d.Point(100.0, 100.0, 0.0)
+	d.Arc(0.0, 0.0, 0.0, 100.0, 0.0, 60.0)
+	d.SetFormatter(format.NewBinary())
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	if !bytes.HasPrefix(buff.Bytes(), []byte(format.BinarySentinel)) {
+		t.Fatalf("sentinel, expected %q got %q", format.BinarySentinel, buff.Bytes()[:22])
+	}
+	got, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	checkEntities(t, d.Entities(), got.Entities())
+	arc, ok := got.Entities()[2].(*entity.Arc)
+	if !ok {
+		t.Fatalf("type, expected *entity.Arc got %T", got.Entities()[2])
+	}
+	if !cmpF64(arc.Radius, 100.0) || !cmpF64(arc.Angle[1], 60.0) {
+		t.Errorf("arc, expected radius 100 angle 60 got %v %v", arc.Radius, arc.Angle[1])
+	}
+
+	// R12 uses 1 byte group codes, and 255 followed by 16-bit integer above 254
+	d.SetVersion(format.R12)
+	l, _ := d.Line(0.0, 0.0, 0.0, 1.0, 1.0, 0.0)
+	l.SetXData("ACME", xdata.String("note"), xdata.Int32(7))
+	buff.Reset()
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write R12, expected nil got %v", err)
+	}
+	if b := buff.Bytes()[len(format.BinarySentinel):]; b[0] != 0 || b[1] != 'S' {
+		t.Fatalf("R12, expected 1 byte group code got %q", b[:4])
+	}
+	got, err = dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read R12, expected nil got %v", err)
+	}
+	checkEntities(t, d.Entities(), got.Entities())
+	vs, err := got.Entities()[3].(*entity.Line).GetXData("ACME")
+	if err != nil || len(vs) != 2 || vs[0].Value != "note" || vs[1].Value != 7 {
+		t.Errorf("R12 xdata, expected note and 7 got %v %v", vs, err)
+	}
+}
+
+func TestTagReader(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "mypoint.dxf"))
+	if err != nil {
+		t.Fatalf("file, could not open file: %v", err)
+	}
+	defer f.Close()
+	r := dxf.NewTagReader(f)
+	first, err := r.Next()
+	if err != nil {
+		t.Fatalf("next, expected nil got %v", err)
+	}
+	if first.Code != 0 || first.Value != "SECTION" || first.Line != 1 {
+		t.Errorf("first tag, expected 0 SECTION at line 1 got %v %v at line %v", first.Code, first.Value, first.Line)
+	}
+	points := 0
+	line := first.Line
+	for {
+		tag, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next, expected nil got %v", err)
+		}
+		if tag.Line != line+2 {
+			t.Errorf("line, expected %v got %v", line+2, tag.Line)
+		}
+		line = tag.Line
+		if tag.Is(0, "POINT") {
+			points++
+		}
+		if tag.Code == 10 {
+			if _, err := tag.Typed(); err != nil {
+				t.Errorf("typed value at line %v, expected nil got %v", tag.Line, err)
+			}
+		}
+	}
+	if points != 3 {
+		t.Errorf("number of points, expected 3 got %v", points)
+	}
+}
+
+func TestWalkEntities(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "torus.dxf"))
+	if err != nil {
+		t.Fatalf("file, could not open file: %v", err)
+	}
+	defer f.Close()
+	layers := make(map[string]int)
+	err = dxf.WalkEntities(f, func(e entity.Entity) error {
+		if _, ok := e.(*entity.Circle); !ok {
+			t.Errorf("type, expected *entity.Circle got %T", e)
+		}
+		layers[e.Layer().Name()]++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk, expected nil got %v", err)
+	}
+	if layers["Toroidal"] != 16 || layers["Poloidal"] != 16 {
+		t.Errorf("layers, expected 16 Toroidal and 16 Poloidal got %v", layers)
+	}
+
+	f.Seek(0, io.SeekStart)
+	count := 0
+	err = dxf.WalkEntities(f, func(e entity.Entity) error {
+		count++
+		if count == 3 {
+			return dxf.StopWalk
+		}
+		return nil
+	})
+	if err != nil || count != 3 {
+		t.Errorf("stop, expected nil and 3 entities got %v and %v", err, count)
+	}
+
+	f.Seek(0, io.SeekStart)
+	count = 0
+	err = dxf.WalkEntities(f, func(e entity.Entity) error {
+		count++
+		return fmt.Errorf("entity %d: %w", count, dxf.StopWalk)
+	})
+	if err != nil || count != 1 {
+		t.Errorf("wrapped stop, expected nil and 1 entity got %v and %v", err, count)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	d := drawing.New()
+	if _, err := d.Insert("NOTEXIST", 0.0, 0.0, 0.0); err == nil {
+		t.Errorf("insert of unknown block, expected error got nil")
+	}
+	d.AddBlock("MARK", "", 1.0, 2.0, 0.0)
+	i, err := d.Insert("MARK", 10.0, 20.0, 0.0)
+	if err != nil {
+		t.Fatalf("insert, expected nil got %v", err)
+	}
+	i.Scale = []float64{2.0, 3.0, 1.0}
+	i.Rotation = 30.0
+	i.Columns, i.Rows = 2, 3
+	i.ColumnSpacing, i.RowSpacing = 5.0, 6.0
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	got, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	if _, err := got.Block("MARK"); err != nil {
+		t.Errorf("block, expected nil got %v", err)
+	}
+	if len(got.Entities()) != 1 {
+		t.Fatalf("number of entities, expected 1 got %v", len(got.Entities()))
+	}
+	gi, ok := got.Entities()[0].(*entity.Insert)
+	if !ok {
+		t.Fatalf("type, expected *entity.Insert got %T", got.Entities()[0])
+	}
+	if gi.BlockName != "MARK" || !cmpF64(gi.Coord[1], 20.0) || !cmpF64(gi.Scale[1], 3.0) ||
+		!cmpF64(gi.Rotation, 30.0) || gi.Columns != 2 || gi.Rows != 3 || !cmpF64(gi.RowSpacing, 6.0) {
+		t.Errorf("insert, expected %v got %v", i, gi)
+	}
+}
+
+func TestBlockEntities(t *testing.T) {
+	d := drawing.New()
+	b, _ := d.AddBlock("FRAME", "frame", 0.0, 0.0, 0.0)
+	l := entity.NewLine()
+	l.End = []float64{10.0, 0.0, 0.0}
+	b.AddEntity(l)
+	c := entity.NewCircle()
+	c.Radius = 5.0
+	b.AddEntity(c)
+	d.Insert("FRAME", 0.0, 0.0, 0.0)
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	got, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	gb, err := got.Block("FRAME")
+	if err != nil {
+		t.Fatalf("block, expected nil got %v", err)
+	}
+	if len(gb.Entities) != 2 {
+		t.Fatalf("number of block entities, expected 2 got %v", len(gb.Entities))
+	}
+	if gl, ok := gb.Entities[0].(*entity.Line); !ok || !cmpF64(gl.End[0], 10.0) {
+		t.Errorf("block entity 0, expected %v got %v", l, gb.Entities[0])
+	}
+	if gc, ok := gb.Entities[1].(*entity.Circle); !ok || !cmpF64(gc.Radius, 5.0) {
+		t.Errorf("block entity 1, expected %v got %v", c, gb.Entities[1])
+	}
+	if len(got.Entities()) != 1 {
+		t.Errorf("number of entities, expected 1 got %v", len(got.Entities()))
+	}
+}
+
+func TestExplode(t *testing.T) {
+	d := drawing.New()
+	inner, _ := d.AddBlock("INNER", "", 1.0, 0.0, 0.0)
+	l := entity.NewLine()
+	l.Start = []float64{1.0, 0.0, 0.0}
+	l.End = []float64{2.0, 0.0, 0.0}
+	inner.AddEntity(l)
+	c := entity.NewCircle()
+	c.Center = []float64{1.0, 1.0, 0.0}
+	c.Radius = 1.0
+	inner.AddEntity(c)
+	outer, _ := d.AddBlock("OUTER", "", 0.0, 0.0, 0.0)
+	nested := entity.NewInsert("INNER")
+	nested.Coord = []float64{100.0, 0.0, 0.0}
+	outer.AddEntity(nested)
+
+	i, _ := d.Insert("INNER", 10.0, 0.0, 0.0)
+	i.Scale = []float64{2.0, 2.0, 2.0}
+	i.Rotation = 90.0
+	i.Columns = 2
+	i.ColumnSpacing = 5.0
+	d.Insert("OUTER", 0.0, 0.0, 0.0)
+
+	es, err := d.Explode(i)
+	if err != nil {
+		t.Fatalf("explode, expected nil got %v", err)
+	}
+	if len(es) != 4 {
+		t.Fatalf("number of entities, expected 4 got %v", len(es))
+	}
+	expected := [][]float64{{10.0, 0.0, 0.0}, {10.0, 2.0, 0.0}, {10.0, 5.0, 0.0}, {10.0, 7.0, 0.0}}
+	for j, e := range []entity.Entity{es[0], es[2]} {
+		gl := e.(*entity.Line)
+		for k := 0; k < 3; k++ {
+			if !cmpF64(gl.Start[k], expected[2*j][k]) || !cmpF64(gl.End[k], expected[2*j+1][k]) {
+				t.Errorf("line %v, expected %v-%v got %v-%v", j, expected[2*j], expected[2*j+1], gl.Start, gl.End)
+				break
+			}
+		}
+	}
+	gc := es[1].(*entity.Circle)
+	if !cmpF64(gc.Center[0], 8.0) || !cmpF64(gc.Center[1], 0.0) || !cmpF64(gc.Radius, 2.0) {
+		t.Errorf("circle, expected (8, 0) r=2 got %v r=%v", gc.Center, gc.Radius)
+	}
+	if !cmpF64(l.Start[0], 1.0) {
+		t.Errorf("block entity was modified: %v", l.Start)
+	}
+
+	if err := d.ExplodeAll(); err != nil {
+		t.Fatalf("explode all, expected nil got %v", err)
+	}
+	if len(d.Entities()) != 6 {
+		t.Fatalf("number of entities, expected 6 got %v", len(d.Entities()))
+	}
+	gl := d.Entities()[4].(*entity.Line)
+	if !cmpF64(gl.Start[0], 100.0) || !cmpF64(gl.End[0], 101.0) {
+		t.Errorf("nested line, expected 100-101 got %v-%v", gl.Start, gl.End)
+	}
+
+	// non-uniform scale and mirroring
+	shape, _ := d.AddBlock("SHAPE", "", 0.0, 0.0, 0.0)
+	sc := entity.NewCircle()
+	sc.Radius = 1.0
+	shape.AddEntity(sc)
+	st := entity.NewText()
+	st.Coord1 = []float64{1.0, 0.0, 0.0}
+	st.Rotation = 90.0
+	st.WidthFactor = 1.0
+	shape.AddEntity(st)
+	si := entity.NewInsert("SHAPE")
+	si.Scale = []float64{-2.0, 1.0, 1.0}
+	es, err = d.Explode(si)
+	if err != nil {
+		t.Fatalf("explode scaled, expected nil got %v", err)
+	}
+	ge, ok := es[0].(*entity.Ellipse)
+	if !ok {
+		t.Fatalf("scaled circle, expected *entity.Ellipse got %T", es[0])
+	}
+	if !cmpF64(geometry.Length(ge.MajorAxis), 2.0) || !cmpF64(ge.Ratio, 0.5) || !ge.IsFull() {
+		t.Errorf("scaled circle, expected full ellipse of major 2 ratio 0.5 got %v %v %v-%v", ge.MajorAxis, ge.Ratio, ge.Start, ge.End)
+	}
+	// mirrored text has flipped extrusion direction, whose OCS X axis is -X of WCS
+	gt := es[1].(*entity.Text)
+	if gt.Direction[2] != -1.0 || !cmpF64(gt.Coord1[0], 2.0) || !cmpF64(gt.Rotation, 90.0) {
+		t.Errorf("mirrored text, expected direction (0,0,-1) at OCS (2,0) rotation 90 got %v %v %v", gt.Direction, gt.Coord1, gt.Rotation)
+	}
+	if !cmpF64(gt.Height, 2.0) || !cmpF64(gt.WidthFactor, 0.5) || !cmpF64(gt.ObliqueAngle, 0.0) {
+		t.Errorf("mirrored text, expected height 2 width 0.5 oblique 0 got %v %v %v", gt.Height, gt.WidthFactor, gt.ObliqueAngle)
+	}
+}
+
+func TestPolyline(t *testing.T) {
+	d := drawing.New()
+	p := entity.NewPolyline()
+	p.Flag = entity.POLYLINE_CLOSED
+	p.Elevation = 3.0
+	v := p.AddVertex(0.0, 0.0, 0.0)
+	v.Bulge = 1.0
+	v.StartWidth = 0.5
+	v.EndWidth = 0.25
+	p.AddVertex(10.0, 0.0, 0.0)
+	d.AddEntity(p)
+
+	m := entity.NewPolyline()
+	m.Flag = entity.POLYLINE_POLYFACE_MESH
+	m.MCount = 4
+	m.NCount = 1
+	m.AddVertex(0.0, 0.0, 0.0)
+	m.AddVertex(1.0, 0.0, 0.0)
+	m.AddVertex(1.0, 1.0, 0.0)
+	m.AddVertex(0.0, 1.0, 0.0)
+	m.AddFace(1, 2, 3, -4)
+	d.AddEntity(m)
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	es := r.Entities()
+	if len(es) != 2 {
+		t.Fatalf("number of entities, expected 2 got %v", len(es))
+	}
+	gp, ok := es[0].(*entity.Polyline)
+	if !ok {
+		t.Fatalf("type, expected *entity.Polyline got %T", es[0])
+	}
+	if !gp.Is2D() || gp.Flag != entity.POLYLINE_CLOSED || gp.Elevation != 3.0 {
+		t.Errorf("polyline, expected 2D closed elevation 3 got flag %v elevation %v", gp.Flag, gp.Elevation)
+	}
+	if len(gp.Vertices) != 2 {
+		t.Fatalf("number of vertices, expected 2 got %v", len(gp.Vertices))
+	}
+	gv := gp.Vertices[0]
+	if gv.Bulge != 1.0 || gv.StartWidth != 0.5 || gv.EndWidth != 0.25 {
+		t.Errorf("vertex, expected bulge 1 widths 0.5/0.25 got %v %v/%v", gv.Bulge, gv.StartWidth, gv.EndWidth)
+	}
+	if gp.Vertices[1].Coord[0] != 10.0 {
+		t.Errorf("vertex, expected x=10 got %v", gp.Vertices[1].Coord)
+	}
+	gm := es[1].(*entity.Polyline)
+	if !gm.IsPolyfaceMesh() || gm.MCount != 4 || gm.NCount != 1 || len(gm.Vertices) != 5 {
+		t.Fatalf("polyface, expected 4 vertices 1 face got %v %v %v", gm.MCount, gm.NCount, len(gm.Vertices))
+	}
+	f := gm.Vertices[4]
+	if !f.IsFaceRecord() || f.Indices[0] != 1 || f.Indices[3] != -4 {
+		t.Errorf("face, expected [1 2 3 -4] got %v", f.Indices)
+	}
+	mins, maxs := gm.BBox()
+	if mins[0] != 0.0 || maxs[1] != 1.0 {
+		t.Errorf("bbox, expected (0,0,0)-(1,1,0) got %v-%v", mins, maxs)
+	}
+}
+
+func TestLwPolylineBulge(t *testing.T) {
+	d := drawing.New()
+	l, _ := d.LwPolyline(true, []float64{0.0, 0.0}, []float64{2.0, 0.0})
+	l.SetBulge(0, 1.0)
+	l.SetBulge(1, 1.0)
+	l.SetWidth(0, 0.1, 0.2)
+	l.Elevation = 5.0
+	l.Thickness = 2.0
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	gl := r.Entities()[0].(*entity.LwPolyline)
+	if gl.Bulge(0) != 1.0 || gl.Bulge(1) != 1.0 || gl.Elevation != 5.0 || gl.Thickness != 2.0 {
+		t.Errorf("lwpolyline, expected bulges 1, elevation 5, thickness 2 got %v %v %v", gl.Bulges, gl.Elevation, gl.Thickness)
+	}
+	if sw, ew := gl.Width(0); sw != 0.1 || ew != 0.2 {
+		t.Errorf("width, expected 0.1/0.2 got %v/%v", sw, ew)
+	}
+
+	// two semicircles make a circle of radius 1 around (1, 0)
+	mins, maxs := gl.BBox()
+	expected := [][]float64{{0.0, -1.0, 5.0}, {2.0, 1.0, 5.0}}
+	for i := 0; i < 3; i++ {
+		if !cmpF64(mins[i], expected[0][i]) || !cmpF64(maxs[i], expected[1][i]) {
+			t.Fatalf("bbox, expected %v-%v got %v-%v", expected[0], expected[1], mins, maxs)
+		}
+	}
+	ps := gl.Points(0.01)
+	if len(ps) < 10 {
+		t.Fatalf("points, expected tessellated arcs got %v", ps)
+	}
+	for _, p := range ps {
+		if !cmpF64(math.Hypot(p[0]-1.0, p[1]), 1.0) {
+			t.Errorf("point %v is not on the circle", p)
+		}
+	}
+	if ps[len(ps)/4][1] >= 0.0 {
+		t.Errorf("first arc, expected below the chord got %v", ps[len(ps)/4])
+	}
+
+	// zero sweep degenerates to a segment
+	for _, tol := range []float64{0.01, 2.0} {
+		ps = geometry.ArcPoints([]float64{1.0, 0.0}, 1.0, math.Pi/2.0, 0.0, tol)
+		if len(ps) != 2 || !cmpF64(ps[0][0], 1.0) || !cmpF64(ps[0][1], 1.0) || !cmpF64(ps[1][0], 1.0) || !cmpF64(ps[1][1], 1.0) {
+			t.Errorf("zero sweep, tolerance %v, expected (1, 1)-(1, 1) got %v", tol, ps)
+		}
+	}
+}
+
+func TestEllipse(t *testing.T) {
+	d := drawing.New()
+	d.Ellipse(1.0, 2.0, 0.0, 0.0, 4.0, 0.5, 0.0, math.Pi)
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	e, ok := r.Entities()[0].(*entity.Ellipse)
+	if !ok {
+		t.Fatalf("type, expected *entity.Ellipse got %T", r.Entities()[0])
+	}
+	if e.Ratio != 0.5 || e.MajorAxis[1] != 4.0 || !cmpF64(e.End, math.Pi) {
+		t.Errorf("ellipse, expected ratio 0.5 major (0,4,0) end Pi got %v %v %v", e.Ratio, e.MajorAxis, e.End)
+	}
+
+	// major axis along Y, minor axis (-2, 0, 0); the half from 0 to Pi lies on the left
+	mins, maxs := e.BBox()
+	expected := [][]float64{{-1.0, -2.0, 0.0}, {1.0, 6.0, 0.0}}
+	for i := 0; i < 3; i++ {
+		if !cmpF64(mins[i], expected[0][i]) || !cmpF64(maxs[i], expected[1][i]) {
+			t.Fatalf("bbox, expected %v-%v got %v-%v", expected[0], expected[1], mins, maxs)
+		}
+	}
+
+	e.Transform(geometry.Scaling(3.0, 1.0, 1.0))
+	if !cmpF64(geometry.Length(e.MajorAxis), 6.0) || !cmpF64(e.Ratio, 4.0/6.0) {
+		t.Errorf("transform, expected major 6 ratio 2/3 got %v %v", e.MajorAxis, e.Ratio)
+	}
+	mins, maxs = e.BBox()
+	expected = [][]float64{{-3.0, -2.0, 0.0}, {3.0, 6.0, 0.0}}
+	for i := 0; i < 3; i++ {
+		if !cmpF64(mins[i], expected[0][i]) || !cmpF64(maxs[i], expected[1][i]) {
+			t.Fatalf("transformed bbox, expected %v-%v got %v-%v", expected[0], expected[1], mins, maxs)
+		}
+	}
+}
+
+func TestSpline(t *testing.T) {
+	d := drawing.New()
+	s := entity.NewSpline()
+	s.Degree = 2
+	s.Knots = []float64{0.0, 0.0, 0.0, 1.0, 1.0, 1.0}
+	s.Controls = [][]float64{{1.0, 0.0, 0.0}, {1.0, 1.0, 0.0}, {0.0, 1.0, 0.0}}
+	s.Weights = []float64{1.0, math.Sqrt2 / 2.0, 1.0}
+	s.StartTangent = []float64{0.0, 1.0, 0.0}
+	d.AddEntity(s)
+	c := entity.NewSpline()
+	c.Knots = []float64{0.0, 0.0, 0.0, 0.0, 1.0, 1.0, 1.0, 1.0}
+	c.Controls = [][]float64{{0.0, 0.0, 0.0}, {1.0, 2.0, 0.0}, {2.0, -2.0, 0.0}, {3.0, 0.0, 0.0}}
+	d.AddEntity(c)
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	if len(r.Entities()) != 2 {
+		t.Fatalf("number of entities, expected 2 got %v", len(r.Entities()))
+	}
+	gs := r.Entities()[0].(*entity.Spline)
+	if gs.Degree != 2 || len(gs.Knots) != 6 || len(gs.Controls) != 3 || len(gs.Weights) != 3 || gs.StartTangent[1] != 1.0 {
+		t.Fatalf("spline, got degree %v knots %v controls %v weights %v tangent %v", gs.Degree, gs.Knots, gs.Controls, gs.Weights, gs.StartTangent)
+	}
+
+	// rational quadratic quarter circle
+	ps := gs.Points(0.0001)
+	if len(ps) < 8 {
+		t.Errorf("points, expected tessellated curve got %v", len(ps))
+	}
+	for _, p := range ps {
+		if math.Abs(math.Hypot(p[0], p[1])-1.0) > 1e-9 {
+			t.Errorf("point %v is not on the circle", p)
+			break
+		}
+	}
+	n, _ := gs.NURBS()
+	h := 1e-6
+	for _, u := range []float64{0.1, 0.5, 0.9} {
+		p1, p2, dv := n.Point(u-h), n.Point(u+h), n.Derivative(u)
+		for i := 0; i < 2; i++ {
+			if math.Abs((p2[i]-p1[i])/(2*h)-dv[i]) > 1e-5 {
+				t.Errorf("derivative at %v, expected %v got %v", u, (p2[i]-p1[i])/(2*h), dv[i])
+			}
+		}
+	}
+
+	// cubic Bezier is within [-1/sqrt(3), 1/sqrt(3)] while control points are in [-2, 2]
+	mins, maxs := r.Entities()[1].BBox()
+	expected := [][]float64{{0.0, -1.0 / math.Sqrt(3.0), 0.0}, {3.0, 1.0 / math.Sqrt(3.0), 0.0}}
+	for i := 0; i < 3; i++ {
+		if !cmpF64(mins[i], expected[0][i]) || !cmpF64(maxs[i], expected[1][i]) {
+			t.Fatalf("bbox, expected %v-%v got %v-%v", expected[0], expected[1], mins, maxs)
+		}
+	}
+}
+
+func TestMText(t *testing.T) {
+	d := drawing.New()
+	long := strings.Repeat("0123456789", 30)
+	m, _ := d.MText(long+`\P{\fArial|b1|i0;\H2x;\C1;Bold} 45%%d \S1/2;`, 1.0, 2.0, 0.0, 2.5, 40.0)
+	m.Attachment = entity.MTEXT_MIDDLE_CENTER
+	m.SetRotation(90.0)
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	gm, ok := r.Entities()[0].(*entity.MText)
+	if !ok {
+		t.Fatalf("type, expected *entity.MText got %T", r.Entities()[0])
+	}
+	if gm.Value != m.Value {
+		t.Errorf("value, expected %q got %q", m.Value, gm.Value)
+	}
+	if gm.Height != 2.5 || gm.Width != 40.0 || gm.Attachment != entity.MTEXT_MIDDLE_CENTER || !cmpF64(gm.Rotation(), 90.0) {
+		t.Errorf("mtext, got height %v width %v attachment %v rotation %v", gm.Height, gm.Width, gm.Attachment, gm.Rotation())
+	}
+	expected := long + "\nBold 45° 1/2"
+	if got := gm.PlainText(); got != expected {
+		t.Errorf("plain text, expected %q got %q", expected, got)
+	}
+
+	runs := gm.Runs()
+	if len(runs) != 4 {
+		t.Fatalf("runs, expected 4 got %v", runs)
+	}
+	bold := runs[1].Style
+	if runs[1].Text != "Bold" || bold.Font != "Arial" || !bold.Bold || bold.Italic || bold.HeightFactor != 2.0 || bold.Color != 1 {
+		t.Errorf("styled run, got %q %+v", runs[1].Text, bold)
+	}
+	if runs[2].Style.Bold || runs[2].Style.Color != 256 {
+		t.Errorf("style after group, expected default got %+v", runs[2].Style)
+	}
+	if s := runs[3].Stack; s == nil || s.Upper != "1" || s.Lower != "2" || s.Type != '/' {
+		t.Errorf("stack, expected 1/2 got %+v", s)
+	}
+
+	for _, c := range []struct {
+		src string
+		typ byte
+	}{
+		{"A\\S1/2;B", '/'},
+		{"A\\S1#2;B", '#'},
+		{"A\\S1^ 2;B", '^'},
+	} {
+		runs := mtext.Parse(c.src)
+		if len(runs) != 3 || runs[1].Text != "1/2" {
+			t.Fatalf("stack %q, expected 3 runs with 1/2 got %v", c.src, runs)
+		}
+		if s := runs[1].Stack; s == nil || s.Upper != "1" || s.Lower != "2" || s.Type != c.typ {
+			t.Errorf("stack %q, expected 1 %c 2 got %+v", c.src, c.typ, s)
+		}
+		if p := mtext.PlainText(c.src); p != "A1/2B" {
+			t.Errorf("stack %q, expected plain text A1/2B got %q", c.src, p)
+		}
+	}
+
+	// bounding box follows the attachment point and the rotation
+	for _, c := range []struct {
+		attachment int
+		rotation   float64
+		mins, maxs []float64
+	}{
+		{entity.MTEXT_TOP_LEFT, 0.0, []float64{1.0, -0.5, 0.0}, []float64{41.0, 2.0, 0.0}},
+		{entity.MTEXT_BOTTOM_RIGHT, 0.0, []float64{-39.0, 2.0, 0.0}, []float64{1.0, 4.5, 0.0}},
+		{entity.MTEXT_MIDDLE_CENTER, 90.0, []float64{-0.25, -18.0, 0.0}, []float64{2.25, 22.0, 0.0}},
+	} {
+		b, _ := d.MText("text", 1.0, 2.0, 0.0, 2.5, 40.0)
+		b.Attachment = c.attachment
+		b.SetRotation(c.rotation)
+		mins, maxs := b.BBox()
+		for i := 0; i < 3; i++ {
+			if !cmpF64(mins[i], c.mins[i]) || !cmpF64(maxs[i], c.maxs[i]) {
+				t.Errorf("bbox of %d rotated by %v, expected %v %v got %v %v", c.attachment, c.rotation, c.mins, c.maxs, mins, maxs)
+				break
+			}
+		}
+	}
+
+	// embedded object of R2018 doesn't overwrite the geometry
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "MTEXT", "5", "2A", "100", "AcDbEntity", "8", "0", "100", "AcDbMText",
+		"10", "1.0", "20", "2.0", "30", "0.0", "40", "2.5", "41", "40.0", "71", "5", "72", "1",
+		"1", "text", "11", "0.0", "21", "1.0", "31", "0.0",
+		"101", "Embedded Object", "70", "1", "10", "1.0", "20", "0.0", "30", "0.0",
+		"11", "9.0", "21", "8.0", "31", "0.0", "40", "100.0", "41", "3.5", "42", "12.0", "43", "2.5",
+		"71", "2", "72", "1", "44", "50.0", "45", "0.0", "73", "0", "74", "0", "46", "0.0",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	r, err = dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("embedded object, expected nil got %v", err)
+	}
+	em := r.Entities()[0].(*entity.MText)
+	if !cmpF64(em.Coord[0], 1.0) || !cmpF64(em.Coord[1], 2.0) || em.Height != 2.5 || em.Width != 40.0 ||
+		em.Attachment != entity.MTEXT_MIDDLE_CENTER || !cmpF64(em.Rotation(), 90.0) || em.Value != "text" {
+		t.Errorf("embedded object, expected the geometry of MTEXT got %v %v %v %v %v %v",
+			em.Coord, em.Height, em.Width, em.Attachment, em.Rotation(), em.Value)
+	}
+}
+
+func TestHatch(t *testing.T) {
+	d := drawing.New()
+	// gradient is written for R2004 and later
+	d.SetVersion(format.R2018)
+	h, _ := d.Hatch([]float64{0.0, 0.0}, []float64{10.0, 0.0}, []float64{10.0, 10.0}, []float64{0.0, 10.0})
+	h.Paths[0].Bulges = []float64{0.0, 0.0, 0.0, 0.0}
+	// hole: circle of radius 3 around (5, 5)
+	hole := h.AddEdgePath(&entity.ArcEdge{Center: []float64{5.0, 5.0}, Radius: 3.0, StartAngle: 0.0, EndAngle: 360.0, CounterClockwise: true})
+	hole.Flag = 0
+	// associative hatch: the hole follows a circle
+	source, _ := d.Circle(5.0, 5.0, 0.0, 3.0)
+	hole.Sources = []handle.Handler{source}
+	// island: triangle inside the hole with an elliptic and a spline edge
+	island := h.AddEdgePath(
+		&entity.LineEdge{Start: []float64{4.0, 4.0}, End: []float64{6.0, 4.0}},
+		&entity.EllipseEdge{Center: []float64{5.0, 4.0}, MajorAxis: []float64{1.0, 0.0}, Ratio: 0.5, StartAngle: 0.0, EndAngle: 90.0, CounterClockwise: true},
+		&entity.SplineEdge{Degree: 1, Knots: []float64{0.0, 0.0, 1.0, 1.0}, Controls: [][]float64{{5.0, 4.5}, {4.0, 4.0}}},
+	)
+	island.Flag = 0
+	h.Solid = false
+	h.PatternName = "ANSI31"
+	h.PatternAngle = 45.0
+	h.PatternLines = append(h.PatternLines, &entity.PatternLine{Angle: 45.0, Base: []float64{0.0, 0.0}, Offset: []float64{-2.2, 2.2}, Dashes: []float64{1.0, -0.5}})
+	h.Seeds = append(h.Seeds, []float64{1.0, 1.0})
+	h.Gradient = &entity.Gradient{Name: "LINEAR", Angle: 0.5, Colors: []entity.GradientColor{{Value: 0.0, Color: 5, RGB: 255}, {Value: 1.0, Color: 2, RGB: 65280}}}
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	gh, ok := r.Entities()[0].(*entity.Hatch)
+	if !ok {
+		t.Fatalf("type, expected *entity.Hatch got %T", r.Entities()[0])
+	}
+	if len(gh.Paths) != 3 || !gh.Paths[0].IsPolyline() || len(gh.Paths[0].Vertices) != 4 || len(gh.Paths[2].Edges) != 3 {
+		t.Fatalf("paths, got %+v", gh.Paths)
+	}
+	if e, ok := gh.Paths[2].Edges[1].(*entity.EllipseEdge); !ok || e.Ratio != 0.5 || e.EndAngle != 90.0 {
+		t.Errorf("ellipse edge, got %+v", gh.Paths[2].Edges[1])
+	}
+	if e, ok := gh.Paths[2].Edges[2].(*entity.SplineEdge); !ok || e.Degree != 1 || len(e.Knots) != 4 || len(e.Controls) != 2 {
+		t.Errorf("spline edge, got %+v", gh.Paths[2].Edges[2])
+	}
+	if gh.Solid || gh.PatternName != "ANSI31" || len(gh.PatternLines) != 1 || len(gh.PatternLines[0].Dashes) != 2 {
+		t.Errorf("pattern, got %v %v %+v", gh.Solid, gh.PatternName, gh.PatternLines)
+	}
+	if c, ok := gh.Paths[1].Sources[0].(*entity.Circle); !ok || c != r.Entities()[1] {
+		t.Errorf("sources, expected the circle got %v", gh.Paths[1].Sources)
+	}
+	if len(gh.Seeds) != 1 || gh.Seeds[0][0] != 1.0 {
+		t.Errorf("seeds, expected [[1 1]] got %v", gh.Seeds)
+	}
+	if g := gh.Gradient; g == nil || g.Name != "LINEAR" || len(g.Colors) != 2 || g.Colors[1].RGB != 65280 || g.Colors[0].Color != 5 {
+		t.Errorf("gradient, got %+v", gh.Gradient)
+	}
+
+	polygons := gh.Polygons(0.01)
+	if len(polygons) != 2 {
+		t.Fatalf("polygons, expected 2 got %v", len(polygons))
+	}
+	if len(polygons[0]) != 2 || len(polygons[1]) != 1 {
+		t.Errorf("rings, expected square with hole and island got %v and %v rings", len(polygons[0]), len(polygons[1]))
+	}
+	hr := polygons[0][1]
+	if math.Abs(math.Abs(geometry.PolygonArea(hr))-9.0*math.Pi) > 0.2 {
+		t.Errorf("hole area, expected about %v got %v", 9.0*math.Pi, geometry.PolygonArea(hr))
+	}
+
+	h.Transform(geometry.Translation(1.0, 2.0, 3.0))
+	mins, maxs := h.BBox()
+	if !cmpF64(mins[0], 1.0) || !cmpF64(mins[1], 2.0) || !cmpF64(maxs[0], 11.0) || !cmpF64(maxs[1], 12.0) || !cmpF64(mins[2], 3.0) {
+		t.Errorf("bbox, expected (1,2,3)-(11,12,3) got %v-%v", mins, maxs)
+	}
+}
+
+func TestDimension(t *testing.T) {
+	d := drawing.New()
+	if _, err := d.LinearDimension([]float64{0.0, 0.0}, []float64{10.0, 5.0}, []float64{0.0, -2.0}, 0.0); err != nil {
+		t.Fatalf("linear, expected nil got %v", err)
+	}
+	d.AlignedDimension([]float64{0.0, 0.0}, []float64{3.0, 4.0}, []float64{-1.0, 1.0})
+	d.RadialDimension([]float64{0.0, 0.0}, []float64{0.0, 2.0}, 1.0)
+	d.DiameterDimension([]float64{-2.0, 0.0}, []float64{2.0, 0.0}, 1.0)
+	d.AngularDimension([]float64{0.0, 0.0}, []float64{1.0, 0.0}, []float64{0.0, 1.0}, []float64{2.0, 2.0})
+	d.OrdinateDimension([]float64{0.0, 0.0}, []float64{4.0, 3.0}, []float64{4.0, 6.0}, true)
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	expected := []struct {
+		typ         int
+		measurement float64
+	}{
+		{entity.DIM_LINEAR, 10.0},
+		{entity.DIM_ALIGNED, 5.0},
+		{entity.DIM_RADIUS, 2.0},
+		{entity.DIM_DIAMETER, 4.0},
+		{entity.DIM_ANGULAR3P, math.Pi / 2.0},
+		{entity.DIM_ORDINATE, 4.0},
+	}
+	es := r.Entities()
+	if len(es) != len(expected) {
+		t.Fatalf("entities, expected %d got %d", len(expected), len(es))
+	}
+	for i, e := range expected {
+		dm, ok := es[i].(*entity.Dimension)
+		if !ok {
+			t.Fatalf("type, expected *entity.Dimension got %T", es[i])
+		}
+		if dm.DimType() != e.typ || !cmpF64(dm.Measurement, e.measurement) {
+			t.Errorf("dimension %d, expected type %d measurement %v got %d %v", i, e.typ, e.measurement, dm.DimType(), dm.Measurement)
+		}
+		if dm.DimStyle.Name() != "Standard" {
+			t.Errorf("dimstyle, expected Standard got %s", dm.DimStyle.Name())
+		}
+		b, err := r.Block(dm.BlockName)
+		if err != nil || len(b.Entities) == 0 {
+			t.Errorf("block %s, expected entities got %v", dm.BlockName, err)
+		}
+	}
+	dm := es[0].(*entity.Dimension)
+	if dm.BlockName != "*D0" || !cmpF64(dm.DefPoint[0], 10.0) || !cmpF64(dm.DefPoint[1], -2.0) {
+		t.Errorf("linear, expected *D0 at (10,-2) got %s at %v", dm.BlockName, dm.DefPoint)
+	}
+	if es[5].(*entity.Dimension).Type&entity.DIM_ORDINATE_X == 0 {
+		t.Errorf("ordinate, expected X type got %d", es[5].(*entity.Dimension).Type)
+	}
+	if s, err := r.AddDimStyle("STANDARD"); err == nil || s != dm.DimStyle {
+		t.Errorf("dimstyle, expected existing Standard for STANDARD got %v %v", s, err)
+	}
+	if n := len(r.Sections[drawing.TABLES].(table.Tables)[table.DIMSTYLE].Records()); n != 1 {
+		t.Errorf("dimstyle table, expected 1 record got %d", n)
+	}
+
+	tilted := entity.NewDimension(entity.DIM_LINEAR)
+	tilted.Direction = []float64{0.0, 1.0, 0.0}
+	if err := r.RenderDimension(tilted); err == nil {
+		t.Errorf("extrusion, expected error got nil")
+	}
+	if tilted.BlockName != "" {
+		t.Errorf("extrusion, expected no block got %s", tilted.BlockName)
+	}
+}
+
+func TestLeader(t *testing.T) {
+	d := drawing.New()
+	// MULTILEADER is written for R2007 and later
+	d.SetVersion(format.R2018)
+	l, err := d.Leader([]float64{0.0, 0.0, 0.0}, []float64{5.0, 5.0, 0.0}, []float64{8.0, 5.0, 0.0})
+	if err != nil {
+		t.Fatalf("leader, expected nil got %v", err)
+	}
+	text, _ := d.MText("note", 8.5, 5.0, 0.0, 1.0, 0.0)
+	l.AnnotationType = entity.LEADER_MTEXT
+	l.Annotation = text
+	l.Hookline = true
+	ml, err := d.MLeader("label", 20.0, 10.0, 0.0, 2.5, []float64{10.0, 0.0, 0.0}, []float64{15.0, 5.0, 0.0}, []float64{18.0, 10.0, 0.0})
+	if err != nil {
+		t.Fatalf("multileader, expected nil got %v", err)
+	}
+	ml.AddLeader([]float64{18.0, 10.0, 0.0}, []float64{12.0, 14.0, 0.0})
+	ml.Context.Branches[0].Breaks = []entity.MLeaderBreak{{Start: []float64{16.0, 10.0, 0.0}, End: []float64{17.0, 10.0, 0.0}}}
+	ml.Context.Text.ColumnType = 2
+	ml.Context.Text.ColumnWidth = 30.0
+	ml.Attributes = append(ml.Attributes, entity.MLeaderAttribute{Attdef: handle.Ref(0x2A), Index: 1, Width: 3.0, Text: "A"})
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	es := r.Entities()
+	if len(es) != 3 {
+		t.Fatalf("entities, expected 3 got %d", len(es))
+	}
+	gl, ok := es[0].(*entity.Leader)
+	if !ok {
+		t.Fatalf("type, expected *entity.Leader got %T", es[0])
+	}
+	if len(gl.Vertices) != 3 || gl.Vertices[2][0] != 8.0 || !gl.Hookline || gl.AnnotationType != entity.LEADER_MTEXT {
+		t.Errorf("leader, got %+v", gl)
+	}
+	if gl.Annotation != es[1] {
+		t.Errorf("annotation, expected %v got %v", es[1], gl.Annotation)
+	}
+	gm, ok := es[2].(*entity.MLeader)
+	if !ok {
+		t.Fatalf("type, expected *entity.MLeader got %T", es[2])
+	}
+	c := gm.Context
+	if c.Text == nil || c.Text.Value != "label" || c.TextHeight != 2.5 || c.Block != nil {
+		t.Fatalf("context, got %+v", c)
+	}
+	if c.Text.BackgroundScale != 1.5 || c.Text.ColumnWidth != 30.0 {
+		t.Errorf("text, expected background scale 1.5 and column width 30 got %v %v", c.Text.BackgroundScale, c.Text.ColumnWidth)
+	}
+	if len(c.Branches) != 2 || len(c.Branches[0].Lines[0].Vertices) != 2 || c.Branches[1].Index != 1 {
+		t.Fatalf("branches, got %+v", c.Branches)
+	}
+	if len(c.Branches[0].Breaks) != 1 || c.Branches[0].Breaks[0].End[0] != 17.0 {
+		t.Errorf("breaks, got %+v", c.Branches[0].Breaks)
+	}
+	if len(gm.Attributes) != 1 || gm.Attributes[0].Attdef.Handle() != 0x2A || gm.Attributes[0].Text != "A" {
+		t.Errorf("attributes, got %+v", gm.Attributes)
+	}
+
+	gm.Transform(geometry.Translation(1.0, 1.0, 0.0))
+	mins, maxs := gm.BBox()
+	if !cmpF64(mins[0], 11.0) || !cmpF64(mins[1], 1.0) || !cmpF64(maxs[0], 21.0) || !cmpF64(maxs[1], 15.0) {
+		t.Errorf("bbox, expected (11,1)-(21,15) got %v-%v", mins, maxs)
+	}
+}
+
+func TestExplodeUnknown(t *testing.T) {
+	d := drawing.New()
+	b, _ := d.AddBlock("BLK", "", 0.0, 0.0, 0.0)
+	tag := func(code int, value string) format.Tag {
+		return format.Tag{Code: code, Value: value}
+	}
+	b.AddEntity(entity.NewUnknown("SOLID", []format.Tag{
+		tag(100, "AcDbEntity"), tag(8, "0"), tag(100, "AcDbTrace"),
+		tag(10, "0.0"), tag(20, "0.0"), tag(30, "0.0"), tag(11, "1.0"), tag(21, "0.0"), tag(31, "0.0"),
+		tag(12, "0.0"), tag(22, "1.0"), tag(32, "0.0"), tag(13, "1.0"), tag(23, "1.0"), tag(33, "0.0"), tag(39, "2.0"),
+	}))
+	b.AddEntity(entity.NewUnknown("ATTDEF", []format.Tag{
+		tag(100, "AcDbEntity"), tag(8, "0"), tag(100, "AcDbText"),
+		tag(10, "1.0"), tag(20, "0.0"), tag(30, "0.0"), tag(40, "1.0"), tag(1, "default"),
+		tag(100, "AcDbAttributeDefinition"), tag(3, "prompt"), tag(2, "TAG"), tag(70, "0"),
+	}))
+	values := func(u *entity.Unknown) map[int]string {
+		vs := make(map[int]string)
+		for _, tag := range u.Tags {
+			vs[tag.Code] = tag.Value
+		}
+		return vs
+	}
+
+	i, _ := d.Insert("BLK", 10.0, 0.0, 0.0)
+	i.Scale = []float64{-1.0, 1.0, 1.0}
+	es, err := d.Explode(i)
+	if err != nil || len(es) != 2 {
+		t.Fatalf("explode, expected 2 entities got %v %v", es, err)
+	}
+	solid := values(es[0].(*entity.Unknown))
+	if solid[11] != "-9" || solid[21] != "0" || solid[39] != "-2" || solid[210] != "0" || solid[230] != "-1" {
+		t.Errorf("solid, expected (-9, 0) in OCS of (0, 0, -1) with thickness -2 got %v", solid)
+	}
+	if tags := es[0].(*entity.Unknown).Tags; tags[len(tags)-1].Code != 230 {
+		t.Errorf("solid, expected extrusion direction after thickness got %v", tags)
+	}
+	if v := values(b.Entities[0].(*entity.Unknown)); v[11] != "1.0" {
+		t.Errorf("block entity was modified: %v", v)
+	}
+
+	i.Scale = []float64{1.0, 1.0, 1.0}
+	i.Rotation = 90.0
+	es, err = d.Explode(i)
+	if err != nil || len(es) != 2 {
+		t.Fatalf("explode rotated, expected 2 entities got %v %v", es, err)
+	}
+	attdef := es[1].(*entity.Unknown)
+	vs := values(attdef)
+	x, _ := strconv.ParseFloat(vs[10], 64)
+	y, _ := strconv.ParseFloat(vs[20], 64)
+	if !cmpF64(x, 10.0) || !cmpF64(y, 1.0) || vs[50] != "90" || vs[40] != "1" {
+		t.Errorf("attdef, expected (10, 1) rotated by 90 got %v", vs)
+	}
+	if attdef.Tags[7].Code != 50 {
+		t.Errorf("attdef, expected rotation after height got %v", attdef.Tags)
+	}
+
+	r, _ := d.AddBlock("REGION", "", 0.0, 0.0, 0.0)
+	r.AddEntity(entity.NewUnknown("REGION", []format.Tag{tag(100, "AcDbEntity"), tag(8, "0")}))
+	ri, _ := d.Insert("REGION", 0.0, 0.0, 0.0)
+	if _, err := d.Explode(ri); err == nil {
+		t.Errorf("explode region, expected error got nil")
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "LINE", "5", "20", "8", "0", "10", "0.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "1.0", "31", "0.0",
+		"0", "WIPEOUT", "5", "21", "100", "AcDbEntity", "8", "0", "100", "AcDbWipeout",
+		"10", "2.0", "20", "3.0", "30", "0.0", "11", "1.0", "21", "0.0", "31", "0.0", "340", "30", "1001", "ACME", "1000", "keep",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "OBJECTS",
+		"0", "DICTIONARY", "5", "C", "330", "0", "100", "AcDbDictionary", "3", "ACAD_GROUP", "350", "D", "3", "ACAD_IMAGE_DICT", "350", "31",
+		"0", "DICTIONARY", "5", "D", "330", "C", "100", "AcDbDictionary", "3", "*A1", "350", "40",
+		"0", "GROUP", "5", "40", "330", "D", "100", "AcDbGroup", "300", "", "70", "1", "71", "1",
+		"0", "DICTIONARY", "5", "31", "330", "C", "100", "AcDbDictionary", "3", "img", "350", "30",
+		"0", "IMAGEDEF", "5", "30", "330", "31", "100", "AcDbRasterImageDef", "1", "img.png",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	d, err := dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	if len(d.Entities()) != 2 {
+		t.Fatalf("entities, expected 2 got %d", len(d.Entities()))
+	}
+	u, ok := d.Entities()[1].(*entity.Unknown)
+	if !ok || u.Name != "WIPEOUT" {
+		t.Fatalf("unknown, expected WIPEOUT got %v", d.Entities()[1])
+	}
+	if mins, _ := u.BBox(); mins[0] != 1.0 || mins[1] != 0.0 {
+		t.Errorf("bbox, expected min (1, 0) got %v", mins)
+	}
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	out := buff.String()
+	if strings.Count(out, "AcDbGroup") != 1 {
+		t.Errorf("objects, expected a GROUP got %d", strings.Count(out, "AcDbGroup"))
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read again, expected nil got %v", err)
+	}
+	u = r.Entities()[1].(*entity.Unknown)
+	var ref format.Tag
+	for _, tag := range u.Tags {
+		if tag.Code == 340 {
+			ref = tag
+		}
+	}
+	if o, ok := ref.Ref.(*object.ImageDef); !ok || o.FileName != "img.png" {
+		t.Errorf("reference, expected IMAGEDEF got %v", ref.Ref)
+	}
+	if last := u.Tags[len(u.Tags)-1]; last.Code != 340 {
+		t.Errorf("tags, expected XDATA not to be kept in tags got %v", last)
+	}
+	if vs, err := u.GetXData("ACME"); err != nil || len(vs) != 1 || vs[0].Value != "keep" {
+		t.Errorf("xdata, expected 1000 keep got %v %v", vs, err)
+	}
+	item, err := r.RootDictionary().Item("ACAD_IMAGE_DICT")
+	if err != nil {
+		t.Fatalf("root dictionary, expected ACAD_IMAGE_DICT got %v", err)
+	}
+	if o, ok := item.(*object.Dictionary); !ok || o.Owner() != r.RootDictionary() {
+		t.Errorf("root dictionary, expected DICTIONARY owned by root got %v", item)
+	}
+}
+
+func TestInsertAttributes(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "INSERT", "5", "20", "100", "AcDbEntity", "8", "0", "100", "AcDbBlockReference", "66", "1", "2", "BLK",
+		"10", "1.0", "20", "2.0", "30", "0.0",
+		"0", "ATTRIB", "5", "21", "330", "20", "100", "AcDbEntity", "8", "0", "100", "AcDbText",
+		"10", "1.0", "20", "2.0", "30", "0.0", "40", "1.0", "1", "VALUE1", "100", "AcDbAttribute", "2", "TAG1", "70", "0",
+		"0", "ATTRIB", "5", "22", "330", "20", "100", "AcDbEntity", "8", "0", "100", "AcDbText",
+		"10", "1.0", "20", "1.0", "30", "0.0", "40", "1.0", "1", "VALUE2", "100", "AcDbAttribute", "2", "TAG2", "70", "0",
+		"0", "SEQEND", "5", "23", "330", "20", "100", "AcDbEntity", "8", "0",
+		"0", "LINE", "5", "24", "8", "0", "10", "0.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "1.0", "31", "0.0",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	d, err := dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	for n := 0; n < 2; n++ {
+		if len(d.Entities()) != 2 {
+			t.Fatalf("entities, expected 2 got %d", len(d.Entities()))
+		}
+		i, ok := d.Entities()[0].(*entity.Insert)
+		if !ok || len(i.Attributes) != 2 {
+			t.Fatalf("insert, expected 2 attributes got %v", d.Entities()[0])
+		}
+		for j, a := range i.Attributes {
+			value, owner := "", handle.Handler(nil)
+			for _, tag := range a.Tags {
+				switch tag.Code {
+				case 1:
+					value = tag.Value
+				case 330:
+					owner = tag.Ref
+				}
+			}
+			if value != fmt.Sprintf("VALUE%d", j+1) || owner != i {
+				t.Errorf("attribute %d, expected VALUE%d owned by INSERT got %q %v", j, j+1, value, owner)
+			}
+		}
+
+		var buff bytes.Buffer
+		if _, err := d.WriteTo(&buff); err != nil {
+			t.Fatalf("write, expected nil got %v", err)
+		}
+		out := buff.String()
+		if !strings.Contains(out, "AcDbBlockReference\n66\n1\n2\nBLK\n") || strings.Count(out, "\nATTRIB\n") != 2 ||
+			strings.Index(out, "\nSEQEND\n") < strings.LastIndex(out, "\nATTRIB\n") {
+			t.Errorf("write, expected INSERT with 66, 2 ATTRIBs and SEQEND got %s", out)
+		}
+		d, err = dxf.FromReader(&buff)
+		if err != nil {
+			t.Fatalf("read again, expected nil got %v", err)
+		}
+	}
+}
+
+func TestExplodeAttributes(t *testing.T) {
+	d := drawing.New()
+	b, _ := d.AddBlock("BLK", "", 0.0, 0.0, 0.0)
+	l := entity.NewLine()
+	l.End = []float64{1.0, 0.0, 0.0}
+	b.AddEntity(l)
+	attrib := func(value string, x, y float64, flag int) *entity.Unknown {
+		return entity.NewUnknown("ATTRIB", []format.Tag{
+			{Code: 100, Value: "AcDbEntity"}, {Code: 8, Value: "0"}, {Code: 100, Value: "AcDbText"},
+			{Code: 10, Value: fmt.Sprint(x)}, {Code: 20, Value: fmt.Sprint(y)}, {Code: 30, Value: "0.0"},
+			{Code: 40, Value: "2.0"}, {Code: 1, Value: value},
+			{Code: 100, Value: "AcDbAttribute"}, {Code: 2, Value: "TAG"}, {Code: 70, Value: fmt.Sprint(flag)},
+		})
+	}
+	// nested INSERT with an attribute in block coordinates
+	outer, _ := d.AddBlock("OUTER", "", 0.0, 0.0, 0.0)
+	nested := entity.NewInsert("BLK")
+	nested.AddAttribute(attrib("NESTED", 1.0, 0.0, 0))
+	outer.AddEntity(nested)
+
+	i, _ := d.Insert("BLK", 10.0, 0.0, 0.0)
+	i.AddAttribute(attrib("VISIBLE", 10.0, 1.0, 0))
+	i.AddAttribute(attrib("INVISIBLE", 10.0, 2.0, 1))
+	aligned := attrib("ALIGNED", 10.0, 3.0, 0)
+	aligned.Tags = append(aligned.Tags[:6:6], append([]format.Tag{
+		{Code: 11, Value: "12.0"}, {Code: 21, Value: "4.0"}, {Code: 31, Value: "0.0"}, {Code: 72, Value: "1"},
+	}, aligned.Tags[6:]...)...)
+	i.AddAttribute(aligned)
+	o, _ := d.Insert("OUTER", 0.0, 5.0, 0.0)
+	o.Rotation = 90.0
+
+	if err := d.ExplodeAll(); err != nil {
+		t.Fatalf("explode all, expected nil got %v", err)
+	}
+	es := d.Entities()
+	if len(es) != 5 {
+		t.Fatalf("number of entities, expected 5 got %v", es)
+	}
+	text, ok := es[1].(*entity.Text)
+	if !ok || text.Value != "VISIBLE" || !cmpF64(text.Coord1[0], 10.0) || !cmpF64(text.Coord1[1], 1.0) || text.Height != 2.0 {
+		t.Errorf("attribute, expected TEXT VISIBLE at (10, 1) got %v", es[1])
+	}
+	text, ok = es[2].(*entity.Text)
+	if !ok || text.Value != "ALIGNED" || !cmpF64(text.Coord2[0], 12.0) || !cmpF64(text.Coord2[1], 4.0) || text.HorizontalFlag != 1 {
+		t.Errorf("aligned attribute, expected TEXT ALIGNED centered at (12, 4) got %v", es[2])
+	}
+	text, ok = es[4].(*entity.Text)
+	if !ok || text.Value != "NESTED" || !cmpF64(text.Coord1[0], 0.0) || !cmpF64(text.Coord1[1], 6.0) || !cmpF64(text.Rotation, 90.0) {
+		t.Errorf("nested attribute, expected TEXT NESTED at (0, 6) rotated by 90 got %v", es[3])
+	}
+}
+
+func TestXData(t *testing.T) {
+	d := drawing.New()
+	layer, _ := d.AddLayer("Walls", color.Red, table.LT_CONTINUOUS, true)
+	l1, _ := d.Line(0.0, 0.0, 0.0, 1.0, 0.0, 0.0)
+	l2, _ := d.Line(0.0, 1.0, 0.0, 1.0, 1.0, 0.0)
+	err := l2.SetXData("ACME",
+		xdata.String("wall"),
+		xdata.Int16(3),
+		xdata.Point(1.0, 2.0, 3.0),
+		xdata.Real(0.5),
+		xdata.Handle(l1),
+		xdata.Binary([]byte{0xAB, 0x01}),
+	)
+	if err != nil {
+		t.Fatalf("set, expected nil got %v", err)
+	}
+	if err := l2.SetXData("ACME", xdata.Value{Code: 1070, Value: "x"}); err == nil {
+		t.Errorf("set, expected error for invalid value")
+	}
+	if err := layer.SetXData("LAYERAPP", xdata.Layer("0")); err != nil {
+		t.Fatalf("set layer, expected nil got %v", err)
+	}
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	tables := r.Sections[drawing.TABLES].(table.Tables)
+	for _, app := range []string{"ACAD", "ACME", "LAYERAPP"} {
+		if _, err := tables[table.APPID].Contains(app); err != nil {
+			t.Errorf("appid, expected %s got %v", app, err)
+		}
+	}
+
+	rl1 := r.Entities()[0]
+	vs, err := r.Entities()[1].(*entity.Line).GetXData("ACME")
+	if err != nil || len(vs) != 6 {
+		t.Fatalf("xdata, expected 6 values got %v %v", vs, err)
+	}
+	if vs[0].Value != "wall" || vs[1].Value != 3 || vs[3].Value != 0.5 {
+		t.Errorf("xdata, expected wall, 3, 0.5 got %v %v %v", vs[0], vs[1], vs[3])
+	}
+	if p := vs[2].Value.([]float64); vs[2].Code != 1010 || p[0] != 1.0 || p[1] != 2.0 || p[2] != 3.0 {
+		t.Errorf("xdata, expected point (1, 2, 3) got %v", vs[2])
+	}
+	if h, ok := vs[4].Value.(handle.Handler); !ok || h != rl1.(handle.Handler) {
+		t.Errorf("xdata, expected handle to the first line got %v", vs[4])
+	}
+	if b := vs[5].Value.([]byte); !bytes.Equal(b, []byte{0xAB, 0x01}) {
+		t.Errorf("xdata, expected binary AB01 got %X", b)
+	}
+	rlayer, err := r.Layer("Walls", false)
+	if err != nil {
+		t.Fatalf("layer, expected nil got %v", err)
+	}
+	if vs, err := rlayer.GetXData("LAYERAPP"); err != nil || len(vs) != 1 || vs[0].Code != 1003 {
+		t.Errorf("layer xdata, expected 1003 0 got %v %v", vs, err)
+	}
+	if _, err := rlayer.GetXData("ACME"); err == nil {
+		t.Errorf("layer xdata, expected error for ACME")
+	}
+}
+
+func TestHeaderVariables(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "HEADER",
+		"9", "$ACADVER", "1", "AC1015",
+		"9", "$LIMMAX", "10", "420.0", "20", "297.0",
+		"9", "$CLAYER", "8", "Walls",
+		"9", "$PDMODE", "70", "0",
+		"9", "$TDCREATE", "40", "2460000.5",
+		"9", "$ANGDIR", "70", "1",
+		"9", "$FINGERPRINTGUID", "2", "{1A2B}",
+		"9", "$HANDSEED", "5", "FF",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	d, err := dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	h := d.Sections[drawing.HEADER].(*header.Header)
+	if h.CLayer != "Walls" || h.TdCreate != 2460000.5 || h.FingerprintGUID != "{1A2B}" {
+		t.Errorf("fields, got %q %v %q", h.CLayer, h.TdCreate, h.FingerprintGUID)
+	}
+	vs, err := h.Get("$LIMMAX")
+	if err != nil || len(vs) != 2 || vs[0].Code != 10 || vs[0].Value != 420.0 || vs[1].Value != 297.0 {
+		t.Errorf("$LIMMAX, expected (420, 297) got %v %v", vs, err)
+	}
+	if vs, err := h.Get("$ANGDIR"); err != nil || vs[0].Value != 1 {
+		t.Errorf("$ANGDIR, expected 1 got %v %v", vs, err)
+	}
+	if _, err := h.Get("$NOTEXIST"); err == nil {
+		t.Errorf("$NOTEXIST, expected error")
+	}
+	if err := h.Set("$PDMODE", header.Value{Code: 70, Value: "x"}); err == nil {
+		t.Errorf("$PDMODE, expected error for invalid value")
+	}
+	h.Measurement = 1
+	if err := h.Set("$DIMASZ", header.Value{Code: 40, Value: 2.5}); err != nil {
+		t.Fatalf("set, expected nil got %v", err)
+	}
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	out := buff.String()
+	order := []string{"$LIMMAX", "$CLAYER", "$PDMODE", "$TDCREATE", "$ANGDIR", "$FINGERPRINTGUID", "$DIMASZ", "$MEASUREMENT", "$HANDSEED"}
+	last := -1
+	for _, name := range order {
+		i := strings.Index(out, "\n"+name+"\n")
+		if i < last {
+			t.Errorf("order, expected %s after previous variables at %d got %d", name, last, i)
+		}
+		last = i
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read again, expected nil got %v", err)
+	}
+	rh := r.Sections[drawing.HEADER].(*header.Header)
+	if rh.CLayer != "Walls" || rh.Measurement != 1 {
+		t.Errorf("fields, expected Walls and 1 got %q %d", rh.CLayer, rh.Measurement)
+	}
+	if vs, err := rh.Get("$PDMODE"); err != nil || vs[0].Value != 0 {
+		t.Errorf("$PDMODE, expected 0 to be kept got %v %v", vs, err)
+	}
+	if vs, err := rh.Get("$DIMASZ"); err != nil || vs[0].Value != 2.5 {
+		t.Errorf("$DIMASZ, expected 2.5 got %v %v", vs, err)
+	}
+}
+
+func TestClasses(t *testing.T) {
+	d, err := dxf.FromFile(filepath.Join("testdata", "point.dxf"))
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	cs := d.Classes()
+	if len(cs) == 0 {
+		t.Fatalf("classes, expected classes of the file got none")
+	}
+	c := cs[0]
+	if c.Name != "ACDBDICTIONARYWDFLT" || c.ClassName != "AcDbDictionaryWithDefault" || c.AppName != "ObjectDBX Classes" || c.IsEntity {
+		t.Errorf("class, got %+v", c)
+	}
+	custom := class.NewClass("ACME_WIDGET", "AcmeWidget", "AcmeApp", true)
+	custom.ProxyFlag = class.PROXY_ERASE_ALLOWED | class.PROXY_TRANSFORM_ALLOWED
+	custom.Count = 2
+	custom.WasProxy = true
+	if _, err := d.AddClass(custom); err != nil {
+		t.Fatalf("add, expected nil got %v", err)
+	}
+	if _, err := d.AddClass(class.NewClass("ACME_WIDGET", "", "", false)); err == nil {
+		t.Errorf("add, expected error for existing class")
+	}
+
+	// instance count is written for R2004 and later
+	d.SetVersion(format.R2018)
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read again, expected nil got %v", err)
+	}
+	if len(r.Classes()) != len(cs)+1 {
+		t.Fatalf("classes, expected %d got %d", len(cs)+1, len(r.Classes()))
+	}
+	rc, err := r.Classes().Contains("ACME_WIDGET")
+	if err != nil {
+		t.Fatalf("contains, expected nil got %v", err)
+	}
+	if *rc != *custom {
+		t.Errorf("class, expected %+v got %+v", custom, rc)
+	}
+}
+
+func TestObjects(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "LINE", "5", "20", "8", "0", "10", "0.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "0.0", "31", "0.0",
+		"0", "LINE", "5", "21", "8", "0", "10", "1.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "1.0", "31", "0.0",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "OBJECTS",
+		"0", "DICTIONARY", "5", "C", "330", "0", "100", "AcDbDictionary",
+		"3", "ACAD_GROUP", "350", "D", "3", "ACAD_LAYOUT", "350", "E", "3", "ACAD_PLOTSETTINGS", "350", "F", "3", "ACME", "350", "10",
+		"0", "DICTIONARY", "5", "D", "330", "C", "100", "AcDbDictionary", "3", "Walls", "350", "40",
+		"0", "GROUP", "5", "40", "330", "D", "100", "AcDbGroup", "300", "outer", "70", "0", "71", "1", "340", "20", "340", "21",
+		"0", "DICTIONARY", "5", "E", "330", "C", "100", "AcDbDictionary", "3", "Layout1", "350", "50",
+		"0", "LAYOUT", "5", "50", "330", "E", "100", "AcDbPlotSettings", "1", "", "4", "ISO_A3", "44", "420.0", "45", "297.0", "70", "688",
+		"100", "AcDbLayout", "1", "Layout1", "70", "1", "71", "1", "10", "0.0", "20", "0.0", "11", "420.0", "21", "297.0",
+		"0", "DICTIONARY", "5", "F", "330", "C", "100", "AcDbDictionary", "3", "Setup1", "350", "60",
+		"0", "PLOTSETTINGS", "5", "60", "330", "F", "100", "AcDbPlotSettings", "1", "Setup1", "2", "DWG To PDF.pc3", "73", "1",
+		"0", "DICTIONARY", "5", "10", "330", "C", "100", "AcDbDictionary", "280", "1", "281", "1", "3", "DATA", "360", "70",
+		"0", "XRECORD", "5", "70", "330", "10", "100", "AcDbXrecord", "280", "1", "1", "hello", "40", "2.5", "340", "21",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	d, err := dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	for i, dr := range []*drawing.Drawing{d, nil} {
+		if dr == nil {
+			if dr, err = dxf.FromReader(&buff); err != nil {
+				t.Fatalf("read again, expected nil got %v", err)
+			}
+		}
+		g, exist := dr.Groups["Walls"]
+		if !exist {
+			t.Fatalf("%d: group, expected Walls got %v", i, dr.Groups)
+		}
+		es := g.Entities()
+		if g.Description != "outer" || len(es) != 2 || es[0] != dr.Entities()[0] || es[1] != dr.Entities()[1] {
+			t.Errorf("%d: group, expected outer with 2 lines got %q %v", i, g.Description, es)
+		}
+
+		item, err := dr.RootDictionary().Item("ACAD_LAYOUT")
+		if err != nil {
+			t.Fatalf("%d: root dictionary, expected ACAD_LAYOUT got %v", i, err)
+		}
+		layouts := item.(*object.Dictionary)
+		item, _ = layouts.Item("Layout1")
+		l, ok := item.(*object.Layout)
+		if !ok {
+			t.Fatalf("%d: layout, expected LAYOUT got %v", i, item)
+		}
+		if l.Name != "Layout1" || l.TabOrder != 1 || l.PaperName != "ISO_A3" || l.PaperSize[0] != 420.0 || l.LimMax[1] != 297.0 || l.Owner() != layouts {
+			t.Errorf("%d: layout, got %+v", i, l)
+		}
+
+		item, _ = dr.RootDictionary().Item("ACAD_PLOTSETTINGS")
+		item, _ = item.(*object.Dictionary).Item("Setup1")
+		if p, ok := item.(*object.PlotSettings); !ok || p.PageSetupName != "Setup1" || p.PlotConfig != "DWG To PDF.pc3" || p.Rotation != 1 {
+			t.Errorf("%d: plot settings, got %v", i, item)
+		}
+
+		item, _ = dr.RootDictionary().Item("ACME")
+		acme := item.(*object.Dictionary)
+		if !acme.HardOwner {
+			t.Errorf("%d: dictionary, expected hard owner", i)
+		}
+		item, _ = acme.Item("DATA")
+		x, ok := item.(*object.XRecord)
+		if !ok || len(x.Tags) != 3 {
+			t.Fatalf("%d: xrecord, expected 3 tags got %v", i, item)
+		}
+		if x.Tags[0].Value != "hello" || x.Tags[1].Code != 40 || x.Tags[2].Ref != dr.Entities()[1] {
+			t.Errorf("%d: xrecord, got %v", i, x.Tags)
+		}
+	}
+}
+
+func TestEntityOwner(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "TABLES",
+		"0", "TABLE", "2", "BLOCK_RECORD", "5", "1", "70", "1",
+		"0", "BLOCK_RECORD", "5", "1F", "330", "1", "100", "AcDbSymbolTableRecord", "100", "AcDbBlockTableRecord", "2", "*Model_Space",
+		"0", "ENDTAB",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "LINE", "5", "2A", "330", "1F", "100", "AcDbEntity", "8", "0", "100", "AcDbLine",
+		"10", "0.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "0.0", "31", "0.0",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	d, err := dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	l := d.Entities()[0].(*entity.Line)
+	if r, ok := l.Owner().(*table.BlockRecord); !ok || r.Name() != "*Model_Space" {
+		t.Fatalf("owner, expected BLOCK_RECORD *Model_Space got %v", l.Owner())
+	}
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	if l.Owner().Handle() == 0x1F {
+		t.Fatalf("handle, expected to be reassigned got %X", l.Owner().Handle())
+	}
+	expected := fmt.Sprintf("0\nLINE\n5\n%X\n330\n%X\n100\nAcDbEntity\n", l.Handle(), l.Owner().Handle())
+	if out := buff.String(); !strings.Contains(out, expected) {
+		t.Errorf("write, expected %q got %s", expected, out)
+	}
+}
+
+func TestUnlistedGroups(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "LINE", "5", "2A", "8", "0", "10", "0.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "0.0", "31", "0.0",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "OBJECTS",
+		"0", "DICTIONARY", "5", "C", "330", "0", "100", "AcDbDictionary", "3", "ACAD_GROUP", "350", "D", "3", "ACME", "350", "E",
+		"0", "DICTIONARY", "5", "D", "330", "C", "100", "AcDbDictionary", "3", "G1", "350", "40",
+		"0", "DICTIONARY", "5", "E", "330", "C", "100", "AcDbDictionary", "3", "OWNED", "350", "41",
+		"0", "GROUP", "5", "40", "330", "D", "100", "AcDbGroup", "300", "listed", "70", "0", "71", "1", "340", "2A",
+		"0", "GROUP", "5", "41", "330", "E", "100", "AcDbGroup", "300", "owned", "70", "0", "71", "1", "340", "2A",
+		"0", "GROUP", "5", "42", "330", "D", "100", "AcDbGroup", "300", "unlisted", "70", "1", "71", "1", "340", "2A",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	d, err := dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	if g, exist := d.Groups["G1"]; !exist || g.Description != "listed" {
+		t.Errorf("listed group, expected G1 got %v", d.Groups)
+	}
+	if g, exist := d.Groups["*A1"]; !exist || g.Description != "unlisted" || len(g.Entities()) != 1 {
+		t.Errorf("unlisted group, expected *A1 got %v", d.Groups)
+	}
+	if _, exist := d.Groups["OWNED"]; exist || len(d.Groups) != 2 {
+		t.Errorf("owned group, expected not to be in ACAD_GROUP got %v", d.Groups)
+	}
+	acme, err := d.RootDictionary().Item("ACME")
+	if err != nil {
+		t.Fatalf("owner, expected ACME got %v", err)
+	}
+	owned, err := acme.(*object.Dictionary).Item("OWNED")
+	if g, ok := owned.(*object.Group); err != nil || !ok || g.Description != "owned" {
+		t.Errorf("owned group, expected in ACME got %T %v", owned, err)
+	}
+
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read again, expected nil got %v", err)
+	}
+	if len(r.Groups) != 2 || r.Groups["*A1"] == nil {
+		t.Errorf("read again, expected G1 and *A1 got %v", r.Groups)
+	}
+	acme, err = r.RootDictionary().Item("ACME")
+	if err != nil {
+		t.Fatalf("read again, expected ACME got %v", err)
+	}
+	if owned, err := acme.(*object.Dictionary).Item("OWNED"); err != nil || owned.(*object.Group).Description != "owned" {
+		t.Errorf("read again, expected owned group in ACME got %v %v", owned, err)
+	}
+}
+
+func TestPreserveHandles(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "HEADER", "9", "$HANDSEED", "5", "100", "0", "ENDSEC",
+		"0", "SECTION", "2", "BLOCKS",
+		"0", "BLOCK", "5", "50", "8", "0", "2", "B1", "70", "0", "10", "0.0", "20", "0.0", "30", "0.0",
+		"0", "ENDBLK", "5", "51", "8", "0",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "LINE", "5", "2A", "8", "0", "10", "0.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "0.0", "31", "0.0",
+		"0", "POLYLINE", "5", "2B", "8", "0", "66", "1", "10", "0.0", "20", "0.0", "30", "0.0", "70", "0",
+		"0", "VERTEX", "5", "2C", "8", "0", "10", "0.0", "20", "0.0", "30", "0.0",
+		"0", "VERTEX", "5", "2D", "8", "0", "10", "1.0", "20", "1.0", "30", "0.0",
+		"0", "SEQEND", "5", "2E", "8", "0",
+		"0", "LINE", "5", "30", "8", "0", "10", "0.0", "20", "1.0", "30", "0.0", "11", "1.0", "21", "1.0", "31", "0.0",
+		"1001", "ACME", "1005", "2A",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "OBJECTS",
+		"0", "DICTIONARY", "5", "C", "330", "0", "100", "AcDbDictionary", "3", "ACAD_GROUP", "350", "D",
+		"0", "DICTIONARY", "5", "D", "330", "C", "100", "AcDbDictionary", "3", "G1", "350", "40",
+		"0", "GROUP", "5", "40", "330", "D", "100", "AcDbGroup", "300", "", "70", "0", "71", "1", "340", "2A",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	d, err := dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	d.PreserveHandles = true
+	added, _ := d.Line(5.0, 5.0, 0.0, 6.0, 6.0, 0.0)
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	if added.Handle() < 0x100 {
+		t.Errorf("new handle, expected above $HANDSEED 100 got %X", added.Handle())
+	}
+	if !strings.Contains(buff.String(), "0\nSEQEND\n5\n2E\n") || !strings.Contains(buff.String(), "0\nENDBLK\n5\n51\n") {
+		t.Errorf("SEQEND and ENDBLK, expected handles 2E and 51 to be kept got %s", buff.String())
+	}
+	lines := strings.Split(buff.String(), "\n")
+	seen := make(map[string]bool)
+	body := false
+	for i := 0; i+1 < len(lines); i += 2 {
+		code, value := strings.TrimSpace(lines[i]), strings.TrimSpace(lines[i+1])
+		if code == "0" && value == "ENDSEC" {
+			body = true
+		}
+		if body && (code == "5" || code == "105") {
+			if seen[value] {
+				t.Errorf("handle, expected unique got %s twice", value)
+			}
+			seen[value] = true
+		}
+	}
+
+	r, err := dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read again, expected nil got %v", err)
+	}
+	r.PreserveHandles = true
+	for h, typ := range map[int]string{0x2A: "*entity.Line", 0x2B: "*entity.Polyline", 0x2D: "*entity.Vertex", 0x40: "*object.Group", 0xC: "*object.Dictionary"} {
+		e, err := r.ByHandle(h)
+		if err != nil || fmt.Sprintf("%T", e) != typ {
+			t.Errorf("by handle %X, expected %s got %T %v", h, typ, e, err)
+		}
+	}
+	if _, err := r.ByHandle(added.Handle()); err != nil {
+		t.Errorf("by handle %X, expected the added line got %v", added.Handle(), err)
+	}
+	if _, err := r.ByHandle(0xFFFF); err == nil {
+		t.Errorf("by handle FFFF, expected error")
+	}
+	unsaved, _ := r.Line(0.0, 0.0, 0.0, 1.0, 1.0, 0.0)
+	if _, err := r.ByHandle(0x2A); err != nil || unsaved.Handle() != 0 {
+		t.Errorf("by handle, expected no handle to be assigned got %X %v", unsaved.Handle(), err)
+	}
+
+	// handles assigned by WriteTo without PreserveHandles
+	n := dxf.NewDrawing()
+	line, _ := n.Line(0.0, 0.0, 0.0, 1.0, 1.0, 0.0)
+	pl, _ := n.Polyline(false, []float64{0.0, 0.0, 0.0}, []float64{1.0, 0.0, 0.0})
+	if _, err := n.WriteTo(io.Discard); err != nil {
+		t.Fatalf("write new, expected nil got %v", err)
+	}
+	for _, e := range []handle.Handler{line, pl, pl.Vertices[1]} {
+		if got, err := n.ByHandle(e.Handle()); err != nil || got != e {
+			t.Errorf("by handle %X after write, expected %T got %T %v", e.Handle(), e, got, err)
+		}
+	}
+	if es := r.Groups["G1"].Entities(); len(es) != 1 || es[0].Handle() != 0x2A {
+		t.Errorf("group, expected entity 2A got %v", es)
+	}
+	vs, err := r.Entities()[2].(*entity.Line).GetXData("ACME")
+	if err != nil || vs[0].Value.(handle.Handler).Handle() != 0x2A {
+		t.Errorf("xdata, expected handle 2A got %v %v", vs, err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	write := func(v format.Version) (string, *entity.Line) {
+		d := dxf.NewDrawing()
+		if err := d.SetVersion(v); err != nil {
+			t.Fatalf("set version %s, expected nil got %v", v, err)
+		}
+		l, _ := d.Line(0.0, 0.0, 0.0, 1.0, 1.0, 0.0)
+		l.SetColor(1)
+		l.SetTrueColor(color.RGB(255, 128, 0))
+		l.SetTransparency(color.Transparency(0.5))
+		d.LwPolyline(true, []float64{0.0, 0.0, 0.0}, []float64{1.0, 1.0, 0.0})
+		d.Text("héllo", 0.0, 0.0, 0.0, 1.0)
+		d.MText("a\\Pb", 1.0, 1.0, 0.0, 1.0, 0.0)
+		d.Ellipse(0.0, 0.0, 0.0, 2.0, 0.0, 0.5, 0.0, 2.0*math.Pi)
+		d.Leader([]float64{0.0, 0.0, 0.0}, []float64{1.0, 1.0, 0.0}, []float64{2.0, 1.0, 0.0})
+		var buff bytes.Buffer
+		if _, err := d.WriteTo(&buff); err != nil {
+			t.Fatalf("write %s, expected nil got %v", v, err)
+		}
+		return buff.String(), l
+	}
+	codes := func(s string) map[string][]string {
+		rtn := make(map[string][]string)
+		lines := strings.Split(s, "\n")
+		for i := 0; i+1 < len(lines); i += 2 {
+			code := strings.TrimSpace(lines[i])
+			rtn[code] = append(rtn[code], strings.TrimSpace(lines[i+1]))
+		}
+		return rtn
+	}
+
+	r12, _ := write(format.R12)
+	c := codes(r12)
+	if len(c["100"]) != 0 || len(c["5"]) != 0 || len(c["330"]) != 0 {
+		t.Errorf("R12, expected no subclass markers and handles got %d %d %d", len(c["100"]), len(c["5"]), len(c["330"]))
+	}
+	if len(c["420"]) != 0 || len(c["440"]) != 0 {
+		t.Errorf("R12, expected no true color and transparency")
+	}
+	if strings.Contains(r12, "OBJECTS") || strings.Contains(r12, "CLASSES") || strings.Contains(r12, "BLOCK_RECORD") {
+		t.Errorf("R12, expected no CLASSES, OBJECTS and BLOCK_RECORD")
+	}
+	if strings.Contains(r12, "LWPOLYLINE") || strings.Contains(r12, "MTEXT") || strings.Contains(r12, "ELLIPSE") || strings.Contains(r12, "LEADER") {
+		t.Errorf("R12, expected LWPOLYLINE, MTEXT, ELLIPSE and LEADER to be converted")
+	}
+	if !strings.Contains(r12, "h\xe9llo") {
+		t.Errorf("R12, expected text in ANSI_1252")
+	}
+	d, err := dxf.FromStringData(r12)
+	if err != nil {
+		t.Fatalf("R12 read, expected nil got %v", err)
+	}
+	if d.Header().Version != "AC1009" {
+		t.Errorf("R12 $ACADVER, expected AC1009 got %s", d.Header().Version)
+	}
+	types := make([]string, 0)
+	for _, e := range d.Entities() {
+		types = append(types, fmt.Sprintf("%T", e))
+	}
+	if fmt.Sprint(types) != "[*entity.Line *entity.Polyline *entity.Text *entity.Text *entity.Polyline *entity.Polyline]" {
+		t.Errorf("R12 entities, got %v", types)
+	}
+	if p := d.Entities()[4].(*entity.Polyline); len(p.Vertices) != 64 || p.Flag&entity.POLYLINE_CLOSED == 0 || math.Abs(p.Vertices[16].Coord[1]-1.0) > 1e-9 {
+		t.Errorf("R12 ellipse, expected 64 vertices and closed got %d %d", len(p.Vertices), p.Flag)
+	}
+	if p := d.Entities()[5].(*entity.Polyline); len(p.Vertices) != 3 || p.Flag&entity.POLYLINE_CLOSED != 0 {
+		t.Errorf("R12 leader, expected 3 vertices and open got %d %d", len(p.Vertices), p.Flag)
+	}
+	if p := d.Entities()[1].(*entity.Polyline); len(p.Vertices) != 2 || p.Flag&entity.POLYLINE_CLOSED == 0 {
+		t.Errorf("R12 polyline, expected 2 vertices and closed got %d %d", len(p.Vertices), p.Flag)
+	}
+	if l := d.Entities()[0].(*entity.Line); l.Color() != 1 || l.TrueColor() != -1 {
+		t.Errorf("R12 color, expected 1 and -1 got %d %d", l.Color(), l.TrueColor())
+	}
+
+	r2004, _ := write(format.R2004)
+	if c := codes(r2004); c["1"][0] != "AC1018" || len(c["420"]) != 0 || len(c["440"]) != 0 || len(c["100"]) == 0 {
+		t.Errorf("R2004, expected AC1018 without true color and transparency got %v", c["1"][0])
+	}
+	r2007, _ := write(format.R2007)
+	if c := codes(r2007); c["1"][0] != "AC1021" || len(c["420"]) != 1 || len(c["440"]) != 1 {
+		t.Errorf("R2007, expected AC1021 with true color and transparency got %v", c["1"][0])
+	}
+	if !strings.Contains(r2004, "h\xe9llo") {
+		t.Errorf("R2004, expected text in ANSI_1252")
+	}
+	r2018, l := write(format.R2018)
+	if !strings.Contains(r2018, "AC1032") || !strings.Contains(r2018, "héllo") || !strings.Contains(r2018, "LWPOLYLINE") {
+		t.Errorf("R2018, expected AC1032 in UTF-8 with LWPOLYLINE")
+	}
+	d, err = dxf.FromStringData(r2018)
+	if err != nil {
+		t.Fatalf("R2018 read, expected nil got %v", err)
+	}
+	read := d.Entities()[0].(*entity.Line)
+	if read.TrueColor() != l.TrueColor() || read.Transparency() != l.Transparency() || read.Color() != 1 {
+		t.Errorf("R2018 color, expected %X %X got %X %X", l.TrueColor(), l.Transparency(), read.TrueColor(), read.Transparency())
+	}
+	if v, err := d.Version(); err != nil || v != format.R2018 {
+		t.Errorf("R2018 version, expected R2018 got %v %v", v, err)
+	}
+}
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestVersionGolden(t *testing.T) {
+	for _, c := range []struct {
+		version format.Version
+		golden  string
+	}{
+		{format.R12, "testdata/version_r12.dxf"},
+		{format.R2018, "testdata/version_r2018.dxf"},
+	} {
+		d := dxf.NewDrawing()
+		if err := d.SetVersion(c.version); err != nil {
+			t.Fatalf("set version %s, expected nil got %v", c.version, err)
+		}
+		d.AddLayer("WALL", 1, dxf.DefaultLineType, true)
+		l, _ := d.Line(0.0, 0.0, 0.0, 1.0, 1.0, 0.0)
+		l.SetTrueColor(color.RGB(255, 128, 0))
+		l.SetTransparency(color.Transparency(0.5))
+		d.Circle(0.0, 0.0, 0.0, 1.0)
+		d.Arc(0.0, 0.0, 0.0, 2.0, 0.0, 90.0)
+		d.Text("text", 0.0, 0.0, 0.0, 1.0)
+		d.MText("a\\Pb", 1.0, 1.0, 0.0, 1.0, 0.0)
+		d.LwPolyline(true, []float64{0.0, 0.0, 0.0}, []float64{1.0, 0.0, 0.0}, []float64{1.0, 1.0, 0.0})
+		d.Polyline(false, []float64{0.0, 0.0, 0.0}, []float64{1.0, 1.0, 1.0})
+		d.Ellipse(0.0, 0.0, 0.0, 2.0, 0.0, 0.5, 0.0, 2.0*math.Pi)
+		d.Hatch([]float64{0.0, 0.0}, []float64{1.0, 0.0}, []float64{1.0, 1.0})
+		var buff bytes.Buffer
+		if _, err := d.WriteTo(&buff); err != nil {
+			t.Fatalf("write %s, expected nil got %v", c.version, err)
+		}
+		if *update {
+			if err := os.WriteFile(c.golden, buff.Bytes(), 0644); err != nil {
+				t.Fatalf("update %s, expected nil got %v", c.golden, err)
+			}
+		}
+		expected, err := os.ReadFile(c.golden)
+		if err != nil {
+			t.Fatalf("read %s, expected nil got %v", c.golden, err)
+		}
+		if !bytes.Equal(buff.Bytes(), expected) {
+			t.Errorf("%s, expected the same output as %s got\n%s", c.version, c.golden, buff.String())
+		}
+	}
+}
+
+func TestVersionFromHeader(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "HEADER", "9", "$ACADVER", "1", "AC1009", "0", "ENDSEC",
+		"0", "SECTION", "2", "TABLES",
+		"0", "TABLE", "2", "LAYER", "70", "1",
+		"0", "LAYER", "2", "WALL", "70", "0", "62", "1", "6", "CONTINUOUS",
+		"0", "ENDTAB",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "LINE", "8", "WALL", "10", "0.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "1.0", "31", "0.0",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	d, err := dxf.FromStringData(src)
+	if err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write, expected nil got %v", err)
+	}
+	out := buff.String()
+	if !strings.Contains(out, "AC1009") {
+		t.Errorf("$ACADVER, expected AC1009")
+	}
+	if strings.Contains(out, "AcDbEntity") || strings.Contains(out, "CLASSES") || strings.Contains(out, "\n330\n") {
+		t.Errorf("R12, expected no subclass markers, CLASSES and owners got\n%s", out)
+	}
+	if _, err := dxf.FromStringData(out); err != nil {
+		t.Errorf("read again, expected nil got %v", err)
+	}
+}
+
+func TestVersionDowngrade(t *testing.T) {
+	d := drawing.New()
+	h, _ := d.Hatch([]float64{0.0, 0.0}, []float64{10.0, 0.0}, []float64{10.0, 10.0}, []float64{0.0, 10.0})
+	h.AddEdgePath(&entity.ArcEdge{Center: []float64{5.0, 5.0}, Radius: 2.0, StartAngle: 0.0, EndAngle: 360.0, CounterClockwise: true})
+	d.MLeader("label", 20.0, 10.0, 0.0, 2.5, []float64{10.0, 0.0, 0.0}, []float64{15.0, 5.0, 0.0}, []float64{18.0, 10.0, 0.0})
+	for _, c := range []struct {
+		version format.Version
+		types   string
+	}{
+		// leader lines and the dogleg
+		{format.R12, "*entity.Polyline *entity.Polyline *entity.Line *entity.Line *entity.Line *entity.Text"},
+		{format.R13, "*entity.Polyline *entity.Polyline *entity.Line *entity.Line *entity.Line *entity.MText"},
+		{format.R2004, "*entity.Hatch *entity.Line *entity.Line *entity.Line *entity.MText"},
+		{format.R2007, "*entity.Hatch *entity.MLeader"},
+	} {
+		d.SetVersion(c.version)
+		var buff bytes.Buffer
+		if _, err := d.WriteTo(&buff); err != nil {
+			t.Fatalf("%s write, expected nil got %v", c.version, err)
+		}
+		r, err := dxf.FromReader(&buff)
+		if err != nil {
+			t.Fatalf("%s read, expected nil got %v", c.version, err)
+		}
+		types := make([]string, 0)
+		for _, e := range r.Entities() {
+			types = append(types, fmt.Sprintf("%T", e))
+		}
+		if got := strings.Join(types, " "); got != c.types {
+			t.Errorf("%s entities, expected %s got %s", c.version, c.types, got)
+			continue
+		}
+		if c.version >= format.R14 {
+			continue
+		}
+		outer := r.Entities()[0].(*entity.Polyline)
+		if len(outer.Vertices) != 4 || outer.Flag&entity.POLYLINE_CLOSED == 0 || outer.Is3D() {
+			t.Errorf("%s outer boundary, expected closed 2D polyline of 4 vertices got %d %d", c.version, len(outer.Vertices), outer.Flag)
+		}
+		hole := r.Entities()[1].(*entity.Polyline)
+		for _, v := range hole.Vertices {
+			if !cmpF64(math.Hypot(v.Coord[0]-5.0, v.Coord[1]-5.0), 2.0) {
+				t.Errorf("%s hole, expected vertex on circle got %v", c.version, v.Coord)
+				break
+			}
+		}
+		l := r.Entities()[2].(*entity.Line)
+		if l.Start[0] != 10.0 || l.End[0] != 15.0 {
+			t.Errorf("%s leader line, expected 10-15 got %v-%v", c.version, l.Start, l.End)
+		}
+	}
+	if len(d.Entities()) != 2 {
+		t.Errorf("entities of drawing, expected not to be modified got %d", len(d.Entities()))
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	file := func(version, codepage, layer, text string) string {
+		header := []string{"0", "SECTION", "2", "HEADER", "9", "$ACADVER", "1", version}
+		if codepage != "" {
+			header = append(header, "9", "$DWGCODEPAGE", "3", codepage)
+		}
+		return strings.Join(append(header,
+			"0", "ENDSEC",
+			"0", "SECTION", "2", "TABLES",
+			"0", "TABLE", "2", "LAYER", "70", "1",
+			"0", "LAYER", "2", layer, "70", "0", "62", "7", "6", "CONTINUOUS",
+			"0", "ENDTAB",
+			"0", "ENDSEC",
+			"0", "SECTION", "2", "ENTITIES",
+			"0", "TEXT", "8", layer, "10", "0.0", "20", "0.0", "30", "0.0", "40", "1.0", "1", text,
+			"0", "ENDSEC",
+			"0", "EOF",
+		), "\n")
+	}
+	gbk := "\xcd\xbc\xb2\xe3" // 图层
+	for _, c := range []struct {
+		name, src, layer, text string
+	}{
+		{"ANSI_936", file("AC1015", "ANSI_936", gbk, gbk+"1"), "图层", "图层1"},
+		{"ANSI_1252", file("AC1015", "ANSI_1252", "caf\xe9", "na\xefve"), "café", "naïve"},
+		{"default code page", file("AC1009", "", "caf\xe9", "x"), "café", "x"},
+		{"UTF-8 before R2007", file("AC1015", "ANSI_1252", "图层", "café"), "图层", "café"},
+		{"R2007", file("AC1021", "ANSI_936", "图层", "café"), "图层", "café"},
+		{"escapes", file("AC1015", "ANSI_1252", "\\U+56FE\\U+5C42", "\\M+5CDBC\\M+5B2E3 \\U+00B0"), "图层", "图层 °"},
+	} {
+		d, err := dxf.FromStringData(c.src)
+		if err != nil {
+			t.Errorf("%s: expected nil got %v", c.name, err)
+			continue
+		}
+		if _, exist := d.Layers[c.layer]; !exist {
+			t.Errorf("%s: expected layer %q got %v", c.name, c.layer, d.Layers)
+		}
+		text := d.Entities()[0].(*entity.Text)
+		if text.Value != c.text || text.Layer().Name() != c.layer {
+			t.Errorf("%s: expected %q on %q got %q on %q", c.name, c.text, c.layer, text.Value, text.Layer().Name())
+		}
+	}
+
+	// escaped backslashes and values of non-text codes are kept
+	r := dxf.NewTagReader(strings.NewReader("100\nAc\\U+0044b\n1\n\\\\U+0041\\U+0042\n"))
+	for _, c := range []struct {
+		code  int
+		value string
+	}{
+		{100, "Ac\\U+0044b"},
+		{1, "\\\\U+0041B"},
+	} {
+		tag, err := r.Next()
+		if err != nil || tag.Code != c.code || tag.Value != c.value {
+			t.Errorf("escapes of %d, expected %q got %q (%v)", c.code, c.value, tag.Value, err)
+		}
+	}
+
+	// $DWGCODEPAGE is wrong
+	r = dxf.NewTagReader(strings.NewReader(file("AC1015", "ANSI_1252", gbk, gbk)))
+	if err := r.SetCodePage("ANSI_936"); err != nil {
+		t.Fatalf("set code page, expected nil got %v", err)
+	}
+	d := dxf.NewDrawing()
+	if err := dxf.ReadDrawing(d, r); err != nil {
+		t.Fatalf("read, expected nil got %v", err)
+	}
+	if _, exist := d.Layers["图层"]; !exist {
+		t.Errorf("set code page, expected layer 图层 got %v", d.Layers)
+	}
+	if err := r.SetCodePage("EBCDIC"); err == nil {
+		t.Errorf("unknown code page, expected error")
+	}
+
+	// strings are written back in the code page, or escaped if the code page doesn't have them
+	d, err := dxf.FromStringData(file("AC1015", "ANSI_936", gbk, "\xc3\xba\xbf\xf3"))
+	if err != nil {
+		t.Fatalf("read ANSI_936, expected nil got %v", err)
+	}
+	d.Entities()[0].(*entity.Text).Value += "ก"
+	var buff bytes.Buffer
+	if _, err := d.WriteTo(&buff); err != nil {
+		t.Fatalf("write ANSI_936, expected nil got %v", err)
+	}
+	out := buff.Bytes()
+	if !bytes.Contains(out, []byte("\n\xc3\xba\xbf\xf3\\U+0E01\n")) || !bytes.Contains(out, []byte("\n"+gbk+"\n")) ||
+		bytes.Contains(out, []byte("煤矿")) || !bytes.Contains(out, []byte("ANSI_936")) {
+		t.Errorf("write ANSI_936, expected text and layer in GBK got %q", out)
+	}
+	d, err = dxf.FromReader(&buff)
+	if err != nil {
+		t.Fatalf("read ANSI_936 again, expected nil got %v", err)
+	}
+	if text := d.Entities()[0].(*entity.Text); text.Value != "煤矿ก" || text.Layer().Name() != "图层" {
+		t.Errorf("read ANSI_936 again, expected 煤矿ก on 图层 got %q on %q", text.Value, text.Layer().Name())
+	}
+
+	if s := mtext.PlainText("\\U+00E9t\\U+00E9\\P\\Ux"); s != "été\n\\Ux" {
+		t.Errorf("mtext, expected %q got %q", "été\n\\Ux", s)
+	}
+}
+
+func TestLenient(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "HEADER",
+		"9", "$LTSCALE", "40", "x",
+		"9", "$PDSIZE", "40", "2.0",
+		"0", "ENDSEC",
+		"0", "SECTION", "3", "BROKEN",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "TABLES",
+		"0", "TABLE", "2", "LAYER", "70", "2",
+		"0", "LAYER", "5", "10", "2", "BAD", "70", "x",
+		"0", "LAYER", "5", "11", "2", "GOOD", "70", "0", "62", "1", "6", "CONTINUOUS",
+		"0", "ENDTAB",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "LINE", "5", "2A", "8", "GOOD", "10", "bad", "20", "0.0", "30", "0.0", "11", "1.0", "21", "0.0", "31", "0.0",
+		"0", "POLYLINE", "5", "2B", "8", "0", "66", "1", "10", "0.0", "20", "0.0", "30", "0.0", "70", "8",
+		"0", "VERTEX", "5", "2C", "8", "0", "10", "0.0", "20", "0.0", "30", "0.0", "70", "32",
+		"0", "VERTEX", "5", "2D", "8", "0", "10", "1.0", "20", "bad", "30", "0.0", "70", "32",
+		"0", "VERTEX", "5", "2E", "8", "0", "10", "2.0", "20", "2.0", "30", "0.0", "70", "32",
+		"0", "SEQEND", "5", "2F", "8", "0",
+		"0", "LINE", "5", "30", "8", "GOOD", "10", "0.0", "20", "0.0", "30", "0.0", "11", "1.0", "21", "1.0", "31", "0.0",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+
+	if _, err := dxf.FromStringData(src); err == nil {
+		t.Errorf("strict, expected error")
+	}
+	d, warnings, err := dxf.FromReaderWithOptions(strings.NewReader(src), dxf.ParseOptions{Mode: dxf.Lenient})
+	if err != nil {
+		t.Fatalf("lenient, expected nil got %v", err)
+	}
+	expected := []dxf.Warning{
+		{Section: "HEADER", Type: "$LTSCALE", Line: 7},
+		{Section: "", Line: 17},
+		{Section: "TABLES", Type: "LAYER", Handle: 0x10, Line: 31},
+		{Section: "ENTITIES", Type: "LINE", Handle: 0x2A, Line: 65},
+		{Section: "ENTITIES", Type: "VERTEX", Handle: 0x2D, Line: 115},
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("warnings, expected %d got %d: %v", len(expected), len(warnings), warnings)
+	}
+	for i, w := range warnings {
+		e := expected[i]
+		if w.Section != e.Section || w.Type != e.Type || w.Handle != e.Handle || w.Line != e.Line || w.Message == "" {
+			t.Errorf("warning %d, expected %v got %v", i, e, w)
+		}
+	}
+	if d.Header().LtScale != 1.0 || d.Header().PdSize != 2.0 {
+		t.Errorf("header, expected $LTSCALE skipped and $PDSIZE read got %f %f", d.Header().LtScale, d.Header().PdSize)
+	}
+	if _, exist := d.Layers["BAD"]; exist {
+		t.Errorf("layer, expected BAD to be skipped")
+	}
+	if _, exist := d.Layers["GOOD"]; !exist {
+		t.Errorf("layer, expected GOOD to be read")
+	}
+	es := d.Entities()
+	if len(es) != 2 {
+		t.Fatalf("entities, expected 2 got %d", len(es))
+	}
+	if p, ok := es[0].(*entity.Polyline); !ok || len(p.Vertices) != 2 {
+		t.Errorf("polyline, expected 2 vertices got %v", es[0])
+	}
+	if l, ok := es[1].(*entity.Line); !ok || l.Layer().Name() != "GOOD" {
+		t.Errorf("line, expected the second line got %v", es[1])
+	}
+	if s := warnings[3].String(); s != "line 65: ENTITIES LINE (handle 2A): "+warnings[3].Message {
+		t.Errorf("warning string, got %q", s)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	src := strings.Join([]string{
+		"0", "SECTION", "2", "THUMBNAILIMAGE", "90", "2", "310", "ABCD",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "LINE", "5", "2A", "8", "0", "10", "0.0", "20", "1.0x", "30", "0.0",
+		"0", "ENDSEC",
+		"0", "EOF",
+	}, "\n")
+	_, err := dxf.FromStringData(src)
+	var pe *dxf.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *ParseError got %T %v", err, err)
+	}
+	expected := dxf.ParseError{Section: "ENTITIES", Type: "LINE", Handle: 0x2A, Code: 20, Value: "1.0x", Line: 23}
+	if pe.Section != expected.Section || pe.Type != expected.Type || pe.Handle != expected.Handle ||
+		pe.Code != expected.Code || pe.Value != expected.Value || pe.Line != expected.Line {
+		t.Errorf("expected %+v got %+v", expected, *pe)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected cause strconv.ErrSyntax got %v", pe.Err)
+	}
+	if s := err.Error(); s != `line 23: ENTITIES LINE (handle 2A): code 20: strconv.ParseFloat: parsing "1.0x": invalid syntax` {
+		t.Errorf("error string, got %q", s)
+	}
+
+	for _, bad := range []struct {
+		tags  []string
+		code  int
+		value string
+	}{
+		{[]string{"62", "red"}, 62, "red"},
+		{[]string{"1001", "ACME", "1004", "XYZ"}, 1004, "XYZ"},
+		{[]string{"1001", "ACME", "1005", "G1"}, 1005, "G1"},
+	} {
+		src := strings.Join(append(append([]string{
+			"0", "SECTION", "2", "ENTITIES",
+			"0", "LINE", "5", "2A", "8", "0", "10", "0.0", "20", "0.0", "30", "0.0",
+		}, bad.tags...), "0", "ENDSEC", "0", "EOF"), "\n")
+		_, err = dxf.FromStringData(src)
+		if !errors.As(err, &pe) || pe.Code != bad.code || pe.Value != bad.value || pe.Type != "LINE" {
+			t.Errorf("code %d, expected ParseError of %q got %v", bad.code, bad.value, err)
+		}
+	}
+
+	_, err = dxf.FromStringData("0\nSECTION\n2\nENTITIES\nLINE\n0\n")
+	if !errors.As(err, &pe) || !errors.Is(err, dxf.ErrGroupCode) || pe.Line != 5 || pe.Code != -1 || pe.Value != "LINE" || pe.Section != "ENTITIES" {
+		t.Errorf("invalid group code, got %v", err)
+	}
+	_, err = dxf.FromStringData("0\nSECTION\n2\nENTITIES\n0\n")
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !errors.As(err, &pe) || pe.Line != 5 {
+		t.Errorf("unexpected EOF, got %v", err)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	wcs := func(dir, p []float64) []float64 {
+		return geometry.OCS(dir).Apply(p)
+	}
+	cmpPoint := func(p, q []float64) bool {
+		for i := 0; i < 3; i++ {
+			if !cmpF64(p[i], q[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	mirror := geometry.Mirroring([]float64{1.0, 0.0, 0.0})
+
+	// mirrored text keeps reading direction in flipped OCS
+	tx := entity.NewText()
+	tx.Coord1 = []float64{2.0, 1.0, 0.0}
+	tx.Height = 1.0
+	tx.Transform(mirror)
+	if !cmpPoint(tx.Direction, []float64{0.0, 0.0, -1.0}) || !cmpPoint(wcs(tx.Direction, tx.Coord1), []float64{-2.0, 1.0, 0.0}) {
+		t.Errorf("mirrored text, expected direction (0,0,-1) at (-2,1,0) got %v %v", tx.Direction, wcs(tx.Direction, tx.Coord1))
+	}
+	if !cmpF64(tx.Rotation, 0.0) || !cmpF64(tx.Height, 1.0) || !cmpF64(tx.ObliqueAngle, 0.0) {
+		t.Errorf("mirrored text, expected rotation 0 height 1 oblique 0 got %v %v %v", tx.Rotation, tx.Height, tx.ObliqueAngle)
+	}
+	if !strings.Contains(tx.String(), "\n210\n0.000000\n220\n0.000000\n230\n-1.000000\n") {
+		t.Errorf("mirrored text, expected extrusion direction in output got %s", tx.String())
+	}
+
+	tx = entity.NewText()
+	tx.Height = 1.0
+	tx.WidthFactor = 1.0
+	tx.Rotation = 90.0
+	tx.Transform(geometry.Scaling(2.0, 1.0, 1.0))
+	if !cmpF64(tx.Rotation, 90.0) || !cmpF64(tx.Height, 2.0) || !cmpF64(tx.WidthFactor, 0.5) || !cmpF64(tx.ObliqueAngle, 0.0) {
+		t.Errorf("scaled text, expected rotation 90 height 2 width 0.5 oblique 0 got %v %v %v %v", tx.Rotation, tx.Height, tx.WidthFactor, tx.ObliqueAngle)
+	}
+	tx = entity.NewText()
+	tx.Height = 1.0
+	tx.Transform(geometry.Matrix{{1.0, 1.0, 0.0, 0.0}, {0.0, 1.0, 0.0, 0.0}, {0.0, 0.0, 1.0, 0.0}, {0.0, 0.0, 0.0, 1.0}})
+	if !cmpF64(tx.Height, 1.0) || !cmpF64(tx.ObliqueAngle, 45.0) {
+		t.Errorf("sheared text, expected height 1 oblique 45 got %v %v", tx.Height, tx.ObliqueAngle)
+	}
+
+	// rotated mtext scaled non-uniformly keeps the height between lines as text does
+	mt := entity.NewMText()
+	mt.Height = 1.0
+	mt.Width = 10.0
+	mt.SetRotation(30.0)
+	mt.Transform(geometry.Scaling(2.0, 1.0, 1.0))
+	tx = entity.NewText()
+	tx.Height = 1.0
+	tx.WidthFactor = 1.0
+	tx.Rotation = 30.0
+	tx.Transform(geometry.Scaling(2.0, 1.0, 1.0))
+	xscale := math.Hypot(math.Sqrt(3.0), 0.5)
+	rotation := math.Atan2(0.5, math.Sqrt(3.0)) * 180.0 / math.Pi
+	if !cmpF64(mt.Height, 2.0/xscale) || !cmpF64(mt.Height, tx.Height) || !cmpF64(mt.Width, 10.0*xscale) || !cmpF64(mt.Rotation(), rotation) {
+		t.Errorf("scaled mtext, expected height %v width %v rotation %v got %v %v %v", 2.0/xscale, 10.0*xscale, rotation, mt.Height, mt.Width, mt.Rotation())
+	}
+
+	// mirrored arc covers the mirrored quarter
+	a := entity.NewArc(nil)
+	a.Radius = 1.0
+	a.Angle = []float64{0.0, 90.0}
+	a.Transform(mirror)
+	for i, expected := range [][]float64{{-1.0, 0.0, 0.0}, {0.0, 1.0, 0.0}} {
+		s, c := math.Sincos(a.Angle[i] * math.Pi / 180.0)
+		if p := wcs(a.Direction, []float64{a.Center[0] + c*a.Radius, a.Center[1] + s*a.Radius, a.Center[2]}); !cmpPoint(p, expected) {
+			t.Errorf("mirrored arc, expected end point %v got %v", expected, p)
+		}
+	}
+
+	// circles remain circles only under uniform scale in their plane
+	c := entity.NewCircle()
+	c.Radius = 1.0
+	if n, err := entity.TransformEntity(c, geometry.Scaling(2.0, 2.0, 1.0)); n != c || err != nil || !cmpF64(c.Radius, 2.0) {
+		t.Errorf("uniformly scaled circle, expected the circle of radius 2 got %v %v", n, err)
+	}
+	n, err := entity.TransformEntity(c, geometry.Scaling(1.5, 0.5, 1.0))
+	e, ok := n.(*entity.Ellipse)
+	if !ok || err != nil {
+		t.Fatalf("scaled circle, expected *entity.Ellipse got %T %v", n, err)
+	}
+	if !cmpF64(geometry.Length(e.MajorAxis), 3.0) || !cmpF64(e.Ratio, 1.0/3.0) || !e.IsFull() {
+		t.Errorf("scaled circle, expected full ellipse of major 3 ratio 1/3 got %v %v %v-%v", e.MajorAxis, e.Ratio, e.Start, e.End)
+	}
+
+	// LWPOLYLINE vertices stay in OCS with elevation
+	l := entity.NewLwPolyline(2)
+	l.Vertices = [][]float64{{1.0, 0.0}, {2.0, 0.0}}
+	l.Bulges[0] = 1.0
+	l.Elevation = 3.0
+	l.Transform(mirror.Multiply(geometry.Mirroring([]float64{0.0, 0.0, 1.0})))
+	for i, expected := range [][]float64{{-1.0, 0.0, -3.0}, {-2.0, 0.0, -3.0}} {
+		if p := wcs(l.Direction, []float64{l.Vertices[i][0], l.Vertices[i][1], l.Elevation}); !cmpPoint(p, expected) {
+			t.Errorf("mirrored lwpolyline, expected vertex %v got %v", expected, p)
+		}
+	}
+	if l.Bulges[0] != 1.0 {
+		t.Errorf("mirrored lwpolyline, expected bulge 1 got %v", l.Bulges[0])
+	}
+
+	// widths are scaled across each segment
+	l = entity.NewLwPolyline(3)
+	l.Vertices = [][]float64{{0.0, 0.0}, {1.0, 0.0}, {1.0, 1.0}}
+	l.ConstantWidth = 0.5
+	if _, err := entity.TransformEntity(l, geometry.Scaling(2.0, 3.0, 1.0)); err != nil {
+		t.Fatalf("scaled lwpolyline, expected nil got %v", err)
+	}
+	if sw, ew := l.Width(0); !cmpF64(sw, 1.5) || !cmpF64(ew, 1.5) || l.ConstantWidth != 0.0 {
+		t.Errorf("scaled lwpolyline, expected width 1.5 of horizontal segment got %v %v %v", sw, ew, l.ConstantWidth)
+	}
+	if sw, ew := l.Width(1); !cmpF64(sw, 1.0) || !cmpF64(ew, 1.0) {
+		t.Errorf("scaled lwpolyline, expected width 1 of vertical segment got %v %v", sw, ew)
+	}
+
+	// arc segments scaled non-uniformly are tessellated
+	l = entity.NewLwPolyline(2)
+	l.Vertices = [][]float64{{-1.0, 0.0}, {1.0, 0.0}}
+	l.Bulges[0] = 1.0
+	if _, err := entity.TransformEntity(l, geometry.Scaling(2.0, 1.0, 1.0)); err != nil {
+		t.Fatalf("scaled arc segment, expected nil got %v", err)
+	}
+	if len(l.Vertices) < 10 || l.Num != len(l.Vertices) {
+		t.Fatalf("scaled arc segment, expected tessellated vertices got %v", l.Vertices)
+	}
+	for j, v := range l.Vertices {
+		if l.Bulge(j) != 0.0 || !cmpF64(v[0]*v[0]/4.0+v[1]*v[1], 1.0) || v[1] > 1e-9 {
+			t.Errorf("scaled arc segment, expected straight segments on the lower half of the ellipse got %v %v", v, l.Bulge(j))
+		}
+	}
+
+	// INSERT keeps its block unsheared
+	ins := entity.NewInsert("B")
+	ins.Rotation = 90.0
+	before := ins.Matrix([]float64{0.0, 0.0, 0.0}, 0, 0)
+	scaling := geometry.Scaling(2.0, 1.0, 1.0)
+	if _, err := entity.TransformEntity(ins, scaling); err != nil {
+		t.Fatalf("scaled insert, expected nil got %v", err)
+	}
+	after := ins.Matrix([]float64{0.0, 0.0, 0.0}, 0, 0)
+	for _, p := range [][]float64{{1.0, 0.0, 0.0}, {0.0, 1.0, 0.0}, {1.0, 2.0, 3.0}} {
+		if !cmpPoint(after.Apply(p), scaling.Multiply(before).Apply(p)) {
+			t.Errorf("scaled insert, expected %v at %v got %v", scaling.Multiply(before).Apply(p), p, after.Apply(p))
+		}
+	}
+	ins.Rotation = 45.0
+	if _, err := entity.TransformEntity(ins, scaling); err == nil || ins.Rotation != 45.0 {
+		t.Errorf("sheared insert, expected error and no change got %v %v", err, ins.Rotation)
+	}
+
+	d := drawing.New()
+	dc, _ := d.Circle(0.0, 0.0, 0.0, 1.0)
+	dt, _ := d.Text("text", 1.0, 1.0, 0.0, 1.0)
+	g, _ := d.Group("G", "", dc)
+	if err := d.Translate(1.0, 2.0, 3.0); err != nil {
+		t.Fatalf("translate, expected nil got %v", err)
+	}
+	if !cmpPoint(dc.Center, []float64{1.0, 2.0, 3.0}) || !cmpPoint(dt.Coord1, []float64{2.0, 3.0, 3.0}) {
+		t.Errorf("translate, expected (1,2,3) and (2,3,3) got %v %v", dc.Center, dt.Coord1)
+	}
+	if err := d.Transform(geometry.Scaling(2.0, 1.0, 1.0)); err != nil {
+		t.Fatalf("transform, expected nil got %v", err)
+	}
+	if _, ok := d.Entities()[0].(*entity.Ellipse); !ok {
+		t.Fatalf("transform, expected *entity.Ellipse got %T", d.Entities()[0])
+	}
+	if g.Entities()[0] != d.Entities()[0] {
+		t.Errorf("transform, expected group to hold the ellipse got %T", g.Entities()[0])
+	}
+	d.AddEntity(ins)
+	if err := d.Transform(scaling); err == nil || !cmpPoint(dt.Coord1, []float64{4.0, 3.0, 3.0}) {
+		t.Errorf("sheared insert, expected error and no change got %v %v", err, dt.Coord1)
+	}
+
+	// attributes of INSERT and unsupported entities of known geometry are moved
+	d = drawing.New()
+	d.AddBlock("B", "", 0.0, 0.0, 0.0)
+	ins, _ = d.Insert("B", 1.0, 0.0, 0.0)
+	ins.AddAttribute(entity.NewUnknown("ATTRIB", []format.Tag{
+		{Code: 100, Value: "AcDbEntity"}, {Code: 8, Value: "0"}, {Code: 100, Value: "AcDbText"},
+		{Code: 10, Value: "1.0"}, {Code: 20, Value: "0.0"}, {Code: 30, Value: "0.0"}, {Code: 40, Value: "1.0"}, {Code: 1, Value: "V"},
+		{Code: 100, Value: "AcDbAttribute"}, {Code: 2, Value: "TAG"}, {Code: 70, Value: "0"},
+	}))
+	solid := entity.NewUnknown("SOLID", []format.Tag{
+		{Code: 100, Value: "AcDbEntity"}, {Code: 8, Value: "0"}, {Code: 100, Value: "AcDbTrace"},
+		{Code: 10, Value: "0.0"}, {Code: 20, Value: "0.0"}, {Code: 30, Value: "0.0"}, {Code: 11, Value: "1.0"}, {Code: 21, Value: "0.0"}, {Code: 31, Value: "0.0"},
+		{Code: 12, Value: "0.0"}, {Code: 22, Value: "1.0"}, {Code: 32, Value: "0.0"}, {Code: 13, Value: "1.0"}, {Code: 23, Value: "1.0"}, {Code: 33, Value: "0.0"},
+	})
+	d.AddEntity(solid)
+	if err := d.Translate(1.0, 2.0, 0.0); err != nil {
+		t.Fatalf("translate, expected nil got %v", err)
+	}
+	moved := func(u *entity.Unknown, code int, expected string) bool {
+		for _, tag := range u.Tags {
+			if tag.Code == code {
+				return tag.Value == expected
+			}
+		}
+		return false
+	}
+	if a := ins.Attributes[0]; !moved(a, 10, "2") || !moved(a, 20, "2") {
+		t.Errorf("translated attribute, expected (2, 2) got %v", a.Tags)
+	}
+	if !moved(solid, 13, "2") || !moved(solid, 23, "3") {
+		t.Errorf("translated solid, expected (2, 3) got %v", solid.Tags)
+	}
+	d.AddEntity(entity.NewUnknown("REGION", []format.Tag{{Code: 100, Value: "AcDbEntity"}, {Code: 8, Value: "0"}}))
+	if err := d.Translate(1.0, 0.0, 0.0); err == nil || !moved(solid, 13, "2") {
+		t.Errorf("translated region, expected error and no change got %v %v", err, solid.Tags)
+	}
+}
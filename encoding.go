@@ -0,0 +1,137 @@
+package dxf
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+
+	"github.com/flywave/go-dxf/format"
+)
+
+// DefaultCodePage is the code page of files before R2007 without $DWGCODEPAGE.
+const DefaultCodePage = format.DefaultCodePage
+
+// multiByteFonts maps n of \M+nXXXX to encodings.
+var multiByteFonts = map[byte]encoding.Encoding{
+	'1': japanese.ShiftJIS,
+	'2': traditionalchinese.Big5,
+	'3': korean.EUCKR,
+	'5': simplifiedchinese.GBK,
+}
+
+// textDecoder converts values into UTF-8.
+// Values of files before R2007 are decoded by the code page unless they are valid UTF-8,
+// as some applications write UTF-8 regardless of the version.
+type textDecoder struct {
+	version  string            // $ACADVER
+	codepage string            // $DWGCODEPAGE
+	fixed    bool              // code page is set by the user
+	enc      encoding.Encoding // nil for UTF-8
+}
+
+// newTextDecoder creates a textDecoder for files before R2007 in DefaultCodePage.
+func newTextDecoder() *textDecoder {
+	t := &textDecoder{codepage: DefaultCodePage}
+	t.update()
+	return t
+}
+
+// update sets the encoding according to the version and code page.
+func (t *textDecoder) update() {
+	if v, err := format.ParseVersion(strings.TrimSpace(t.version)); err == nil && v >= format.R2007 && !t.fixed {
+		t.enc = nil
+		return
+	}
+	if e, err := format.CodePage(t.codepage); err == nil {
+		t.enc = e
+	}
+}
+
+// decode returns the value of the group code in UTF-8.
+// \U+XXXX and \M+nXXXX sequences are translated only in values of text codes.
+func (t *textDecoder) decode(code int, s string) string {
+	if t.enc != nil && !utf8.ValidString(s) {
+		if d, err := t.enc.NewDecoder().String(s); err == nil {
+			s = d
+		}
+	}
+	if !textCode(code) {
+		return s
+	}
+	return decodeEscapes(s)
+}
+
+// textCode reports whether values of the group code are text,
+// which excludes entity types, handles, subclass markers and control strings.
+func textCode(code int) bool {
+	switch code {
+	case 0, 5, 100, 102:
+		return false
+	}
+	return format.CodeType(code) == format.STRING
+}
+
+// decodeEscapes translates \U+XXXX and \M+nXXXX sequences into characters.
+// An escaped backslash (\\) is kept as is, so the sequence after it is not translated.
+func decodeEscapes(s string) string {
+	i := strings.Index(s, "\\")
+	if i < 0 {
+		return s
+	}
+	var b strings.Builder
+	b.WriteString(s[:i])
+	for i < len(s) {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\\' {
+			b.WriteString(s[i : i+2])
+			i += 2
+			continue
+		}
+		if r, n := escapedChar(s[i:]); n > 0 {
+			b.WriteString(r)
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// escapedChar returns the character of the escape sequence at the start of s
+// and its length, or 0 if s doesn't start with a valid sequence.
+func escapedChar(s string) (string, int) {
+	switch {
+	case strings.HasPrefix(s, "\\U+") && len(s) >= 7:
+		v, err := strconv.ParseUint(s[3:7], 16, 32)
+		if err != nil {
+			return "", 0
+		}
+		return string(rune(v)), 7
+	case strings.HasPrefix(s, "\\M+") && len(s) >= 8:
+		e, exist := multiByteFonts[s[3]]
+		if !exist {
+			return "", 0
+		}
+		v, err := strconv.ParseUint(s[4:8], 16, 16)
+		if err != nil {
+			return "", 0
+		}
+		c, err := e.NewDecoder().String(string([]byte{byte(v >> 8), byte(v)}))
+		if err != nil || c == "" {
+			return "", 0
+		}
+		return c, 8
+	}
+	return "", 0
+}
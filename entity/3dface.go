@@ -2,6 +2,7 @@ package entity
 
 import (
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 )
 
 // ThreeDFace represents 3DFACE Entity.
@@ -42,6 +43,7 @@ func (f *ThreeDFace) Format(fm format.Formatter) {
 	if f.Flag != 0 {
 		fm.WriteInt(70, f.Flag)
 	}
+	f.FormatXData(fm)
 }
 
 // String outputs data using default formatter.
@@ -71,3 +73,17 @@ func (f *ThreeDFace) BBox() ([]float64, []float64) {
 	}
 	return mins, maxs
 }
+
+// Clone returns a copy of ThreeDFace.
+func (f *ThreeDFace) Clone() Entity {
+	return &ThreeDFace{
+		entity: f.entity.clone(),
+		Points: copyPoints(f.Points),
+		Flag:   f.Flag,
+	}
+}
+
+// Transform transforms ThreeDFace by m.
+func (f *ThreeDFace) Transform(m geometry.Matrix) {
+	transformPoints(m, f.Points)
+}
@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"math"
+
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 )
 
 type Arc struct {
@@ -29,11 +32,12 @@ func NewArc(c *Circle) *Arc {
 
 // Format writes data to formatter.
 func (a *Arc) Format(f format.Formatter) {
-	a.Circle.Format(f)
+	a.Circle.format(f)
 	f.WriteString(100, "AcDbArc")
 	for i := 0; i < 2; i++ {
 		f.WriteFloat(50+i, a.Angle[i])
 	}
+	a.FormatXData(f)
 }
 
 // String write out the String representation
@@ -42,3 +46,29 @@ func (a *Arc) String() string {
 	a.Format(f)
 	return f.Output()
 }
+
+// Clone returns a copy of Arc.
+func (a *Arc) Clone() Entity {
+	return &Arc{
+		Circle: a.Circle.Clone().(*Circle),
+		Angle:  copyPoint(a.Angle),
+	}
+}
+
+// Transform transforms Arc by m.
+// Use TransformEntity unless m scales uniformly in the plane, as for Circle.
+func (a *Arc) Transform(m geometry.Matrix) {
+	t := newOCSTransform(m, a.Direction)
+	for i := 0; i < 2; i++ {
+		a.Angle[i] = t.angle(a.Angle[i])
+	}
+	a.Circle.Transform(m)
+}
+
+// ToEllipse returns an elliptical arc of the same shape as Arc.
+func (a *Arc) ToEllipse() *Ellipse {
+	e := a.Circle.ToEllipse()
+	e.Start = normalizeRadian(a.Angle[0] * math.Pi / 180.0)
+	e.End = normalizeRadian(a.Angle[1] * math.Pi / 180.0)
+	return e
+}
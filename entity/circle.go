@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"math"
+
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 )
 
 // Circle represents CIRCLE Entity.
@@ -30,6 +33,12 @@ func NewCircle() *Circle {
 
 // Format writes data to formatter.
 func (c *Circle) Format(f format.Formatter) {
+	c.format(f)
+	c.FormatXData(f)
+}
+
+// format writes data except XDATA, which is shared with Arc.
+func (c *Circle) format(f format.Formatter) {
 	c.entity.Format(f)
 	f.WriteString(100, "AcDbCircle")
 	for i := 0; i < 3; i++ {
@@ -77,3 +86,39 @@ func (c *Circle) BBox() ([]float64, []float64) {
 	// TODO: extrusion
 	return []float64{c.Center[0] - c.Radius, c.Center[1] - c.Radius, c.Center[2]}, []float64{c.Center[0] + c.Radius, c.Center[1] + c.Radius, c.Center[2]}
 }
+
+// Clone returns a copy of Circle.
+func (c *Circle) Clone() Entity {
+	return &Circle{
+		entity:    c.entity.clone(),
+		Center:    copyPoint(c.Center),
+		Radius:    c.Radius,
+		Direction: copyPoint(c.Direction),
+	}
+}
+
+// Transform transforms Circle by m.
+// Center is kept in OCS of the transformed extrusion direction.
+// The radius follows the transformed OCS X axis,
+// so use TransformEntity unless m scales uniformly in the plane.
+func (c *Circle) Transform(m geometry.Matrix) {
+	t := newOCSTransform(m, c.Direction)
+	c.Center = t.m.Apply(c.Center)
+	c.Radius *= t.xscale
+	c.Direction = t.normal
+}
+
+// ToEllipse returns a full Ellipse of the same shape as Circle.
+func (c *Circle) ToEllipse() *Ellipse {
+	e := &Ellipse{
+		entity:    c.entity.clone(),
+		Center:    geometry.OCS(c.Direction).Apply(c.Center),
+		MajorAxis: geometry.OCS(c.Direction).ApplyVector([]float64{c.Radius, 0.0, 0.0}),
+		Ratio:     1.0,
+		Start:     0.0,
+		End:       2.0 * math.Pi,
+		Direction: copyPoint(c.Direction),
+	}
+	e.SetEntityType(ELLIPSE)
+	return e
+}
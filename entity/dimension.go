@@ -0,0 +1,226 @@
+package entity
+
+import (
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/table"
+)
+
+// Dimension type (lower bits of code 70)
+const (
+	DIM_LINEAR    = 0 // rotated, horizontal or vertical
+	DIM_ALIGNED   = 1
+	DIM_ANGULAR   = 2 // 2 lines
+	DIM_DIAMETER  = 3
+	DIM_RADIUS    = 4
+	DIM_ANGULAR3P = 5 // 3 points
+	DIM_ORDINATE  = 6
+)
+
+// Dimension flags (upper bits of code 70)
+const (
+	DIM_BLOCK_EXCLUSIVE = 32  // block is referenced by this dimension only
+	DIM_ORDINATE_X      = 64  // ordinate dimension measures X
+	DIM_USER_TEXT       = 128 // text is at user-defined location
+)
+
+// Dimension represents DIMENSION Entity.
+// Meanings of definition points depend on the type:
+//
+//	LINEAR, ALIGNED: DefPoint on dimension line, DefPoint2 and DefPoint3 are origins of extension lines
+//	ANGULAR:         DefPoint2-DefPoint3 and DefPoint4-DefPoint are lines, DefPoint5 is on dimension arc
+//	DIAMETER:        DefPoint and DefPoint4 are opposite points on the curve
+//	RADIUS:          DefPoint is center, DefPoint4 is on the curve
+//	ANGULAR3P:       DefPoint is on dimension arc, DefPoint2 and DefPoint3 are ends, DefPoint4 is vertex
+//	ORDINATE:        DefPoint is origin, DefPoint2 is feature location, DefPoint3 is leader end
+type Dimension struct {
+	*entity
+	BlockName         string          // 2
+	DimStyle          *table.DimStyle // 3
+	DefPoint          []float64       // 10, 20, 30 (WCS)
+	TextPoint         []float64       // 11, 21, 31 (OCS)
+	DefPoint2         []float64       // 13, 23, 33 (WCS)
+	DefPoint3         []float64       // 14, 24, 34 (WCS)
+	DefPoint4         []float64       // 15, 25, 35 (WCS)
+	DefPoint5         []float64       // 16, 26, 36 (OCS)
+	Type              int             // 70
+	Attachment        int             // 71
+	LineSpacingStyle  int             // 72
+	LineSpacingFactor float64         // 41
+	Measurement       float64         // 42
+	Text              string          // 1: "" or "<>" means measurement, " " means no text
+	TextRotation      float64         // 53 (Degree)
+	HorizontalAngle   float64         // 51 (Degree)
+	Rotation          float64         // 50: LINEAR only (Degree)
+	Oblique           float64         // 52: LINEAR and ALIGNED only (Degree)
+	LeaderLength      float64         // 40: DIAMETER and RADIUS only
+	Direction         []float64       // 210, 220, 230
+}
+
+// IsEntity is for Entity interface.
+func (d *Dimension) IsEntity() bool {
+	return true
+}
+
+// NewDimension creates a new Dimension of given type.
+func NewDimension(typ int) *Dimension {
+	d := &Dimension{
+		entity:            NewEntity(DIMENSION),
+		DimStyle:          table.NewDimStyle("Standard"),
+		DefPoint:          []float64{0.0, 0.0, 0.0},
+		TextPoint:         []float64{0.0, 0.0, 0.0},
+		DefPoint2:         []float64{0.0, 0.0, 0.0},
+		DefPoint3:         []float64{0.0, 0.0, 0.0},
+		DefPoint4:         []float64{0.0, 0.0, 0.0},
+		DefPoint5:         []float64{0.0, 0.0, 0.0},
+		Type:              typ,
+		Attachment:        MTEXT_MIDDLE_CENTER,
+		LineSpacingStyle:  1,
+		LineSpacingFactor: 1.0,
+		Direction:         []float64{0.0, 0.0, 1.0},
+	}
+	return d
+}
+
+// DimType returns the dimension type without flags.
+func (d *Dimension) DimType() int {
+	return d.Type & 0x0f
+}
+
+// Format writes data to formatter.
+func (d *Dimension) Format(f format.Formatter) {
+	d.entity.Format(f)
+	f.WriteString(100, "AcDbDimension")
+	f.WriteString(2, d.BlockName)
+	for i := 0; i < 3; i++ {
+		f.WriteFloat((i+1)*10, d.DefPoint[i])
+	}
+	for i := 0; i < 3; i++ {
+		f.WriteFloat((i+1)*10+1, d.TextPoint[i])
+	}
+	f.WriteInt(70, d.Type)
+	f.WriteInt(71, d.Attachment)
+	f.WriteInt(72, d.LineSpacingStyle)
+	f.WriteFloat(41, d.LineSpacingFactor)
+	f.WriteFloat(42, d.Measurement)
+	f.WriteString(1, d.Text)
+	if d.TextRotation != 0.0 {
+		f.WriteFloat(53, d.TextRotation)
+	}
+	if d.HorizontalAngle != 0.0 {
+		f.WriteFloat(51, d.HorizontalAngle)
+	}
+	if !isDefaultDirection(d.Direction) {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat(200+(i+1)*10, d.Direction[i])
+		}
+	}
+	f.WriteString(3, d.DimStyle.Name())
+	point := func(code int, p []float64) {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat(code+i*10, p[i])
+		}
+	}
+	switch d.DimType() {
+	case DIM_LINEAR, DIM_ALIGNED:
+		f.WriteString(100, "AcDbAlignedDimension")
+		point(13, d.DefPoint2)
+		point(14, d.DefPoint3)
+		if d.DimType() == DIM_LINEAR {
+			f.WriteFloat(50, d.Rotation)
+			if d.Oblique != 0.0 {
+				f.WriteFloat(52, d.Oblique)
+			}
+			f.WriteString(100, "AcDbRotatedDimension")
+		} else if d.Oblique != 0.0 {
+			f.WriteFloat(52, d.Oblique)
+		}
+	case DIM_ANGULAR:
+		f.WriteString(100, "AcDb2LineAngularDimension")
+		point(13, d.DefPoint2)
+		point(14, d.DefPoint3)
+		point(15, d.DefPoint4)
+		point(16, d.DefPoint5)
+	case DIM_DIAMETER:
+		f.WriteString(100, "AcDbDiametricDimension")
+		point(15, d.DefPoint4)
+		f.WriteFloat(40, d.LeaderLength)
+	case DIM_RADIUS:
+		f.WriteString(100, "AcDbRadialDimension")
+		point(15, d.DefPoint4)
+		f.WriteFloat(40, d.LeaderLength)
+	case DIM_ANGULAR3P:
+		f.WriteString(100, "AcDb3PointAngularDimension")
+		point(13, d.DefPoint2)
+		point(14, d.DefPoint3)
+		point(15, d.DefPoint4)
+	case DIM_ORDINATE:
+		f.WriteString(100, "AcDbOrdinateDimension")
+		point(13, d.DefPoint2)
+		point(14, d.DefPoint3)
+	}
+	d.FormatXData(f)
+}
+
+// String outputs data using default formatter.
+func (d *Dimension) String() string {
+	f := format.NewASCII()
+	return d.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (d *Dimension) FormatString(f format.Formatter) string {
+	d.Format(f)
+	return f.Output()
+}
+
+// points returns definition points used by the type.
+func (d *Dimension) points() [][]float64 {
+	switch d.DimType() {
+	case DIM_LINEAR, DIM_ALIGNED, DIM_ORDINATE:
+		return [][]float64{d.DefPoint, d.DefPoint2, d.DefPoint3, d.TextPoint}
+	case DIM_DIAMETER, DIM_RADIUS:
+		return [][]float64{d.DefPoint, d.DefPoint4, d.TextPoint}
+	case DIM_ANGULAR:
+		return [][]float64{d.DefPoint, d.DefPoint2, d.DefPoint3, d.DefPoint4, d.DefPoint5, d.TextPoint}
+	default:
+		return [][]float64{d.DefPoint, d.DefPoint2, d.DefPoint3, d.DefPoint4, d.TextPoint}
+	}
+}
+
+// BBox returns bounding box of definition points and text location.
+func (d *Dimension) BBox() ([]float64, []float64) {
+	return pointsBBox(d.points())
+}
+
+// Clone returns a copy of Dimension.
+// The copy refers to the same block.
+func (d *Dimension) Clone() Entity {
+	c := *d
+	c.entity = d.entity.clone()
+	c.DefPoint = copyPoint(d.DefPoint)
+	c.TextPoint = copyPoint(d.TextPoint)
+	c.DefPoint2 = copyPoint(d.DefPoint2)
+	c.DefPoint3 = copyPoint(d.DefPoint3)
+	c.DefPoint4 = copyPoint(d.DefPoint4)
+	c.DefPoint5 = copyPoint(d.DefPoint5)
+	c.Direction = copyPoint(d.Direction)
+	return &c
+}
+
+// Transform transforms definition points of Dimension by m.
+// Measurement and the block geometry are not updated.
+func (d *Dimension) Transform(m geometry.Matrix) {
+	t := newOCSTransform(m, d.Direction)
+	d.DefPoint = m.Apply(d.DefPoint)
+	d.DefPoint2 = m.Apply(d.DefPoint2)
+	d.DefPoint3 = m.Apply(d.DefPoint3)
+	d.DefPoint4 = m.Apply(d.DefPoint4)
+	d.TextPoint = t.m.Apply(d.TextPoint)
+	d.DefPoint5 = t.m.Apply(d.DefPoint5)
+	if d.DimType() == DIM_LINEAR {
+		d.Rotation = t.angle(d.Rotation)
+	}
+	d.TextRotation = t.angle(d.TextRotation) - t.angle(0.0)
+	d.Direction = t.normal
+}
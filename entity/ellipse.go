@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"math"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+)
+
+// Ellipse represents ELLIPSE Entity.
+// Unlike Circle, Center and MajorAxis are in WCS.
+type Ellipse struct {
+	*entity
+	Center    []float64 // 10, 20, 30
+	MajorAxis []float64 // 11, 21, 31: endpoint of major axis relative to Center
+	Ratio     float64   // 40: ratio of minor axis to major axis
+	Start     float64   // 41: start parameter (Radian)
+	End       float64   // 42: end parameter (Radian)
+	Direction []float64 // 210, 220, 230
+}
+
+// IsEntity is for Entity interface.
+func (e *Ellipse) IsEntity() bool {
+	return true
+}
+
+// NewEllipse creates a new full Ellipse.
+func NewEllipse() *Ellipse {
+	e := &Ellipse{
+		entity:    NewEntity(ELLIPSE),
+		Center:    []float64{0.0, 0.0, 0.0},
+		MajorAxis: []float64{1.0, 0.0, 0.0},
+		Ratio:     1.0,
+		Start:     0.0,
+		End:       2.0 * math.Pi,
+		Direction: []float64{0.0, 0.0, 1.0},
+	}
+	return e
+}
+
+// Format writes data to formatter.
+func (e *Ellipse) Format(f format.Formatter) {
+	e.entity.Format(f)
+	f.WriteString(100, "AcDbEllipse")
+	for i := 0; i < 3; i++ {
+		f.WriteFloat((i+1)*10, e.Center[i])
+	}
+	for i := 0; i < 3; i++ {
+		f.WriteFloat((i+1)*10+1, e.MajorAxis[i])
+	}
+	for i := 0; i < 3; i++ {
+		f.WriteFloat(200+(i+1)*10, e.Direction[i])
+	}
+	f.WriteFloat(40, e.Ratio)
+	f.WriteFloat(41, e.Start)
+	f.WriteFloat(42, e.End)
+	e.FormatXData(f)
+}
+
+// String outputs data using default formatter.
+func (e *Ellipse) String() string {
+	f := format.NewASCII()
+	return e.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (e *Ellipse) FormatString(f format.Formatter) string {
+	e.Format(f)
+	return f.Output()
+}
+
+// MinorAxis returns the endpoint of minor axis relative to Center.
+func (e *Ellipse) MinorAxis() []float64 {
+	n := geometry.Normalize(geometry.Cross(geometry.Normalize(e.Direction), e.MajorAxis))
+	l := geometry.Length(e.MajorAxis) * e.Ratio
+	return []float64{n[0] * l, n[1] * l, n[2] * l}
+}
+
+// IsFull reports whether Ellipse is closed.
+func (e *Ellipse) IsFull() bool {
+	return math.Abs(e.sweep()-2.0*math.Pi) < 1e-9
+}
+
+// sweep returns the parameter range from Start to End counterclockwise.
+func (e *Ellipse) sweep() float64 {
+	s := math.Mod(e.End-e.Start, 2.0*math.Pi)
+	if s <= 1e-12 {
+		s += 2.0 * math.Pi
+	}
+	return s
+}
+
+// Point returns the point of parameter t in WCS.
+func (e *Ellipse) Point(t float64) []float64 {
+	b := e.MinorAxis()
+	s, c := math.Sincos(t)
+	p := make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		p[i] = e.Center[i] + c*e.MajorAxis[i] + s*b[i]
+	}
+	return p
+}
+
+// BBox returns the exact bounding box of Ellipse or elliptical arc.
+func (e *Ellipse) BBox() ([]float64, []float64) {
+	mins := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+	maxs := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	add := func(p []float64) {
+		for i := 0; i < 3; i++ {
+			mins[i] = math.Min(mins[i], p[i])
+			maxs[i] = math.Max(maxs[i], p[i])
+		}
+	}
+	add(e.Point(e.Start))
+	add(e.Point(e.End))
+	b := e.MinorAxis()
+	sweep := e.sweep()
+	for i := 0; i < 3; i++ {
+		// coordinate i is extreme where its derivative -sin(t)*a + cos(t)*b is zero
+		t := math.Atan2(b[i], e.MajorAxis[i])
+		for _, u := range []float64{t, t + math.Pi} {
+			if geometry.AngleInSweep(u, e.Start, sweep) {
+				add(e.Point(u))
+			}
+		}
+	}
+	return mins, maxs
+}
+
+// Clone returns a copy of Ellipse.
+func (e *Ellipse) Clone() Entity {
+	return &Ellipse{
+		entity:    e.entity.clone(),
+		Center:    copyPoint(e.Center),
+		MajorAxis: copyPoint(e.MajorAxis),
+		Ratio:     e.Ratio,
+		Start:     e.Start,
+		End:       e.End,
+		Direction: copyPoint(e.Direction),
+	}
+}
+
+// Transform transforms Ellipse by m.
+// Non-uniform scaling and shearing are supported by finding the new principal axes.
+func (e *Ellipse) Transform(m geometry.Matrix) {
+	full := e.IsFull()
+	a := m.ApplyVector(e.MajorAxis)
+	b := m.ApplyVector(e.MinorAxis())
+	major, minor, t0 := principalAxes(a, b)
+	if n := geometry.Normalize(geometry.Cross(a, b)); geometry.Length(n) > 0.0 {
+		e.Direction = n
+	}
+	e.Center = m.Apply(e.Center)
+	e.MajorAxis = major
+	if l := geometry.Length(major); l > 0.0 {
+		e.Ratio = geometry.Length(minor) / l
+	}
+	if full {
+		e.Start, e.End = 0.0, 2.0*math.Pi
+	} else {
+		e.Start = normalizeRadian(e.Start - t0)
+		e.End = normalizeRadian(e.End - t0)
+	}
+}
+
+// normalizeRadian returns angle a in range [0, 2*Pi).
+func normalizeRadian(a float64) float64 {
+	a = math.Mod(a, 2.0*math.Pi)
+	if a < 0.0 {
+		a += 2.0 * math.Pi
+	}
+	return a
+}
@@ -2,8 +2,10 @@ package entity
 
 import (
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 	"github.com/flywave/go-dxf/handle"
 	"github.com/flywave/go-dxf/table"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // Entity is interface for DXF Entities.
@@ -17,6 +19,10 @@ type Entity interface {
 	SetLayer(*table.Layer)
 	SetLtscale(float64)
 	BBox() ([]float64, []float64)
+	Clone() Entity
+	// Transform transforms the entity in place.
+	// Use TransformEntity for a matrix which may not keep circles circular.
+	Transform(geometry.Matrix)
 }
 
 // entity is common part of Entities.
@@ -27,7 +33,11 @@ type entity struct {
 	blockRecord handle.Handler // 102 330
 	owner       handle.Handler // 330
 	layer       *table.Layer   // 8
+	color       int            // 62: 256 is BYLAYER
 	ltscale     float64        // 48
+	truecolor   int            // 420: 0x00RRGGBB, -1 if not set
+	transparent int            // 440: 0x020000TT, 0 if not set
+	xdata.XData                // 1001-1071
 }
 
 // NewEntity creates a new entity.
@@ -38,7 +48,9 @@ func NewEntity(t EntityType) *entity {
 		blockRecord: nil,
 		owner:       nil,
 		layer:       table.LY_0,
+		color:       256,
 		ltscale:     1.0,
+		truecolor:   -1,
 	}
 	return e
 }
@@ -57,9 +69,18 @@ func (e *entity) Format(f format.Formatter) {
 	}
 	f.WriteString(100, "AcDbEntity")
 	f.WriteString(8, e.layer.Name())
+	if e.color != 256 {
+		f.WriteInt(62, e.color)
+	}
 	if e.ltscale != 1.0 {
 		f.WriteFloat(48, e.ltscale)
 	}
+	if e.truecolor >= 0 {
+		f.WriteInt(420, e.truecolor)
+	}
+	if e.transparent != 0 {
+		f.WriteInt(440, e.transparent)
+	}
 }
 
 // String outputs data using default formatter.
@@ -90,6 +111,11 @@ func (e *entity) SetBlockRecord(h handle.Handler) {
 	e.blockRecord = h
 }
 
+// Owner returns the owner (code 330), or nil if not set.
+func (e *entity) Owner() handle.Handler {
+	return e.owner
+}
+
 // SetOwner sets an owner.
 func (e *entity) SetOwner(h handle.Handler) {
 	e.owner = h
@@ -110,7 +136,48 @@ func (e *entity) SetLtscale(v float64) {
 	e.ltscale = v
 }
 
+// Color returns ACI color number (code 62).
+// 0 is BYBLOCK and 256 is BYLAYER.
+func (e *entity) Color() int {
+	return e.color
+}
+
+// SetColor sets ACI color number (code 62).
+func (e *entity) SetColor(c int) {
+	e.color = c
+}
+
+// TrueColor returns 24-bit color 0x00RRGGBB (code 420), or -1 if not set.
+func (e *entity) TrueColor() int {
+	return e.truecolor
+}
+
+// SetTrueColor sets 24-bit color 0x00RRGGBB (code 420).
+// -1 unsets it. It is written for R2004 and later.
+func (e *entity) SetTrueColor(rgb int) {
+	e.truecolor = rgb
+}
+
+// Transparency returns transparency value (code 440), or 0 if not set.
+func (e *entity) Transparency() int {
+	return e.transparent
+}
+
+// SetTransparency sets transparency value (code 440).
+// Use color.Transparency to convert from ratio. 0 unsets it.
+func (e *entity) SetTransparency(v int) {
+	e.transparent = v
+}
+
 // SetEntityType sets entity type.
 func (e *entity) SetEntityType(t EntityType) {
 	e.Type = t
 }
+
+// clone returns a copy of common part without handle.
+func (e *entity) clone() *entity {
+	c := *e
+	c.handle = 0
+	c.XData = e.CloneXData()
+	return &c
+}
@@ -15,6 +15,13 @@ const (
 	ARC
 	TEXT
 	SPLINE
+	INSERT
+	ELLIPSE
+	MTEXT
+	HATCH
+	DIMENSION
+	LEADER
+	MULTILEADER
 )
 
 // EntityTypeString converts EntityType to string.
@@ -41,6 +48,20 @@ func EntityTypeString(t EntityType) string {
 		return "TEXT"
 	case SPLINE:
 		return "SPLINE"
+	case INSERT:
+		return "INSERT"
+	case ELLIPSE:
+		return "ELLIPSE"
+	case MTEXT:
+		return "MTEXT"
+	case HATCH:
+		return "HATCH"
+	case DIMENSION:
+		return "DIMENSION"
+	case LEADER:
+		return "LEADER"
+	case MULTILEADER:
+		return "MULTILEADER"
 	default:
 		return ""
 	}
@@ -70,6 +91,20 @@ func EntityTypeValue(t string) EntityType {
 		return TEXT
 	case "SPLINE":
 		return SPLINE
+	case "INSERT":
+		return INSERT
+	case "ELLIPSE":
+		return ELLIPSE
+	case "MTEXT":
+		return MTEXT
+	case "HATCH":
+		return HATCH
+	case "DIMENSION":
+		return DIMENSION
+	case "LEADER":
+		return LEADER
+	case "MULTILEADER":
+		return MULTILEADER
 	default:
 		return -1
 	}
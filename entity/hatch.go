@@ -0,0 +1,500 @@
+package entity
+
+import (
+	"math"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/handle"
+)
+
+// Hatch boundary path type flags (code 92)
+const (
+	HATCH_PATH_EXTERNAL  = 1
+	HATCH_PATH_POLYLINE  = 2
+	HATCH_PATH_DERIVED   = 4
+	HATCH_PATH_TEXTBOX   = 8
+	HATCH_PATH_OUTERMOST = 16
+)
+
+// Hatch style (code 75)
+const (
+	HATCH_STYLE_NORMAL = iota // odd parity
+	HATCH_STYLE_OUTER         // outermost area only
+	HATCH_STYLE_IGNORE        // entire area
+)
+
+// Hatch pattern type (code 76)
+const (
+	HATCH_PATTERN_USER = iota
+	HATCH_PATTERN_PREDEFINED
+	HATCH_PATTERN_CUSTOM
+)
+
+// HatchPath represents a boundary path of Hatch.
+// If Flag has HATCH_PATH_POLYLINE, Vertices and Bulges are used, otherwise Edges.
+type HatchPath struct {
+	Flag     int              // 92
+	Closed   bool             // 73: polyline only
+	Vertices [][]float64      // 93, 10, 20: polyline only
+	Bulges   []float64        // 72, 42: polyline only
+	Edges    []HatchEdge      // 93: edges
+	Sources  []handle.Handler // 97, 330: source boundary objects
+}
+
+// PatternLine represents a pattern definition line of Hatch.
+type PatternLine struct {
+	Angle  float64   // 53 (Degree)
+	Base   []float64 // 43, 44
+	Offset []float64 // 45, 46
+	Dashes []float64 // 79, 49
+}
+
+// GradientColor represents a color of gradient fill.
+type GradientColor struct {
+	Value float64 // 463
+	Color int     // 63: ACI color
+	RGB   int     // 421
+}
+
+// Gradient represents gradient fill data of Hatch.
+type Gradient struct {
+	Name        string          // 470: e.g. LINEAR, CYLINDER, SPHERICAL
+	SingleColor bool            // 452
+	Angle       float64         // 460 (Radian)
+	Shift       float64         // 461
+	Tint        float64         // 462
+	Colors      []GradientColor // 453
+}
+
+// Hatch represents HATCH Entity.
+// Boundary paths, pattern lines and seed points are 2D in OCS at Elevation.
+type Hatch struct {
+	*entity
+	Elevation     float64        // 30
+	Direction     []float64      // 210, 220, 230
+	PatternName   string         // 2
+	Solid         bool           // 70
+	Associative   bool           // 71
+	Paths         []*HatchPath   // 91
+	Style         int            // 75
+	PatternType   int            // 76
+	PatternAngle  float64        // 52 (Degree)
+	PatternScale  float64        // 41
+	PatternDouble bool           // 77
+	PatternLines  []*PatternLine // 78
+	PixelSize     float64        // 47
+	Seeds         [][]float64    // 98, 10, 20
+	Gradient      *Gradient      // 450: nil if not gradient fill
+}
+
+// IsEntity is for Entity interface.
+func (h *Hatch) IsEntity() bool {
+	return true
+}
+
+// NewHatch creates a new solid Hatch without boundary paths.
+func NewHatch() *Hatch {
+	h := &Hatch{
+		entity:       NewEntity(HATCH),
+		Direction:    []float64{0.0, 0.0, 1.0},
+		PatternName:  "SOLID",
+		Solid:        true,
+		Paths:        make([]*HatchPath, 0),
+		Style:        HATCH_STYLE_NORMAL,
+		PatternType:  HATCH_PATTERN_PREDEFINED,
+		PatternScale: 1.0,
+		PatternLines: make([]*PatternLine, 0),
+		Seeds:        make([][]float64, 0),
+	}
+	return h
+}
+
+// AddPolylinePath adds a closed polyline boundary path.
+func (h *Hatch) AddPolylinePath(vertices ...[]float64) *HatchPath {
+	p := &HatchPath{
+		Flag:     HATCH_PATH_EXTERNAL | HATCH_PATH_POLYLINE,
+		Closed:   true,
+		Vertices: copyPoints(vertices),
+	}
+	h.Paths = append(h.Paths, p)
+	return p
+}
+
+// AddEdgePath adds a boundary path consisting of edges.
+func (h *Hatch) AddEdgePath(edges ...HatchEdge) *HatchPath {
+	p := &HatchPath{
+		Flag:  HATCH_PATH_EXTERNAL,
+		Edges: edges,
+	}
+	h.Paths = append(h.Paths, p)
+	return p
+}
+
+// IsPolyline reports whether HatchPath is a polyline.
+func (p *HatchPath) IsPolyline() bool {
+	return p.Flag&HATCH_PATH_POLYLINE != 0
+}
+
+// hasBulge reports whether any vertex has bulge.
+func (p *HatchPath) hasBulge() bool {
+	for _, b := range p.Bulges {
+		if b != 0.0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Format writes data to formatter.
+func (p *HatchPath) Format(f format.Formatter) {
+	f.WriteInt(92, p.Flag)
+	if p.IsPolyline() {
+		bulge := p.hasBulge()
+		f.WriteInt(72, boolInt(bulge))
+		f.WriteInt(73, boolInt(p.Closed))
+		f.WriteInt(93, len(p.Vertices))
+		for i, v := range p.Vertices {
+			f.WriteFloat(10, v[0])
+			f.WriteFloat(20, v[1])
+			if bulge {
+				b := 0.0
+				if i < len(p.Bulges) {
+					b = p.Bulges[i]
+				}
+				f.WriteFloat(42, b)
+			}
+		}
+	} else {
+		f.WriteInt(93, len(p.Edges))
+		for _, e := range p.Edges {
+			e.Format(f)
+		}
+	}
+	f.WriteInt(97, len(p.Sources))
+	for _, s := range p.Sources {
+		f.WriteHex(330, s.Handle())
+	}
+}
+
+// Ring returns the boundary as a closed 2D ring in OCS.
+// Arcs and curves are tessellated within tolerance.
+func (p *HatchPath) Ring(tolerance float64) [][]float64 {
+	ring := make([][]float64, 0)
+	add := func(ps [][]float64) {
+		for _, q := range ps {
+			if n := len(ring); n > 0 && samePoint(ring[n-1], q) {
+				continue
+			}
+			ring = append(ring, q)
+		}
+	}
+	if p.IsPolyline() {
+		n := len(p.Vertices)
+		for i, v := range p.Vertices {
+			b := 0.0
+			if i < len(p.Bulges) {
+				b = p.Bulges[i]
+			}
+			add(geometry.BulgePoints(v, p.Vertices[(i+1)%n], b, tolerance))
+		}
+	} else {
+		for _, e := range p.Edges {
+			add(e.Points(tolerance))
+		}
+	}
+	if n := len(ring); n > 1 && samePoint(ring[0], ring[n-1]) {
+		ring = ring[:n-1]
+	}
+	if len(ring) > 0 {
+		ring = append(ring, []float64{ring[0][0], ring[0][1]})
+	}
+	return ring
+}
+
+// clone returns a copy of HatchPath.
+func (p *HatchPath) clone() *HatchPath {
+	c := *p
+	c.Vertices = copyPoints(p.Vertices)
+	c.Bulges = copyPoint(p.Bulges)
+	c.Sources = append([]handle.Handler(nil), p.Sources...)
+	c.Edges = make([]HatchEdge, len(p.Edges))
+	for i, e := range p.Edges {
+		c.Edges[i] = e.CloneEdge()
+	}
+	return &c
+}
+
+// Format writes data to formatter.
+func (l *PatternLine) Format(f format.Formatter) {
+	f.WriteFloat(53, l.Angle)
+	f.WriteFloat(43, l.Base[0])
+	f.WriteFloat(44, l.Base[1])
+	f.WriteFloat(45, l.Offset[0])
+	f.WriteFloat(46, l.Offset[1])
+	f.WriteInt(79, len(l.Dashes))
+	for _, d := range l.Dashes {
+		f.WriteFloat(49, d)
+	}
+}
+
+// Format writes data to formatter.
+func (g *Gradient) Format(f format.Formatter) {
+	f.WriteInt(450, 1)
+	f.WriteInt(451, 0)
+	f.WriteInt(452, boolInt(g.SingleColor))
+	f.WriteInt(453, len(g.Colors))
+	f.WriteFloat(460, g.Angle)
+	f.WriteFloat(461, g.Shift)
+	f.WriteFloat(462, g.Tint)
+	for _, c := range g.Colors {
+		f.WriteFloat(463, c.Value)
+		if c.Color != 0 {
+			f.WriteInt(63, c.Color)
+		}
+		f.WriteInt(421, c.RGB)
+	}
+	f.WriteString(470, g.Name)
+}
+
+// Format writes data to formatter.
+func (h *Hatch) Format(f format.Formatter) {
+	h.entity.Format(f)
+	f.WriteString(100, "AcDbHatch")
+	f.WriteFloat(10, 0.0)
+	f.WriteFloat(20, 0.0)
+	f.WriteFloat(30, h.Elevation)
+	for i := 0; i < 3; i++ {
+		f.WriteFloat(200+(i+1)*10, h.Direction[i])
+	}
+	f.WriteString(2, h.PatternName)
+	f.WriteInt(70, boolInt(h.Solid))
+	f.WriteInt(71, boolInt(h.Associative))
+	f.WriteInt(91, len(h.Paths))
+	for _, p := range h.Paths {
+		p.Format(f)
+	}
+	f.WriteInt(75, h.Style)
+	f.WriteInt(76, h.PatternType)
+	if !h.Solid {
+		f.WriteFloat(52, h.PatternAngle)
+		f.WriteFloat(41, h.PatternScale)
+		f.WriteInt(77, boolInt(h.PatternDouble))
+		f.WriteInt(78, len(h.PatternLines))
+		for _, l := range h.PatternLines {
+			l.Format(f)
+		}
+	}
+	if h.PixelSize != 0.0 {
+		f.WriteFloat(47, h.PixelSize)
+	}
+	f.WriteInt(98, len(h.Seeds))
+	for _, s := range h.Seeds {
+		f.WriteFloat(10, s[0])
+		f.WriteFloat(20, s[1])
+	}
+	if h.Gradient != nil {
+		h.Gradient.Format(f)
+	}
+	h.FormatXData(f)
+}
+
+// String outputs data using default formatter.
+func (h *Hatch) String() string {
+	f := format.NewASCII()
+	return h.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (h *Hatch) FormatString(f format.Formatter) string {
+	h.Format(f)
+	return f.Output()
+}
+
+// Polygons converts boundary paths into polygons with holes in OCS.
+// Each polygon is a list of closed rings; the first is the outer ring and the rest are holes,
+// like coordinates of GeoJSON MultiPolygon.
+// Nesting is decided by containment, so islands inside holes become new polygons.
+// Arcs and curves are tessellated within tolerance.
+func (h *Hatch) Polygons(tolerance float64) [][][][]float64 {
+	rings := make([][][]float64, 0, len(h.Paths))
+	for _, p := range h.Paths {
+		if r := p.Ring(tolerance); len(r) >= 4 {
+			rings = append(rings, r)
+		}
+	}
+	// parent is the smallest ring containing each ring, or -1.
+	areas := make([]float64, len(rings))
+	for i, r := range rings {
+		areas[i] = math.Abs(geometry.PolygonArea(r))
+	}
+	parent := make([]int, len(rings))
+	for i, r := range rings {
+		parent[i] = -1
+		for j, o := range rings {
+			if i == j || areas[j] <= areas[i] || !ringInside(r, o) {
+				continue
+			}
+			if parent[i] < 0 || areas[j] < areas[parent[i]] {
+				parent[i] = j
+			}
+		}
+	}
+	depth := func(i int) int {
+		d := 0
+		for j := parent[i]; j >= 0; j = parent[j] {
+			d++
+		}
+		return d
+	}
+	polygons := make([][][][]float64, 0)
+	index := make(map[int]int)
+	for i, r := range rings {
+		if depth(i)%2 == 0 {
+			index[i] = len(polygons)
+			polygons = append(polygons, [][][]float64{r})
+		}
+	}
+	for i, r := range rings {
+		if k, ok := index[parent[i]]; ok && depth(i)%2 == 1 {
+			polygons[k] = append(polygons[k], r)
+		}
+	}
+	return polygons
+}
+
+// Boundaries returns boundary paths as closed 2D Polylines in OCS
+// for the versions which don't have HATCH.
+// Arcs and curves are tessellated within tolerance.
+func (h *Hatch) Boundaries(tolerance float64) Entities {
+	es := New()
+	for _, p := range h.Paths {
+		r := p.Ring(tolerance)
+		if len(r) < 4 {
+			continue
+		}
+		pl := NewPolyline()
+		pl.entity = h.entity.clone()
+		pl.SetEntityType(POLYLINE)
+		pl.Flag = 0
+		pl.Close()
+		pl.Elevation = h.Elevation
+		pl.Direction = copyPoint(h.Direction)
+		for _, q := range r[:len(r)-1] {
+			pl.AddVertex(q[0], q[1], h.Elevation)
+		}
+		es = es.Add(pl)
+	}
+	return es
+}
+
+// ringInside reports whether ring r is inside ring o.
+// Rings are assumed not to intersect, so a vertex of r decides it.
+func ringInside(r, o [][]float64) bool {
+	for _, p := range r {
+		if !onRing(p, o) {
+			return geometry.PointInPolygon(p, o)
+		}
+	}
+	return false
+}
+
+// onRing reports whether p is a vertex of ring.
+func onRing(p []float64, ring [][]float64) bool {
+	for _, q := range ring {
+		if samePoint(p, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// samePoint reports whether 2D points p and q are equal.
+func samePoint(p, q []float64) bool {
+	return math.Abs(p[0]-q[0]) < 1e-12 && math.Abs(p[1]-q[1]) < 1e-12
+}
+
+// BBox returns the bounding box of boundary paths in WCS.
+// Arcs and curves are tessellated within 1/10000 of the size of Hatch.
+func (h *Hatch) BBox() ([]float64, []float64) {
+	bbox := func(tolerance float64) ([]float64, []float64) {
+		mins := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+		maxs := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+		m := geometry.OCS(h.Direction)
+		for _, p := range h.Paths {
+			for _, q := range p.Ring(tolerance) {
+				c := m.Apply([]float64{q[0], q[1], h.Elevation})
+				for i := 0; i < 3; i++ {
+					mins[i] = math.Min(mins[i], c[i])
+					maxs[i] = math.Max(maxs[i], c[i])
+				}
+			}
+		}
+		return mins, maxs
+	}
+	mins, maxs := bbox(0.0)
+	size := geometry.Length([]float64{maxs[0] - mins[0], maxs[1] - mins[1], maxs[2] - mins[2]})
+	if math.IsInf(size, 0) || math.IsNaN(size) || size == 0.0 {
+		return mins, maxs
+	}
+	return bbox(size * 1e-4)
+}
+
+// Clone returns a copy of Hatch.
+func (h *Hatch) Clone() Entity {
+	c := *h
+	c.entity = h.entity.clone()
+	c.Direction = copyPoint(h.Direction)
+	c.Paths = make([]*HatchPath, len(h.Paths))
+	for i, p := range h.Paths {
+		c.Paths[i] = p.clone()
+	}
+	c.PatternLines = make([]*PatternLine, len(h.PatternLines))
+	for i, l := range h.PatternLines {
+		c.PatternLines[i] = &PatternLine{
+			Angle:  l.Angle,
+			Base:   copyPoint(l.Base),
+			Offset: copyPoint(l.Offset),
+			Dashes: copyPoint(l.Dashes),
+		}
+	}
+	c.Seeds = copyPoints(h.Seeds)
+	if h.Gradient != nil {
+		g := *h.Gradient
+		g.Colors = append([]GradientColor(nil), h.Gradient.Colors...)
+		c.Gradient = &g
+	}
+	return &c
+}
+
+// Transform transforms Hatch by m.
+// Boundaries are kept in OCS of the transformed extrusion direction,
+// and circular arc edges become elliptic arc edges under non-uniform scaling.
+func (h *Hatch) Transform(m geometry.Matrix) {
+	t := newOCSTransform(m, h.Direction)
+	// 2D coordinates are on the plane at Elevation
+	t.m = t.m.Multiply(geometry.Translation(0.0, 0.0, h.Elevation))
+	for _, p := range h.Paths {
+		for i, v := range p.Vertices {
+			p.Vertices[i] = apply2D(t.m, v)
+		}
+		for i, e := range p.Edges {
+			p.Edges[i] = e.transform(t)
+		}
+	}
+	for _, l := range h.PatternLines {
+		l.Angle = t.angle(l.Angle)
+		l.Base = apply2D(t.m, l.Base)
+		l.Offset = t.m.ApplyVector([]float64{l.Offset[0], l.Offset[1], 0.0})[:2]
+		for i := range l.Dashes {
+			l.Dashes[i] *= t.xscale
+		}
+	}
+	for i, s := range h.Seeds {
+		h.Seeds[i] = apply2D(t.m, s)
+	}
+	h.PatternAngle = t.angle(h.PatternAngle)
+	h.PatternScale *= t.xscale
+	h.Elevation = t.m.Apply([]float64{0.0, 0.0, 0.0})[2]
+	h.Direction = t.normal
+}
@@ -0,0 +1,335 @@
+package entity
+
+import (
+	"math"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+)
+
+// Hatch edge type (code 72)
+const (
+	HATCH_EDGE_LINE    = 1
+	HATCH_EDGE_ARC     = 2
+	HATCH_EDGE_ELLIPSE = 3
+	HATCH_EDGE_SPLINE  = 4
+)
+
+// HatchEdge represents an edge of Hatch boundary path.
+// Coordinates are 2D in OCS of Hatch.
+type HatchEdge interface {
+	EdgeType() int
+	Format(format.Formatter)
+	Points(tolerance float64) [][]float64
+	CloneEdge() HatchEdge
+	transform(t *ocsTransform) HatchEdge
+}
+
+// LineEdge represents a line edge.
+type LineEdge struct {
+	Start []float64 // 10, 20
+	End   []float64 // 11, 21
+}
+
+// ArcEdge represents a circular arc edge.
+// If CounterClockwise is false, the arc runs clockwise from -StartAngle to -EndAngle
+// as AutoCAD stores clockwise edges.
+type ArcEdge struct {
+	Center           []float64 // 10, 20
+	Radius           float64   // 40
+	StartAngle       float64   // 50 (Degree)
+	EndAngle         float64   // 51 (Degree)
+	CounterClockwise bool      // 73
+}
+
+// EllipseEdge represents an elliptic arc edge.
+// StartAngle and EndAngle are parameters in Degree, and clockwise edges
+// are stored in the same way as ArcEdge.
+type EllipseEdge struct {
+	Center           []float64 // 10, 20
+	MajorAxis        []float64 // 11, 21: endpoint of major axis relative to Center
+	Ratio            float64   // 40
+	StartAngle       float64   // 50 (Degree)
+	EndAngle         float64   // 51 (Degree)
+	CounterClockwise bool      // 73
+}
+
+// SplineEdge represents a spline edge.
+type SplineEdge struct {
+	Degree       int         // 94
+	Rational     bool        // 73
+	Periodic     bool        // 74
+	Knots        []float64   // 95, 40
+	Controls     [][]float64 // 96, 10, 20
+	Weights      []float64   // 42
+	Fits         [][]float64 // 97, 11, 21
+	StartTangent []float64   // 12, 22: nil if not specified
+	EndTangent   []float64   // 13, 23: nil if not specified
+}
+
+// EdgeType returns HATCH_EDGE_LINE.
+func (e *LineEdge) EdgeType() int {
+	return HATCH_EDGE_LINE
+}
+
+// Format writes data to formatter.
+func (e *LineEdge) Format(f format.Formatter) {
+	f.WriteInt(72, HATCH_EDGE_LINE)
+	f.WriteFloat(10, e.Start[0])
+	f.WriteFloat(20, e.Start[1])
+	f.WriteFloat(11, e.End[0])
+	f.WriteFloat(21, e.End[1])
+}
+
+// Points returns start and end point.
+func (e *LineEdge) Points(tolerance float64) [][]float64 {
+	return [][]float64{{e.Start[0], e.Start[1]}, {e.End[0], e.End[1]}}
+}
+
+// CloneEdge returns a copy of LineEdge.
+func (e *LineEdge) CloneEdge() HatchEdge {
+	return &LineEdge{Start: copyPoint(e.Start), End: copyPoint(e.End)}
+}
+
+func (e *LineEdge) transform(t *ocsTransform) HatchEdge {
+	e.Start = apply2D(t.m, e.Start)
+	e.End = apply2D(t.m, e.End)
+	return e
+}
+
+// EdgeType returns HATCH_EDGE_ARC.
+func (e *ArcEdge) EdgeType() int {
+	return HATCH_EDGE_ARC
+}
+
+// Format writes data to formatter.
+func (e *ArcEdge) Format(f format.Formatter) {
+	f.WriteInt(72, HATCH_EDGE_ARC)
+	f.WriteFloat(10, e.Center[0])
+	f.WriteFloat(20, e.Center[1])
+	f.WriteFloat(40, e.Radius)
+	f.WriteFloat(50, e.StartAngle)
+	f.WriteFloat(51, e.EndAngle)
+	f.WriteInt(73, boolInt(e.CounterClockwise))
+}
+
+// Points tessellates the arc within tolerance.
+func (e *ArcEdge) Points(tolerance float64) [][]float64 {
+	start, sweep := edgeSweep(e.StartAngle, e.EndAngle, e.CounterClockwise)
+	return geometry.ArcPoints(e.Center, e.Radius, start, sweep, tolerance)
+}
+
+// CloneEdge returns a copy of ArcEdge.
+func (e *ArcEdge) CloneEdge() HatchEdge {
+	c := *e
+	c.Center = copyPoint(e.Center)
+	return &c
+}
+
+// transform converts the arc into an elliptic arc if t does not scale uniformly.
+func (e *ArcEdge) transform(t *ocsTransform) HatchEdge {
+	if math.Abs(t.xscale-t.yscale) > 1e-9*math.Max(t.xscale, t.yscale) {
+		el := &EllipseEdge{
+			Center:           e.Center,
+			MajorAxis:        []float64{e.Radius, 0.0},
+			Ratio:            1.0,
+			StartAngle:       e.StartAngle,
+			EndAngle:         e.EndAngle,
+			CounterClockwise: e.CounterClockwise,
+		}
+		return el.transform(t)
+	}
+	e.Center = apply2D(t.m, e.Center)
+	e.Radius *= t.xscale
+	if e.CounterClockwise {
+		e.StartAngle = t.angle(e.StartAngle)
+		e.EndAngle = t.angle(e.EndAngle)
+	} else {
+		e.StartAngle = -t.angle(-e.StartAngle)
+		e.EndAngle = -t.angle(-e.EndAngle)
+	}
+	return e
+}
+
+// EdgeType returns HATCH_EDGE_ELLIPSE.
+func (e *EllipseEdge) EdgeType() int {
+	return HATCH_EDGE_ELLIPSE
+}
+
+// Format writes data to formatter.
+func (e *EllipseEdge) Format(f format.Formatter) {
+	f.WriteInt(72, HATCH_EDGE_ELLIPSE)
+	f.WriteFloat(10, e.Center[0])
+	f.WriteFloat(20, e.Center[1])
+	f.WriteFloat(11, e.MajorAxis[0])
+	f.WriteFloat(21, e.MajorAxis[1])
+	f.WriteFloat(40, e.Ratio)
+	f.WriteFloat(50, e.StartAngle)
+	f.WriteFloat(51, e.EndAngle)
+	f.WriteInt(73, boolInt(e.CounterClockwise))
+}
+
+// minorAxis returns the minor semi-axis, which is the major axis rotated by 90 degrees.
+func (e *EllipseEdge) minorAxis() []float64 {
+	return []float64{-e.MajorAxis[1] * e.Ratio, e.MajorAxis[0] * e.Ratio}
+}
+
+// Points tessellates the elliptic arc within tolerance.
+func (e *EllipseEdge) Points(tolerance float64) [][]float64 {
+	start, sweep := edgeSweep(e.StartAngle, e.EndAngle, e.CounterClockwise)
+	r := math.Hypot(e.MajorAxis[0], e.MajorAxis[1])
+	// tessellate on the circle of major radius, then compress along minor axis
+	ps := geometry.ArcPoints([]float64{0.0, 0.0}, r, start, sweep, tolerance)
+	b := e.minorAxis()
+	for i, p := range ps {
+		c, s := p[0]/r, p[1]/r
+		ps[i] = []float64{
+			e.Center[0] + c*e.MajorAxis[0] + s*b[0],
+			e.Center[1] + c*e.MajorAxis[1] + s*b[1],
+		}
+	}
+	return ps
+}
+
+// CloneEdge returns a copy of EllipseEdge.
+func (e *EllipseEdge) CloneEdge() HatchEdge {
+	c := *e
+	c.Center = copyPoint(e.Center)
+	c.MajorAxis = copyPoint(e.MajorAxis)
+	return &c
+}
+
+func (e *EllipseEdge) transform(t *ocsTransform) HatchEdge {
+	b := e.minorAxis()
+	major, minor, t0 := principalAxes(
+		t.m.ApplyVector([]float64{e.MajorAxis[0], e.MajorAxis[1], 0.0}),
+		t.m.ApplyVector([]float64{b[0], b[1], 0.0}),
+	)
+	e.Center = apply2D(t.m, e.Center)
+	e.MajorAxis = major[:2]
+	if l := geometry.Length(major); l > 0.0 {
+		e.Ratio = geometry.Length(minor) / l
+	}
+	d := t0 * 180.0 / math.Pi
+	if !e.CounterClockwise {
+		d = -d
+	}
+	e.StartAngle -= d
+	e.EndAngle -= d
+	return e
+}
+
+// EdgeType returns HATCH_EDGE_SPLINE.
+func (e *SplineEdge) EdgeType() int {
+	return HATCH_EDGE_SPLINE
+}
+
+// Format writes data to formatter.
+func (e *SplineEdge) Format(f format.Formatter) {
+	f.WriteInt(72, HATCH_EDGE_SPLINE)
+	f.WriteInt(94, e.Degree)
+	f.WriteInt(73, boolInt(e.Rational))
+	f.WriteInt(74, boolInt(e.Periodic))
+	f.WriteInt(95, len(e.Knots))
+	f.WriteInt(96, len(e.Controls))
+	for _, k := range e.Knots {
+		f.WriteFloat(40, k)
+	}
+	for i, c := range e.Controls {
+		f.WriteFloat(10, c[0])
+		f.WriteFloat(20, c[1])
+		if e.Rational && i < len(e.Weights) {
+			f.WriteFloat(42, e.Weights[i])
+		}
+	}
+	f.WriteInt(97, len(e.Fits))
+	for _, p := range e.Fits {
+		f.WriteFloat(11, p[0])
+		f.WriteFloat(21, p[1])
+	}
+	if e.StartTangent != nil {
+		f.WriteFloat(12, e.StartTangent[0])
+		f.WriteFloat(22, e.StartTangent[1])
+	}
+	if e.EndTangent != nil {
+		f.WriteFloat(13, e.EndTangent[0])
+		f.WriteFloat(23, e.EndTangent[1])
+	}
+}
+
+// Points tessellates the spline within tolerance.
+// If the spline has no valid control points, fit points are returned.
+func (e *SplineEdge) Points(tolerance float64) [][]float64 {
+	cs := make([][]float64, len(e.Controls))
+	for i, c := range e.Controls {
+		cs[i] = []float64{c[0], c[1], 0.0}
+	}
+	var ws []float64
+	if e.Rational {
+		ws = e.Weights
+	}
+	n, err := geometry.NewNURBS(e.Degree, e.Knots, cs, ws)
+	if err != nil {
+		return copyPoints(e.Fits)
+	}
+	ps := n.Tessellate(tolerance)
+	for i, p := range ps {
+		ps[i] = p[:2]
+	}
+	return ps
+}
+
+// CloneEdge returns a copy of SplineEdge.
+func (e *SplineEdge) CloneEdge() HatchEdge {
+	c := *e
+	c.Knots = copyPoint(e.Knots)
+	c.Controls = copyPoints(e.Controls)
+	c.Weights = copyPoint(e.Weights)
+	c.Fits = copyPoints(e.Fits)
+	c.StartTangent = copyPoint(e.StartTangent)
+	c.EndTangent = copyPoint(e.EndTangent)
+	return &c
+}
+
+func (e *SplineEdge) transform(t *ocsTransform) HatchEdge {
+	for i, c := range e.Controls {
+		e.Controls[i] = apply2D(t.m, c)
+	}
+	for i, c := range e.Fits {
+		e.Fits[i] = apply2D(t.m, c)
+	}
+	if e.StartTangent != nil {
+		e.StartTangent = t.m.ApplyVector([]float64{e.StartTangent[0], e.StartTangent[1], 0.0})[:2]
+	}
+	if e.EndTangent != nil {
+		e.EndTangent = t.m.ApplyVector([]float64{e.EndTangent[0], e.EndTangent[1], 0.0})[:2]
+	}
+	return e
+}
+
+// edgeSweep returns the start angle and signed sweep (Radian) of arc and elliptic arc edges.
+func edgeSweep(start, end float64, ccw bool) (float64, float64) {
+	sweep := math.Mod(end-start, 360.0)
+	if sweep <= 0.0 {
+		sweep += 360.0
+	}
+	start *= math.Pi / 180.0
+	sweep *= math.Pi / 180.0
+	if !ccw {
+		return -start, -sweep
+	}
+	return start, sweep
+}
+
+// apply2D transforms 2D point p on XY plane and returns 2D point.
+func apply2D(m geometry.Matrix, p []float64) []float64 {
+	return m.Apply([]float64{p[0], p[1], 0.0})[:2]
+}
+
+// boolInt converts bool to 0 or 1.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
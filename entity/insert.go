@@ -0,0 +1,233 @@
+package entity
+
+import (
+	"math"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+)
+
+// Insert represents INSERT Entity (block reference).
+// If Columns or Rows is greater than 1, it is written as MINSERT.
+type Insert struct {
+	*entity
+	BlockName     string     // 2
+	Coord         []float64  // 10, 20, 30
+	Scale         []float64  // 41, 42, 43
+	Rotation      float64    // 50 (Degree)
+	Columns       int        // 70
+	Rows          int        // 71
+	ColumnSpacing float64    // 44
+	RowSpacing    float64    // 45
+	Direction     []float64  // 210, 220, 230
+	Attributes    []*Unknown // ATTRIB entities following INSERT
+	endhandle     int
+}
+
+// IsEntity is for Entity interface.
+func (i *Insert) IsEntity() bool {
+	return true
+}
+
+// NewInsert creates a new Insert referencing the named block.
+func NewInsert(name string) *Insert {
+	i := &Insert{
+		entity:        NewEntity(INSERT),
+		BlockName:     name,
+		Coord:         []float64{0.0, 0.0, 0.0},
+		Scale:         []float64{1.0, 1.0, 1.0},
+		Rotation:      0.0,
+		Columns:       1,
+		Rows:          1,
+		ColumnSpacing: 0.0,
+		RowSpacing:    0.0,
+		Direction:     []float64{0.0, 0.0, 1.0},
+	}
+	return i
+}
+
+// IsArray reports whether Insert has more than one column or row (MINSERT).
+func (i *Insert) IsArray() bool {
+	return i.Columns > 1 || i.Rows > 1
+}
+
+// Format writes data to formatter.
+func (i *Insert) Format(f format.Formatter) {
+	i.entity.Format(f)
+	if i.IsArray() {
+		f.WriteString(100, "AcDbMInsertBlock")
+	} else {
+		f.WriteString(100, "AcDbBlockReference")
+	}
+	if len(i.Attributes) > 0 {
+		f.WriteInt(66, 1)
+	}
+	f.WriteString(2, i.BlockName)
+	for j := 0; j < 3; j++ {
+		f.WriteFloat((j+1)*10, i.Coord[j])
+	}
+	for j := 0; j < 3; j++ {
+		if i.Scale[j] != 1.0 {
+			f.WriteFloat(41+j, i.Scale[j])
+		}
+	}
+	if i.Rotation != 0.0 {
+		f.WriteFloat(50, i.Rotation)
+	}
+	if i.IsArray() {
+		f.WriteInt(70, i.Columns)
+		f.WriteInt(71, i.Rows)
+		f.WriteFloat(44, i.ColumnSpacing)
+		f.WriteFloat(45, i.RowSpacing)
+	}
+	if i.Direction[0] != 0.0 || i.Direction[1] != 0.0 || i.Direction[2] != 1.0 {
+		for j := 0; j < 3; j++ {
+			f.WriteFloat(200+(j+1)*10, i.Direction[j])
+		}
+	}
+	i.FormatXData(f)
+	if len(i.Attributes) > 0 {
+		for _, a := range i.Attributes {
+			a.Format(f)
+		}
+		f.WriteString(0, "SEQEND")
+		f.WriteHex(5, i.endhandle)
+		f.WriteString(100, "AcDbEntity")
+		f.WriteString(8, i.Layer().Name())
+	}
+}
+
+// AddAttribute appends ATTRIB a to Insert, which becomes the owner of a.
+func (i *Insert) AddAttribute(a *Unknown) {
+	a.SetOwner(i)
+	i.Attributes = append(i.Attributes, a)
+}
+
+// SetHandle sets handles to itself, its attributes and SEQEND if it has attributes.
+func (i *Insert) SetHandle(h *int) {
+	i.entity.SetHandle(h)
+	if len(i.Attributes) == 0 {
+		return
+	}
+	for _, a := range i.Attributes {
+		a.SetHandle(h)
+	}
+	i.SetEndHandle(h)
+}
+
+// SetEndHandle sets a handle to SEQEND only.
+func (i *Insert) SetEndHandle(h *int) {
+	i.endhandle = *h
+	(*h)++
+}
+
+// String outputs data using default formatter.
+func (i *Insert) String() string {
+	f := format.NewASCII()
+	return i.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (i *Insert) FormatString(f format.Formatter) string {
+	i.Format(f)
+	return f.Output()
+}
+
+// CurrentDirection returns extrusion direction.
+func (i *Insert) CurrentDirection() []float64 {
+	return i.Direction
+}
+
+// SetDirection sets new extrusion direction.
+func (i *Insert) SetDirection(d []float64) {
+	i.Direction = d
+}
+
+// CurrentCoord returns insertion point coord.
+func (i *Insert) CurrentCoord() []float64 {
+	return i.Coord
+}
+
+// SetCoord sets new insertion point coord.
+func (i *Insert) SetCoord(co []float64) {
+	i.Coord = co
+}
+
+// BBox returns the insertion point,
+// as the extent of the referenced block is unknown to Insert itself.
+func (i *Insert) BBox() ([]float64, []float64) {
+	return []float64{i.Coord[0], i.Coord[1], i.Coord[2]}, []float64{i.Coord[0], i.Coord[1], i.Coord[2]}
+}
+
+// Clone returns a copy of Insert.
+func (i *Insert) Clone() Entity {
+	c := *i
+	c.entity = i.entity.clone()
+	c.Coord = copyPoint(i.Coord)
+	c.Scale = copyPoint(i.Scale)
+	c.Direction = copyPoint(i.Direction)
+	c.Attributes = nil
+	c.endhandle = 0
+	for _, a := range i.Attributes {
+		c.AddAttribute(a.Clone().(*Unknown))
+	}
+	return &c
+}
+
+// axes returns the X, Y and Z axes of the block, rotated by Rotation,
+// transformed by t into new OCS.
+func (i *Insert) axes(t *ocsTransform) ([]float64, []float64, []float64) {
+	s, c := math.Sincos(i.Rotation * math.Pi / 180.0)
+	x := t.m.ApplyVector([]float64{c, s, 0.0})
+	y := t.m.ApplyVector([]float64{-s, c, 0.0})
+	z := t.m.ApplyVector([]float64{0.0, 0.0, 1.0})
+	return x, y, z
+}
+
+// sheared reports whether m shears the block of Insert,
+// which cannot be represented by rotation and scale of Insert.
+func (i *Insert) sheared(m geometry.Matrix) bool {
+	x, y, z := i.axes(newOCSTransform(m, i.Direction))
+	tol := 1e-9 * geometry.Length(x) * geometry.Length(y)
+	return math.Abs(geometry.Dot(x, y)) > tol || math.Hypot(z[0], z[1]) > 1e-9*geometry.Length(z)
+}
+
+// Transform transforms Insert and its attributes by m.
+// Scale and spacing follow the axes of the block rotated by Rotation,
+// so the result is exact unless m shears the block, which TransformEntity reports as an error.
+func (i *Insert) Transform(m geometry.Matrix) {
+	t := newOCSTransform(m, i.Direction)
+	x, y, _ := i.axes(t)
+	xscale, yscale := math.Hypot(x[0], x[1]), math.Hypot(y[0], y[1])
+	i.Coord = t.m.Apply(i.Coord)
+	if xscale != 0.0 {
+		i.Rotation = math.Atan2(x[1], x[0]) * 180.0 / math.Pi
+	}
+	i.Scale[0] *= xscale
+	i.Scale[1] *= yscale
+	i.Scale[2] *= t.zscale
+	i.ColumnSpacing *= xscale
+	i.RowSpacing *= yscale
+	i.Direction = t.normal
+	for _, a := range i.Attributes {
+		a.Transform(m)
+	}
+}
+
+// Matrix returns the transformation from block coordinates into WCS
+// for given column and row of the array, where base is the base point of the block.
+func (i *Insert) Matrix(base []float64, column, row int) geometry.Matrix {
+	m := geometry.OCS(i.Direction)
+	m = m.Multiply(geometry.Translation(i.Coord[0], i.Coord[1], i.Coord[2]))
+	m = m.Multiply(geometry.RotationZ(i.Rotation * math.Pi / 180.0))
+	m = m.Multiply(geometry.Translation(float64(column)*i.ColumnSpacing, float64(row)*i.RowSpacing, 0.0))
+	m = m.Multiply(geometry.Scaling(i.Scale[0], i.Scale[1], i.Scale[2]))
+	return m.Multiply(geometry.Translation(-base[0], -base[1], -base[2]))
+}
+
+// Place returns a copy of e, an entity of the block referenced by Insert,
+// placed at given column and row of the array, where base is the base point of the block.
+// Entities are converted as TransformEntity does, and its error is returned.
+func (i *Insert) Place(e Entity, base []float64, column, row int) (Entity, error) {
+	return TransformEntity(e.Clone(), i.Matrix(base, column, row))
+}
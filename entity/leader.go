@@ -0,0 +1,165 @@
+package entity
+
+import (
+	"math"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/table"
+)
+
+// Leader path type (code 72)
+const (
+	LEADER_STRAIGHT = 0
+	LEADER_SPLINE   = 1
+)
+
+// Leader annotation type (code 73)
+const (
+	LEADER_MTEXT     = 0
+	LEADER_TOLERANCE = 1
+	LEADER_INSERT    = 2
+	LEADER_NONE      = 3
+)
+
+// Leader represents LEADER Entity.
+type Leader struct {
+	*entity
+	DimStyle            *table.DimStyle // 3
+	Arrow               bool            // 71
+	PathType            int             // 72
+	AnnotationType      int             // 73
+	HooklineDirection   bool            // 74: hookline is in the same direction as HorizontalDirection
+	Hookline            bool            // 75
+	TextHeight          float64         // 40
+	TextWidth           float64         // 41
+	Vertices            [][]float64     // 76, 10, 20, 30 (WCS)
+	Color               int             // 77: 256 is BYLAYER
+	Annotation          handle.Handler  // 340: MTEXT, TOLERANCE or INSERT
+	Direction           []float64       // 210, 220, 230
+	HorizontalDirection []float64       // 211, 221, 231
+	BlockOffset         []float64       // 212, 222, 232
+	AnnotationOffset    []float64       // 213, 223, 233
+}
+
+// IsEntity is for Entity interface.
+func (l *Leader) IsEntity() bool {
+	return true
+}
+
+// NewLeader creates a new Leader with an arrow and no annotation.
+func NewLeader() *Leader {
+	l := &Leader{
+		entity:              NewEntity(LEADER),
+		DimStyle:            table.NewDimStyle("Standard"),
+		Arrow:               true,
+		PathType:            LEADER_STRAIGHT,
+		AnnotationType:      LEADER_NONE,
+		Vertices:            make([][]float64, 0),
+		Color:               256,
+		Direction:           []float64{0.0, 0.0, 1.0},
+		HorizontalDirection: []float64{1.0, 0.0, 0.0},
+		BlockOffset:         []float64{0.0, 0.0, 0.0},
+		AnnotationOffset:    []float64{0.0, 0.0, 0.0},
+	}
+	return l
+}
+
+// Format writes data to formatter.
+func (l *Leader) Format(f format.Formatter) {
+	l.entity.Format(f)
+	f.WriteString(100, "AcDbLeader")
+	f.WriteString(3, l.DimStyle.Name())
+	f.WriteInt(71, boolInt(l.Arrow))
+	f.WriteInt(72, l.PathType)
+	f.WriteInt(73, l.AnnotationType)
+	f.WriteInt(74, boolInt(l.HooklineDirection))
+	f.WriteInt(75, boolInt(l.Hookline))
+	f.WriteFloat(40, l.TextHeight)
+	f.WriteFloat(41, l.TextWidth)
+	f.WriteInt(76, len(l.Vertices))
+	for _, v := range l.Vertices {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat((i+1)*10, v[i])
+		}
+	}
+	if l.Color != 256 {
+		f.WriteInt(77, l.Color)
+	}
+	if l.Annotation != nil {
+		f.WriteHex(340, l.Annotation.Handle())
+	}
+	writeVector(f, 210, l.Direction)
+	writeVector(f, 211, l.HorizontalDirection)
+	writeVector(f, 212, l.BlockOffset)
+	writeVector(f, 213, l.AnnotationOffset)
+	l.FormatXData(f)
+}
+
+// String outputs data using default formatter.
+func (l *Leader) String() string {
+	f := format.NewASCII()
+	return l.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (l *Leader) FormatString(f format.Formatter) string {
+	l.Format(f)
+	return f.Output()
+}
+
+// AddVertex appends a vertex (x, y, z).
+func (l *Leader) AddVertex(x, y, z float64) {
+	l.Vertices = append(l.Vertices, []float64{x, y, z})
+}
+
+// BBox returns bounding box of vertices.
+func (l *Leader) BBox() ([]float64, []float64) {
+	return pointsBBox(l.Vertices)
+}
+
+// Clone returns a copy of Leader.
+// The copy refers to the same annotation.
+func (l *Leader) Clone() Entity {
+	c := *l
+	c.entity = l.entity.clone()
+	c.Vertices = copyPoints(l.Vertices)
+	c.Direction = copyPoint(l.Direction)
+	c.HorizontalDirection = copyPoint(l.HorizontalDirection)
+	c.BlockOffset = copyPoint(l.BlockOffset)
+	c.AnnotationOffset = copyPoint(l.AnnotationOffset)
+	return &c
+}
+
+// Transform transforms vertices and directions of Leader by m.
+// The annotation is not transformed.
+func (l *Leader) Transform(m geometry.Matrix) {
+	for i, v := range l.Vertices {
+		l.Vertices[i] = m.Apply(v)
+	}
+	l.Direction = newOCSTransform(m, l.Direction).normal
+	l.HorizontalDirection = geometry.Normalize(m.ApplyVector(l.HorizontalDirection))
+	l.BlockOffset = m.ApplyVector(l.BlockOffset)
+	l.AnnotationOffset = m.ApplyVector(l.AnnotationOffset)
+}
+
+// writeVector writes 3D vector v with code, code+10 and code+20.
+func writeVector(f format.Formatter, code int, v []float64) {
+	for i := 0; i < 3; i++ {
+		f.WriteFloat(code+i*10, v[i])
+	}
+}
+
+// pointsBBox returns bounding box of 3D points.
+func pointsBBox(ps [][]float64) ([]float64, []float64) {
+	mins := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+	maxs := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range ps {
+		for i := 0; i < 3; i++ {
+			mins[i] = math.Min(mins[i], p[i])
+			maxs[i] = math.Max(maxs[i], p[i])
+		}
+	}
+	return mins, maxs
+}
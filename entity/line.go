@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 )
 
 // Line represents LINE Entity.
@@ -38,6 +39,7 @@ func (l *Line) Format(f format.Formatter) {
 	for i := 0; i < 3; i++ {
 		f.WriteFloat((i+1)*10+1, l.End[i])
 	}
+	l.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -99,3 +101,18 @@ func (l *Line) Move(x, y, z float64) {
 		l.End[i] += val
 	}
 }
+
+// Clone returns a copy of Line.
+func (l *Line) Clone() Entity {
+	return &Line{
+		entity: l.entity.clone(),
+		Start:  copyPoint(l.Start),
+		End:    copyPoint(l.End),
+	}
+}
+
+// Transform transforms Line by m.
+func (l *Line) Transform(m geometry.Matrix) {
+	l.Start = m.Apply(l.Start)
+	l.End = m.Apply(l.End)
+}
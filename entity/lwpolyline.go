@@ -1,15 +1,24 @@
 package entity
 
 import (
+	"math"
+
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 )
 
 // LwPolyline represents LWPOLYLINE Entity.
 type LwPolyline struct {
 	*entity
-	Num      int // 90
-	Closed   bool
-	Vertices [][]float64
+	Num           int // 90
+	Closed        bool
+	Elevation     float64     // 38
+	Thickness     float64     // 39
+	ConstantWidth float64     // 43
+	Vertices      [][]float64 // 10, 20
+	Widths        [][]float64 // 40, 41: start and end width of each vertex
+	Bulges        []float64   // 42
+	Direction     []float64   // 210, 220, 230
 }
 
 // IsEntity is for Entity interface.
@@ -20,18 +29,111 @@ func (l *LwPolyline) IsEntity() bool {
 // NewLwPolyline creates a new LwPolyline.
 func NewLwPolyline(size int) *LwPolyline {
 	vs := make([][]float64, size)
+	ws := make([][]float64, size)
 	for i := 0; i < size; i++ {
 		vs[i] = make([]float64, 2)
+		ws[i] = make([]float64, 2)
 	}
 	l := &LwPolyline{
-		entity:   NewEntity(LWPOLYLINE),
-		Num:      size,
-		Closed:   false,
-		Vertices: vs,
+		entity:    NewEntity(LWPOLYLINE),
+		Num:       size,
+		Closed:    false,
+		Vertices:  vs,
+		Widths:    ws,
+		Bulges:    make([]float64, size),
+		Direction: []float64{0.0, 0.0, 1.0},
 	}
 	return l
 }
 
+// Bulge returns the bulge of i-th vertex.
+func (l *LwPolyline) Bulge(i int) float64 {
+	if i < len(l.Bulges) {
+		return l.Bulges[i]
+	}
+	return 0.0
+}
+
+// Width returns the start and end width of i-th vertex.
+func (l *LwPolyline) Width(i int) (float64, float64) {
+	if i < len(l.Widths) && len(l.Widths[i]) >= 2 {
+		return l.Widths[i][0], l.Widths[i][1]
+	}
+	return 0.0, 0.0
+}
+
+// SetBulge sets the bulge of i-th vertex.
+func (l *LwPolyline) SetBulge(i int, bulge float64) {
+	for len(l.Bulges) <= i {
+		l.Bulges = append(l.Bulges, 0.0)
+	}
+	l.Bulges[i] = bulge
+}
+
+// SetWidth sets the start and end width of i-th vertex.
+func (l *LwPolyline) SetWidth(i int, start, end float64) {
+	for len(l.Widths) <= i {
+		l.Widths = append(l.Widths, make([]float64, 2))
+	}
+	l.Widths[i] = []float64{start, end}
+}
+
+// segments calls fn for each segment with its start and end vertex and bulge.
+func (l *LwPolyline) segments(fn func(p1, p2 []float64, bulge float64)) {
+	n := len(l.Vertices)
+	last := n - 1
+	if l.Closed {
+		last = n
+	}
+	for i := 0; i < last; i++ {
+		fn(l.Vertices[i], l.Vertices[(i+1)%n], l.Bulge(i))
+	}
+}
+
+// Points returns vertices in OCS with arc segments tessellated within tolerance.
+// If LwPolyline is closed, the first vertex is repeated at the end.
+func (l *LwPolyline) Points(tolerance float64) [][]float64 {
+	ps := make([][]float64, 0, len(l.Vertices)+1)
+	l.segments(func(p1, p2 []float64, bulge float64) {
+		ps = append(ps, geometry.BulgePoints(p1, p2, bulge, tolerance)...)
+	})
+	n := len(l.Vertices)
+	switch {
+	case n == 0:
+	case l.Closed:
+		ps = append(ps, []float64{l.Vertices[0][0], l.Vertices[0][1]})
+	default:
+		ps = append(ps, []float64{l.Vertices[n-1][0], l.Vertices[n-1][1]})
+	}
+	return ps
+}
+
+// tessellate replaces arc segments with straight segments within tolerance.
+// Widths are interpolated along the arcs.
+func (l *LwPolyline) tessellate(tolerance float64) {
+	n := len(l.Vertices)
+	vs := make([][]float64, 0, n)
+	ws := make([][]float64, 0, n)
+	for i, v := range l.Vertices {
+		sw, ew := l.Width(i)
+		b := l.Bulge(i)
+		if b == 0.0 || (!l.Closed && i == n-1) {
+			vs = append(vs, v)
+			ws = append(ws, []float64{sw, ew})
+			continue
+		}
+		ps := geometry.BulgePoints(v, l.Vertices[(i+1)%n], b, tolerance)
+		for k, p := range ps {
+			vs = append(vs, p)
+			ws = append(ws, []float64{sw + (ew-sw)*float64(k)/float64(len(ps)), sw + (ew-sw)*float64(k+1)/float64(len(ps))})
+		}
+	}
+	l.Vertices = vs
+	l.Widths = ws
+	l.Bulges = make([]float64, len(vs))
+	l.Num = len(vs)
+}
+
 // Format writes data to formatter.
 func (l *LwPolyline) Format(f format.Formatter) {
 	l.entity.Format(f)
@@ -42,11 +144,33 @@ func (l *LwPolyline) Format(f format.Formatter) {
 	} else {
 		f.WriteInt(70, 0)
 	}
+	if l.ConstantWidth != 0.0 {
+		f.WriteFloat(43, l.ConstantWidth)
+	}
+	if l.Elevation != 0.0 {
+		f.WriteFloat(38, l.Elevation)
+	}
+	if l.Thickness != 0.0 {
+		f.WriteFloat(39, l.Thickness)
+	}
 	for i := 0; i < l.Num; i++ {
 		for j := 0; j < 2; j++ {
 			f.WriteFloat((j+1)*10, l.Vertices[i][j])
 		}
+		if sw, ew := l.Width(i); sw != 0.0 || ew != 0.0 {
+			f.WriteFloat(40, sw)
+			f.WriteFloat(41, ew)
+		}
+		if b := l.Bulge(i); b != 0.0 {
+			f.WriteFloat(42, b)
+		}
 	}
+	if !isDefaultDirection(l.Direction) {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat(200+(i+1)*10, l.Direction[i])
+		}
+	}
+	l.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -66,18 +190,87 @@ func (l *LwPolyline) Close() {
 	l.Closed = true
 }
 
+// BBox returns the bounding box in WCS including arc segments.
 func (l *LwPolyline) BBox() ([]float64, []float64) {
-	mins := make([]float64, 3)
-	maxs := make([]float64, 3)
-	for _, p := range l.Vertices {
-		for i := 0; i < len(p); i++ {
-			if p[i] < mins[i] {
-				mins[i] = p[i]
+	mins := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+	maxs := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	m := geometry.OCS(l.Direction)
+	add := func(x, y float64) {
+		c := m.Apply([]float64{x, y, l.Elevation})
+		for i := 0; i < 3; i++ {
+			mins[i] = math.Min(mins[i], c[i])
+			maxs[i] = math.Max(maxs[i], c[i])
+		}
+	}
+	for _, v := range l.Vertices {
+		add(v[0], v[1])
+	}
+	l.segments(func(p1, p2 []float64, bulge float64) {
+		center, radius, start, sweep := geometry.BulgeArc(p1, p2, bulge)
+		if center == nil {
+			return
+		}
+		// WCS coordinate i of the arc is extreme where the tangent is perpendicular to axis i
+		for i := 0; i < 3; i++ {
+			a := math.Atan2(m[i][1], m[i][0])
+			for _, e := range []float64{a, a + math.Pi} {
+				if geometry.AngleInSweep(e, start, sweep) {
+					add(center[0]+radius*math.Cos(e), center[1]+radius*math.Sin(e))
+				}
 			}
-			if p[i] > maxs[i] {
-				maxs[i] = p[i]
+		}
+	})
+	return mins, maxs
+}
+
+// Clone returns a copy of LwPolyline.
+func (l *LwPolyline) Clone() Entity {
+	c := *l
+	c.entity = l.entity.clone()
+	c.Vertices = copyPoints(l.Vertices)
+	c.Widths = copyPoints(l.Widths)
+	c.Bulges = copyPoint(l.Bulges)
+	c.Direction = copyPoint(l.Direction)
+	return &c
+}
+
+// Transform transforms LwPolyline by m.
+// Vertices are kept in OCS of the transformed extrusion direction.
+// Bulges keep their sign, since a mirrored polyline gets the flipped extrusion direction.
+// Arc segments remain circular only if m scales uniformly in the plane,
+// so use TransformEntity for other matrices.
+// Widths are scaled across each segment, and a constant width becomes the width of
+// each vertex if m scales non-uniformly.
+func (l *LwPolyline) Transform(m geometry.Matrix) {
+	t := newOCSTransform(m, l.Direction)
+	n := len(l.Vertices)
+	if l.ConstantWidth != 0.0 && !t.conformal() {
+		variable := false
+		for i := 0; i < n; i++ {
+			if sw, ew := l.Width(i); sw != 0.0 || ew != 0.0 {
+				variable = true
 			}
 		}
+		for i := 0; i < n && !variable; i++ {
+			l.SetWidth(i, l.ConstantWidth, l.ConstantWidth)
+		}
+		l.ConstantWidth = 0.0
 	}
-	return mins, maxs
+	for i, w := range l.Widths {
+		if i >= n {
+			break
+		}
+		s := t.widthScale(l.Vertices[i], l.Vertices[(i+1)%n])
+		for j := range w {
+			w[j] *= s
+		}
+	}
+	for i, v := range l.Vertices {
+		p := t.m.Apply([]float64{v[0], v[1], l.Elevation})
+		l.Vertices[i] = p[:2]
+	}
+	l.Elevation = t.m.Apply([]float64{0.0, 0.0, l.Elevation})[2]
+	l.ConstantWidth *= t.xscale
+	l.Thickness *= t.zscale
+	l.Direction = t.normal
 }
@@ -0,0 +1,654 @@
+package entity
+
+import (
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/handle"
+)
+
+// MLeader leader type (code 170)
+const (
+	MLEADER_INVISIBLE = 0
+	MLEADER_STRAIGHT  = 1
+	MLEADER_SPLINE    = 2
+)
+
+// MLeader content type (code 172)
+const (
+	MLEADER_CONTENT_NONE      = 0
+	MLEADER_CONTENT_BLOCK     = 1
+	MLEADER_CONTENT_MTEXT     = 2
+	MLEADER_CONTENT_TOLERANCE = 3
+)
+
+// Raw color values of MLeader (codes 90-93)
+const (
+	MLEADER_COLOR_BYLAYER = -1073741824
+	MLEADER_COLOR_BYBLOCK = -1056964608
+)
+
+// MLeader represents MULTILEADER Entity.
+// References to styles and blocks are kept as handles.
+type MLeader struct {
+	*entity
+	Version                 int                // 270
+	Context                 *MLeaderContext    // 300 CONTEXT_DATA{
+	Style                   handle.Handler     // 340: MLEADERSTYLE
+	PropertyOverride        int                // 90
+	LeaderType              int                // 170
+	LineColor               int                // 91
+	LineType                handle.Handler     // 341
+	LineWeight              int                // 171
+	Landing                 bool               // 290
+	Dogleg                  bool               // 291
+	DoglegLength            float64            // 41
+	Arrowhead               handle.Handler     // 342: nil is the default arrow
+	ArrowSize               float64            // 42
+	ContentType             int                // 172
+	TextStyle               handle.Handler     // 343
+	TextLeftAttachment      int                // 173
+	TextRightAttachment     int                // 95
+	TextAngleType           int                // 174
+	TextAlignment           int                // 175
+	TextColor               int                // 92
+	TextFrame               bool               // 292
+	Block                   handle.Handler     // 344: BLOCK_RECORD
+	BlockColor              int                // 93
+	BlockScale              []float64          // 10, 20, 30
+	BlockRotation           float64            // 43 (Radian)
+	BlockConnection         int                // 176
+	Annotative              bool               // 293
+	Arrowheads              []MLeaderArrowhead // 94, 345
+	Attributes              []MLeaderAttribute // 330, 177, 44, 302
+	TextDirectionNegative   bool               // 294
+	TextIPEAlign            int                // 178
+	TextAttachmentPoint     int                // 179
+	Scale                   float64            // 45
+	TextAttachmentDirection int                // 271
+	TextBottomAttachment    int                // 272
+	TextTopAttachment       int                // 273
+	LeaderExtendToText      bool               // 295
+}
+
+// MLeaderArrowhead represents an arrowhead override of a leader line.
+type MLeaderArrowhead struct {
+	Index     int            // 94
+	Arrowhead handle.Handler // 345
+}
+
+// MLeaderAttribute represents an attribute value of block content.
+type MLeaderAttribute struct {
+	Attdef handle.Handler // 330: ATTDEF
+	Index  int            // 177
+	Width  float64        // 44
+	Text   string         // 302
+}
+
+// MLeaderContext represents the context data of MLeader,
+// which holds the actual geometry of leaders and content.
+type MLeaderContext struct {
+	Scale               float64          // 40
+	BasePoint           []float64        // 10, 20, 30
+	TextHeight          float64          // 41
+	ArrowSize           float64          // 140
+	LandingGap          float64          // 145
+	LeftAttachment      int              // 174
+	RightAttachment     int              // 175
+	TextAlign           int              // 176
+	BlockConnection     int              // 177
+	Text                *MLeaderText     // 290: nil if MLeader has no MTEXT
+	Block               *MLeaderBlock    // 296: nil if MLeader has no block
+	PlaneOrigin         []float64        // 110, 120, 130
+	PlaneXAxis          []float64        // 111, 121, 131
+	PlaneYAxis          []float64        // 112, 122, 132
+	PlaneNormalReversed bool             // 297
+	Branches            []*MLeaderBranch // 302 LEADER{
+	TopAttachment       int              // 272
+	BottomAttachment    int              // 273
+}
+
+// MLeaderText represents MTEXT content of MLeader.
+type MLeaderText struct {
+	Value              string         // 304
+	Direction          []float64      // 11, 21, 31: normal
+	Style              handle.Handler // 340
+	Location           []float64      // 12, 22, 32
+	XAxis              []float64      // 13, 23, 33
+	Rotation           float64        // 42 (Radian)
+	Width              float64        // 43
+	DefinedWidth       float64        // 44
+	DefinedHeight      float64        // 45
+	LineSpacingStyle   int            // 170
+	LineSpacingFactor  float64        // 141
+	Alignment          int            // 171
+	FlowDirection      int            // 172
+	Color              int            // 90
+	BackgroundColor    int            // 91
+	BackgroundScale    float64        // 142 before 173
+	BackgroundTransp   int            // 92
+	BackgroundWindow   bool           // 291
+	BackgroundFill     bool           // 292
+	ColumnType         int            // 173
+	AutoHeight         bool           // 293
+	ColumnWidth        float64        // 142 after 173
+	ColumnGutter       float64        // 143
+	ColumnFlowReversed bool           // 294
+	ColumnSizes        []float64      // 144
+	WordBreak          bool           // 295
+}
+
+// MLeaderBlock represents block content of MLeader.
+type MLeaderBlock struct {
+	Block     handle.Handler // 341: BLOCK_RECORD
+	Direction []float64      // 14, 24, 34: normal
+	Location  []float64      // 15, 25, 35
+	Scale     []float64      // 16, 26, 36
+	Rotation  float64        // 46 (Radian)
+	Color     int            // 93
+	Matrix    []float64      // 47: 16 values
+}
+
+// MLeaderBranch represents a leader of MLeader, which consists of leader lines
+// ending at the same landing.
+type MLeaderBranch struct {
+	HasLastPoint        bool           // 290
+	HasDogleg           bool           // 291
+	LastPoint           []float64      // 10, 20, 30
+	DoglegVector        []float64      // 11, 21, 31
+	Breaks              []MLeaderBreak // 12, 13
+	Index               int            // 90
+	DoglegLength        float64        // 40
+	Lines               []*MLeaderLine // 304 LEADER_LINE{
+	AttachmentDirection int            // 271
+}
+
+// MLeaderLine represents a leader line of MLeader.
+// The last point of the line is LastPoint of the branch.
+type MLeaderLine struct {
+	Vertices [][]float64    // 10, 20, 30
+	Breaks   []MLeaderBreak // 90, 11, 12
+	Index    int            // 91
+}
+
+// MLeaderBreak represents a break of dogleg or leader line.
+// Index is used by leader lines only.
+type MLeaderBreak struct {
+	Index int       // 90
+	Start []float64 // 11 or 12
+	End   []float64 // 12 or 13
+}
+
+// IsEntity is for Entity interface.
+func (l *MLeader) IsEntity() bool {
+	return true
+}
+
+// NewMLeader creates a new MLeader with empty MTEXT content and no leaders.
+func NewMLeader() *MLeader {
+	l := &MLeader{
+		entity:               NewEntity(MULTILEADER),
+		Version:              2,
+		Context:              NewMLeaderContext(),
+		LeaderType:           MLEADER_STRAIGHT,
+		LineColor:            MLEADER_COLOR_BYBLOCK,
+		LineWeight:           -2,
+		Landing:              true,
+		Dogleg:               true,
+		DoglegLength:         8.0,
+		ArrowSize:            4.0,
+		ContentType:          MLEADER_CONTENT_MTEXT,
+		TextLeftAttachment:   1,
+		TextRightAttachment:  1,
+		TextAngleType:        1,
+		TextColor:            MLEADER_COLOR_BYBLOCK,
+		BlockColor:           MLEADER_COLOR_BYBLOCK,
+		BlockScale:           []float64{1.0, 1.0, 1.0},
+		TextAttachmentPoint:  1,
+		Scale:                1.0,
+		TextBottomAttachment: 9,
+		TextTopAttachment:    9,
+	}
+	l.Context.Text = NewMLeaderText("")
+	return l
+}
+
+// NewMLeaderContext creates a new MLeaderContext without content and leaders.
+func NewMLeaderContext() *MLeaderContext {
+	return &MLeaderContext{
+		Scale:            1.0,
+		BasePoint:        []float64{0.0, 0.0, 0.0},
+		TextHeight:       4.0,
+		ArrowSize:        4.0,
+		LandingGap:       2.0,
+		LeftAttachment:   1,
+		RightAttachment:  1,
+		PlaneOrigin:      []float64{0.0, 0.0, 0.0},
+		PlaneXAxis:       []float64{1.0, 0.0, 0.0},
+		PlaneYAxis:       []float64{0.0, 1.0, 0.0},
+		Branches:         make([]*MLeaderBranch, 0),
+		TopAttachment:    9,
+		BottomAttachment: 9,
+	}
+}
+
+// NewMLeaderText creates a new MLeaderText with value.
+func NewMLeaderText(value string) *MLeaderText {
+	return &MLeaderText{
+		Value:             value,
+		Direction:         []float64{0.0, 0.0, 1.0},
+		Location:          []float64{0.0, 0.0, 0.0},
+		XAxis:             []float64{1.0, 0.0, 0.0},
+		LineSpacingStyle:  1,
+		LineSpacingFactor: 1.0,
+		Alignment:         1,
+		FlowDirection:     1,
+		Color:             MLEADER_COLOR_BYBLOCK,
+		BackgroundColor:   -939524096,
+		BackgroundScale:   1.5,
+		WordBreak:         true,
+	}
+}
+
+// NewMLeaderBlock creates a new MLeaderBlock referring to block.
+func NewMLeaderBlock(block handle.Handler) *MLeaderBlock {
+	return &MLeaderBlock{
+		Block:     block,
+		Direction: []float64{0.0, 0.0, 1.0},
+		Location:  []float64{0.0, 0.0, 0.0},
+		Scale:     []float64{1.0, 1.0, 1.0},
+		Color:     MLEADER_COLOR_BYBLOCK,
+		Matrix:    []float64{1.0, 0.0, 0.0, 0.0, 0.0, 1.0, 0.0, 0.0, 0.0, 0.0, 1.0, 0.0, 0.0, 0.0, 0.0, 1.0},
+	}
+}
+
+// AddLeader adds a leader branch ending at last with one leader line through vertices.
+func (l *MLeader) AddLeader(last []float64, vertices ...[]float64) *MLeaderBranch {
+	b := &MLeaderBranch{
+		HasLastPoint: true,
+		HasDogleg:    true,
+		LastPoint:    copyPoint(last),
+		DoglegVector: []float64{1.0, 0.0, 0.0},
+		Index:        len(l.Context.Branches),
+		DoglegLength: l.DoglegLength,
+		Lines:        []*MLeaderLine{{Vertices: copyPoints(vertices)}},
+	}
+	l.Context.Branches = append(l.Context.Branches, b)
+	return b
+}
+
+// Format writes data to formatter.
+func (l *MLeader) Format(f format.Formatter) {
+	l.entity.Format(f)
+	f.WriteString(100, "AcDbMLeader")
+	f.WriteInt(270, l.Version)
+	l.Context.Format(f)
+	writeHandle(f, 340, l.Style)
+	f.WriteInt(90, l.PropertyOverride)
+	f.WriteInt(170, l.LeaderType)
+	f.WriteInt(91, l.LineColor)
+	writeHandle(f, 341, l.LineType)
+	f.WriteInt(171, l.LineWeight)
+	f.WriteInt(290, boolInt(l.Landing))
+	f.WriteInt(291, boolInt(l.Dogleg))
+	f.WriteFloat(41, l.DoglegLength)
+	writeHandle(f, 342, l.Arrowhead)
+	f.WriteFloat(42, l.ArrowSize)
+	f.WriteInt(172, l.ContentType)
+	writeHandle(f, 343, l.TextStyle)
+	f.WriteInt(173, l.TextLeftAttachment)
+	f.WriteInt(95, l.TextRightAttachment)
+	f.WriteInt(174, l.TextAngleType)
+	f.WriteInt(175, l.TextAlignment)
+	f.WriteInt(92, l.TextColor)
+	f.WriteInt(292, boolInt(l.TextFrame))
+	writeHandle(f, 344, l.Block)
+	f.WriteInt(93, l.BlockColor)
+	writeVector(f, 10, l.BlockScale)
+	f.WriteFloat(43, l.BlockRotation)
+	f.WriteInt(176, l.BlockConnection)
+	f.WriteInt(293, boolInt(l.Annotative))
+	for _, a := range l.Arrowheads {
+		f.WriteInt(94, a.Index)
+		writeHandle(f, 345, a.Arrowhead)
+	}
+	for _, a := range l.Attributes {
+		writeHandle(f, 330, a.Attdef)
+		f.WriteInt(177, a.Index)
+		f.WriteFloat(44, a.Width)
+		f.WriteString(302, a.Text)
+	}
+	f.WriteInt(294, boolInt(l.TextDirectionNegative))
+	f.WriteInt(178, l.TextIPEAlign)
+	f.WriteInt(179, l.TextAttachmentPoint)
+	f.WriteFloat(45, l.Scale)
+	f.WriteInt(271, l.TextAttachmentDirection)
+	f.WriteInt(272, l.TextBottomAttachment)
+	f.WriteInt(273, l.TextTopAttachment)
+	f.WriteInt(295, boolInt(l.LeaderExtendToText))
+	l.FormatXData(f)
+}
+
+// Format writes context data to formatter.
+func (c *MLeaderContext) Format(f format.Formatter) {
+	f.WriteString(300, "CONTEXT_DATA{")
+	f.WriteFloat(40, c.Scale)
+	writeVector(f, 10, c.BasePoint)
+	f.WriteFloat(41, c.TextHeight)
+	f.WriteFloat(140, c.ArrowSize)
+	f.WriteFloat(145, c.LandingGap)
+	f.WriteInt(174, c.LeftAttachment)
+	f.WriteInt(175, c.RightAttachment)
+	f.WriteInt(176, c.TextAlign)
+	f.WriteInt(177, c.BlockConnection)
+	f.WriteInt(290, boolInt(c.Text != nil))
+	if c.Text != nil {
+		c.Text.Format(f)
+	}
+	f.WriteInt(296, boolInt(c.Block != nil))
+	if c.Block != nil {
+		c.Block.Format(f)
+	}
+	writeVector(f, 110, c.PlaneOrigin)
+	writeVector(f, 111, c.PlaneXAxis)
+	writeVector(f, 112, c.PlaneYAxis)
+	f.WriteInt(297, boolInt(c.PlaneNormalReversed))
+	for _, b := range c.Branches {
+		b.Format(f)
+	}
+	f.WriteInt(272, c.TopAttachment)
+	f.WriteInt(273, c.BottomAttachment)
+	f.WriteString(301, "}")
+}
+
+// Format writes MTEXT content to formatter.
+func (t *MLeaderText) Format(f format.Formatter) {
+	f.WriteString(304, t.Value)
+	writeVector(f, 11, t.Direction)
+	writeHandle(f, 340, t.Style)
+	writeVector(f, 12, t.Location)
+	writeVector(f, 13, t.XAxis)
+	f.WriteFloat(42, t.Rotation)
+	f.WriteFloat(43, t.Width)
+	f.WriteFloat(44, t.DefinedWidth)
+	f.WriteFloat(45, t.DefinedHeight)
+	f.WriteInt(170, t.LineSpacingStyle)
+	f.WriteFloat(141, t.LineSpacingFactor)
+	f.WriteInt(171, t.Alignment)
+	f.WriteInt(172, t.FlowDirection)
+	f.WriteInt(90, t.Color)
+	f.WriteInt(91, t.BackgroundColor)
+	f.WriteFloat(142, t.BackgroundScale)
+	f.WriteInt(92, t.BackgroundTransp)
+	f.WriteInt(291, boolInt(t.BackgroundWindow))
+	f.WriteInt(292, boolInt(t.BackgroundFill))
+	f.WriteInt(173, t.ColumnType)
+	f.WriteInt(293, boolInt(t.AutoHeight))
+	f.WriteFloat(142, t.ColumnWidth)
+	f.WriteFloat(143, t.ColumnGutter)
+	f.WriteInt(294, boolInt(t.ColumnFlowReversed))
+	for _, s := range t.ColumnSizes {
+		f.WriteFloat(144, s)
+	}
+	f.WriteInt(295, boolInt(t.WordBreak))
+}
+
+// Format writes block content to formatter.
+func (b *MLeaderBlock) Format(f format.Formatter) {
+	writeHandle(f, 341, b.Block)
+	writeVector(f, 14, b.Direction)
+	writeVector(f, 15, b.Location)
+	writeVector(f, 16, b.Scale)
+	f.WriteFloat(46, b.Rotation)
+	f.WriteInt(93, b.Color)
+	for _, v := range b.Matrix {
+		f.WriteFloat(47, v)
+	}
+}
+
+// Format writes leader branch to formatter.
+func (b *MLeaderBranch) Format(f format.Formatter) {
+	f.WriteString(302, "LEADER{")
+	f.WriteInt(290, boolInt(b.HasLastPoint))
+	f.WriteInt(291, boolInt(b.HasDogleg))
+	if b.HasLastPoint {
+		writeVector(f, 10, b.LastPoint)
+	}
+	if b.HasDogleg {
+		writeVector(f, 11, b.DoglegVector)
+	}
+	for _, br := range b.Breaks {
+		writeVector(f, 12, br.Start)
+		writeVector(f, 13, br.End)
+	}
+	f.WriteInt(90, b.Index)
+	f.WriteFloat(40, b.DoglegLength)
+	for _, l := range b.Lines {
+		l.Format(f)
+	}
+	f.WriteInt(271, b.AttachmentDirection)
+	f.WriteString(303, "}")
+}
+
+// Format writes leader line to formatter.
+func (l *MLeaderLine) Format(f format.Formatter) {
+	f.WriteString(304, "LEADER_LINE{")
+	for _, v := range l.Vertices {
+		writeVector(f, 10, v)
+	}
+	for _, br := range l.Breaks {
+		f.WriteInt(90, br.Index)
+		writeVector(f, 11, br.Start)
+		writeVector(f, 12, br.End)
+	}
+	f.WriteInt(91, l.Index)
+	f.WriteString(305, "}")
+}
+
+// String outputs data using default formatter.
+func (l *MLeader) String() string {
+	f := format.NewASCII()
+	return l.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (l *MLeader) FormatString(f format.Formatter) string {
+	l.Format(f)
+	return f.Output()
+}
+
+// points returns leader vertices and content locations.
+func (l *MLeader) points() [][]float64 {
+	c := l.Context
+	ps := make([][]float64, 0)
+	if c.Text != nil {
+		ps = append(ps, c.Text.Location)
+	}
+	if c.Block != nil {
+		ps = append(ps, c.Block.Location)
+	}
+	for _, b := range c.Branches {
+		if b.HasLastPoint {
+			ps = append(ps, b.LastPoint)
+		}
+		for _, ln := range b.Lines {
+			ps = append(ps, ln.Vertices...)
+		}
+	}
+	if len(ps) == 0 {
+		ps = append(ps, c.BasePoint)
+	}
+	return ps
+}
+
+// Explode returns leader lines and doglegs as Lines and text content as MText
+// for the versions which don't have MULTILEADER.
+// Arrowheads and block content are not converted.
+func (l *MLeader) Explode() Entities {
+	es := New()
+	c := l.Context
+	if c == nil {
+		return es
+	}
+	line := func(start, end []float64) {
+		n := NewLine()
+		n.entity = l.entity.clone()
+		n.SetEntityType(LINE)
+		n.Start = copyPoint(start)
+		n.End = copyPoint(end)
+		es = es.Add(n)
+	}
+	for _, b := range c.Branches {
+		for _, ln := range b.Lines {
+			ps := ln.Vertices
+			if b.LastPoint != nil {
+				ps = append(ps[:len(ps):len(ps)], b.LastPoint)
+			}
+			for i := 1; i < len(ps); i++ {
+				line(ps[i-1], ps[i])
+			}
+		}
+		if l.Dogleg && b.HasDogleg && b.LastPoint != nil && b.DoglegLength != 0.0 {
+			end := make([]float64, 3)
+			for i := 0; i < 3; i++ {
+				end[i] = b.LastPoint[i] + b.DoglegVector[i]*b.DoglegLength
+			}
+			line(b.LastPoint, end)
+		}
+	}
+	if t := c.Text; t != nil {
+		m := NewMText()
+		m.entity = l.entity.clone()
+		m.SetEntityType(MTEXT)
+		m.Value = t.Value
+		m.Coord = copyPoint(t.Location)
+		m.Height = c.TextHeight
+		m.Width = t.Width
+		m.Direction = copyPoint(t.Direction)
+		m.XAxis = copyPoint(t.XAxis)
+		es = es.Add(m)
+	}
+	return es
+}
+
+// BBox returns bounding box of leader vertices and content locations.
+func (l *MLeader) BBox() ([]float64, []float64) {
+	return pointsBBox(l.points())
+}
+
+// Clone returns a copy of MLeader.
+func (l *MLeader) Clone() Entity {
+	c := *l
+	c.entity = l.entity.clone()
+	c.Context = l.Context.clone()
+	c.BlockScale = copyPoint(l.BlockScale)
+	c.Arrowheads = append([]MLeaderArrowhead(nil), l.Arrowheads...)
+	c.Attributes = append([]MLeaderAttribute(nil), l.Attributes...)
+	return &c
+}
+
+// clone returns a deep copy of context data.
+func (c *MLeaderContext) clone() *MLeaderContext {
+	n := *c
+	n.BasePoint = copyPoint(c.BasePoint)
+	n.PlaneOrigin = copyPoint(c.PlaneOrigin)
+	n.PlaneXAxis = copyPoint(c.PlaneXAxis)
+	n.PlaneYAxis = copyPoint(c.PlaneYAxis)
+	if c.Text != nil {
+		t := *c.Text
+		t.Direction = copyPoint(c.Text.Direction)
+		t.Location = copyPoint(c.Text.Location)
+		t.XAxis = copyPoint(c.Text.XAxis)
+		t.ColumnSizes = copyPoint(c.Text.ColumnSizes)
+		n.Text = &t
+	}
+	if c.Block != nil {
+		b := *c.Block
+		b.Direction = copyPoint(c.Block.Direction)
+		b.Location = copyPoint(c.Block.Location)
+		b.Scale = copyPoint(c.Block.Scale)
+		b.Matrix = copyPoint(c.Block.Matrix)
+		n.Block = &b
+	}
+	n.Branches = make([]*MLeaderBranch, len(c.Branches))
+	for i, b := range c.Branches {
+		nb := *b
+		nb.LastPoint = copyPoint(b.LastPoint)
+		nb.DoglegVector = copyPoint(b.DoglegVector)
+		nb.Breaks = copyBreaks(b.Breaks)
+		nb.Lines = make([]*MLeaderLine, len(b.Lines))
+		for j, l := range b.Lines {
+			nb.Lines[j] = &MLeaderLine{Vertices: copyPoints(l.Vertices), Breaks: copyBreaks(l.Breaks), Index: l.Index}
+		}
+		n.Branches[i] = &nb
+	}
+	return &n
+}
+
+// Transform transforms geometry of context data by m.
+// Sizes such as text height and arrow size are not changed.
+func (l *MLeader) Transform(m geometry.Matrix) {
+	c := l.Context
+	apply := func(p []float64) []float64 {
+		if p == nil {
+			return nil
+		}
+		return m.Apply(p)
+	}
+	vector := func(v []float64) []float64 {
+		if v == nil {
+			return nil
+		}
+		return geometry.Normalize(m.ApplyVector(v))
+	}
+	breaks := func(bs []MLeaderBreak) {
+		for i := range bs {
+			bs[i].Start = apply(bs[i].Start)
+			bs[i].End = apply(bs[i].End)
+		}
+	}
+	c.BasePoint = apply(c.BasePoint)
+	c.PlaneOrigin = apply(c.PlaneOrigin)
+	c.PlaneXAxis = vector(c.PlaneXAxis)
+	c.PlaneYAxis = vector(c.PlaneYAxis)
+	if t := c.Text; t != nil {
+		t.Location = apply(t.Location)
+		t.XAxis = vector(t.XAxis)
+		t.Direction = newOCSTransform(m, t.Direction).normal
+	}
+	if b := c.Block; b != nil {
+		ot := newOCSTransform(m, b.Direction)
+		b.Location = apply(b.Location)
+		b.Rotation += ot.rotation
+		b.Direction = ot.normal
+	}
+	for _, b := range c.Branches {
+		b.LastPoint = apply(b.LastPoint)
+		b.DoglegVector = vector(b.DoglegVector)
+		breaks(b.Breaks)
+		for _, ln := range b.Lines {
+			for i, v := range ln.Vertices {
+				ln.Vertices[i] = apply(v)
+			}
+			breaks(ln.Breaks)
+		}
+	}
+}
+
+// copyBreaks returns a copy of breaks.
+func copyBreaks(bs []MLeaderBreak) []MLeaderBreak {
+	if bs == nil {
+		return nil
+	}
+	c := make([]MLeaderBreak, len(bs))
+	for i, b := range bs {
+		c[i] = MLeaderBreak{Index: b.Index, Start: copyPoint(b.Start), End: copyPoint(b.End)}
+	}
+	return c
+}
+
+// writeHandle writes handle of h with code if h is not nil.
+func writeHandle(f format.Formatter, code int, h handle.Handler) {
+	if h != nil {
+		f.WriteHex(code, h.Handle())
+	}
+}
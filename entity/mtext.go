@@ -0,0 +1,202 @@
+package entity
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+	"github.com/flywave/go-dxf/mtext"
+	"github.com/flywave/go-dxf/table"
+)
+
+// MText attachment point (code 71)
+const (
+	MTEXT_TOP_LEFT = iota + 1
+	MTEXT_TOP_CENTER
+	MTEXT_TOP_RIGHT
+	MTEXT_MIDDLE_LEFT
+	MTEXT_MIDDLE_CENTER
+	MTEXT_MIDDLE_RIGHT
+	MTEXT_BOTTOM_LEFT
+	MTEXT_BOTTOM_CENTER
+	MTEXT_BOTTOM_RIGHT
+)
+
+// mtextChunk is the maximum length of a text chunk (code 1 and 3).
+const mtextChunk = 250
+
+// MText represents MTEXT Entity.
+type MText struct {
+	*entity
+	Coord             []float64    // 10, 20, 30
+	Height            float64      // 40
+	Width             float64      // 41: reference rectangle width
+	Attachment        int          // 71
+	DrawingDirection  int          // 72: 1 = left to right, 3 = top to bottom, 5 = by style
+	Value             string       // 3, 1
+	Style             *table.Style // 7
+	Direction         []float64    // 210, 220, 230
+	XAxis             []float64    // 11, 21, 31: direction vector in WCS
+	LineSpacingStyle  int          // 73: 1 = at least, 2 = exact
+	LineSpacingFactor float64      // 44
+}
+
+// IsEntity is for Entity interface.
+func (t *MText) IsEntity() bool {
+	return true
+}
+
+// NewMText creates a new MText.
+func NewMText() *MText {
+	t := &MText{
+		entity:            NewEntity(MTEXT),
+		Coord:             []float64{0.0, 0.0, 0.0},
+		Height:            1.0,
+		Width:             0.0,
+		Attachment:        MTEXT_TOP_LEFT,
+		DrawingDirection:  1,
+		Value:             "",
+		Style:             table.ST_STANDARD,
+		Direction:         []float64{0.0, 0.0, 1.0},
+		XAxis:             []float64{1.0, 0.0, 0.0},
+		LineSpacingStyle:  1,
+		LineSpacingFactor: 1.0,
+	}
+	return t
+}
+
+// Format writes data to formatter.
+// Value longer than 250 characters is split into chunks of code 3.
+func (t *MText) Format(f format.Formatter) {
+	t.entity.Format(f)
+	f.WriteString(100, "AcDbMText")
+	for i := 0; i < 3; i++ {
+		f.WriteFloat((i+1)*10, t.Coord[i])
+	}
+	f.WriteFloat(40, t.Height)
+	f.WriteFloat(41, t.Width)
+	f.WriteInt(71, t.Attachment)
+	f.WriteInt(72, t.DrawingDirection)
+	v := []rune(t.Value)
+	for len(v) > mtextChunk {
+		f.WriteString(3, string(v[:mtextChunk]))
+		v = v[mtextChunk:]
+	}
+	f.WriteString(1, string(v))
+	f.WriteString(7, t.Style.Name())
+	if !isDefaultDirection(t.Direction) {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat(200+(i+1)*10, t.Direction[i])
+		}
+	}
+	for i := 0; i < 3; i++ {
+		f.WriteFloat((i+1)*10+1, t.XAxis[i])
+	}
+	f.WriteInt(73, t.LineSpacingStyle)
+	f.WriteFloat(44, t.LineSpacingFactor)
+	t.FormatXData(f)
+}
+
+// String outputs data using default formatter.
+func (t *MText) String() string {
+	f := format.NewASCII()
+	return t.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (t *MText) FormatString(f format.Formatter) string {
+	t.Format(f)
+	return f.Output()
+}
+
+// PlainText returns Value without inline formatting codes.
+func (t *MText) PlainText() string {
+	return mtext.PlainText(t.Value)
+}
+
+// Runs returns Value as styled runs.
+func (t *MText) Runs() []mtext.Run {
+	return mtext.Parse(t.Value)
+}
+
+// Rotation returns the angle of XAxis in OCS (Degree).
+func (t *MText) Rotation() float64 {
+	x := geometry.OCS(t.Direction).Transpose().ApplyVector(t.XAxis)
+	return math.Atan2(x[1], x[0]) * 180.0 / math.Pi
+}
+
+// SetRotation sets XAxis by the angle in OCS (Degree).
+func (t *MText) SetRotation(deg float64) {
+	s, c := math.Sincos(deg * math.Pi / 180.0)
+	t.XAxis = geometry.OCS(t.Direction).ApplyVector([]float64{c, s, 0.0})
+}
+
+// BBox returns bounding box of the text frame placed at the attachment point
+// and rotated by XAxis in the plane of Direction.
+// Without Width, the frame is as wide as the longest line of characters as wide as Height.
+// Lines are spaced by 5/3 of Height times LineSpacingFactor.
+func (t *MText) BBox() ([]float64, []float64) {
+	lines := strings.Split(t.PlainText(), "\n")
+	width := t.Width
+	if width == 0.0 {
+		for _, l := range lines {
+			width = math.Max(width, float64(utf8.RuneCountInString(l))*t.Height)
+		}
+	}
+	height := t.Height + float64(len(lines)-1)*t.Height*5.0/3.0*t.LineSpacingFactor
+	// offsets of the frame from the attachment point
+	column, row := 0, 0
+	if t.Attachment >= MTEXT_TOP_LEFT && t.Attachment <= MTEXT_BOTTOM_RIGHT {
+		column, row = (t.Attachment-1)%3, (t.Attachment-1)/3
+	}
+	left := -width * float64(column) / 2.0
+	top := height * float64(row) / 2.0
+	x := geometry.Normalize(t.XAxis)
+	y := geometry.Normalize(geometry.Cross(t.Direction, x))
+	corners := make([][]float64, 0, 4)
+	for _, u := range []float64{left, left + width} {
+		for _, v := range []float64{top - height, top} {
+			corners = append(corners, []float64{
+				t.Coord[0] + u*x[0] + v*y[0],
+				t.Coord[1] + u*x[1] + v*y[1],
+				t.Coord[2] + u*x[2] + v*y[2],
+			})
+		}
+	}
+	return pointsBBox(corners)
+}
+
+// Clone returns a copy of MText.
+func (t *MText) Clone() Entity {
+	c := *t
+	c.entity = t.entity.clone()
+	c.Coord = copyPoint(t.Coord)
+	c.Direction = copyPoint(t.Direction)
+	c.XAxis = copyPoint(t.XAxis)
+	return &c
+}
+
+// Transform transforms MText by m.
+// Width follows the scale of XAxis, and Height the scale perpendicular to it
+// in the transformed OCS, as Text does.
+func (t *MText) Transform(m geometry.Matrix) {
+	o := newOCSTransform(m, t.Direction)
+	x := geometry.OCS(t.Direction).Transpose().ApplyVector(t.XAxis)
+	c, s := 1.0, 0.0
+	if l := math.Hypot(x[0], x[1]); l > 0.0 {
+		c, s = x[0]/l, x[1]/l
+	}
+	base := o.m.ApplyVector([]float64{c, s, 0.0})
+	stroke := o.m.ApplyVector([]float64{-s, c, 0.0})
+	t.Coord = m.Apply(t.Coord)
+	t.Direction = o.normal
+	xscale := math.Hypot(base[0], base[1])
+	if xscale == 0.0 {
+		return
+	}
+	t.XAxis = geometry.OCS(o.normal).ApplyVector([]float64{base[0] / xscale, base[1] / xscale, 0.0})
+	t.Height *= (base[0]*stroke[1] - base[1]*stroke[0]) / xscale
+	t.Width *= xscale
+}
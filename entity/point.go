@@ -2,6 +2,7 @@ package entity
 
 import (
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 )
 
 // Point represents POINT Entity.
@@ -43,6 +44,7 @@ func (p *Point) Format(f format.Formatter) {
 	for i := 0; i < 3; i++ {
 		f.WriteFloat((i+1)*10, p.Coord[i])
 	}
+	p.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -60,3 +62,16 @@ func (p *Point) FormatString(f format.Formatter) string {
 func (p *Point) BBox() ([]float64, []float64) {
 	return []float64{p.Coord[0], p.Coord[1], p.Coord[2]}, []float64{p.Coord[0], p.Coord[1], p.Coord[2]}
 }
+
+// Clone returns a copy of Point.
+func (p *Point) Clone() Entity {
+	return &Point{
+		entity: p.entity.clone(),
+		Coord:  copyPoint(p.Coord),
+	}
+}
+
+// Transform transforms Point by m.
+func (p *Point) Transform(m geometry.Matrix) {
+	p.Coord = m.Apply(p.Coord)
+}
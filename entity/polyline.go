@@ -1,16 +1,43 @@
 package entity
 
 import (
+	"math"
+
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+)
+
+// Polyline flags (code 70).
+const (
+	POLYLINE_CLOSED          = 1
+	POLYLINE_CURVE_FIT       = 2
+	POLYLINE_SPLINE_FIT      = 4
+	POLYLINE_3D              = 8
+	POLYLINE_POLYGON_MESH    = 16
+	POLYLINE_MESH_CLOSED_N   = 32
+	POLYLINE_POLYFACE_MESH   = 64
+	POLYLINE_CONTINUOUS_TYPE = 128
 )
 
 // Polyline represents POLYLINE Entity.
+// Depending on Flag, it is a 2D polyline, a 3D polyline,
+// a polyface mesh or a polygon mesh.
 type Polyline struct {
 	*entity
-	Flag      int
-	size      int
-	Vertices  []*Vertex
-	endhandle int
+	Flag       int       // 70
+	Elevation  float64   // 30
+	Thickness  float64   // 39
+	StartWidth float64   // 40
+	EndWidth   float64   // 41
+	MCount     int       // 71: M vertex count of polygon mesh, or number of vertices of polyface mesh
+	NCount     int       // 72: N vertex count of polygon mesh, or number of faces of polyface mesh
+	MDensity   int       // 73
+	NDensity   int       // 74
+	SmoothType int       // 75
+	Direction  []float64 // 210, 220, 230
+	size       int
+	Vertices   []*Vertex
+	endhandle  int
 }
 
 // IsEntity is for Entity interface.
@@ -24,6 +51,7 @@ func NewPolyline() *Polyline {
 	p := &Polyline{
 		entity:    NewEntity(POLYLINE),
 		Flag:      8,
+		Direction: []float64{0.0, 0.0, 1.0},
 		size:      0,
 		Vertices:  vs,
 		endhandle: 0,
@@ -31,15 +59,72 @@ func NewPolyline() *Polyline {
 	return p
 }
 
+// Is3D reports whether Polyline is a 3D polyline.
+func (p *Polyline) Is3D() bool {
+	return p.Flag&POLYLINE_3D != 0
+}
+
+// IsPolygonMesh reports whether Polyline is a polygon mesh.
+func (p *Polyline) IsPolygonMesh() bool {
+	return p.Flag&POLYLINE_POLYGON_MESH != 0
+}
+
+// IsPolyfaceMesh reports whether Polyline is a polyface mesh.
+func (p *Polyline) IsPolyfaceMesh() bool {
+	return p.Flag&POLYLINE_POLYFACE_MESH != 0
+}
+
+// Is2D reports whether Polyline is a 2D polyline, whose vertices are in OCS.
+func (p *Polyline) Is2D() bool {
+	return !p.Is3D() && !p.IsPolygonMesh() && !p.IsPolyfaceMesh()
+}
+
 // Format writes data to formatter.
 func (p *Polyline) Format(f format.Formatter) {
 	p.entity.Format(f)
-	f.WriteString(100, "AcDb3dPolyline")
+	switch {
+	case p.Is3D():
+		f.WriteString(100, "AcDb3dPolyline")
+	case p.IsPolygonMesh():
+		f.WriteString(100, "AcDbPolygonMesh")
+	case p.IsPolyfaceMesh():
+		f.WriteString(100, "AcDbPolyFaceMesh")
+	default:
+		f.WriteString(100, "AcDb2dPolyline")
+	}
 	f.WriteInt(66, 1)
 	f.WriteString(10, "0.0")
 	f.WriteString(20, "0.0")
-	f.WriteString(30, "0.0")
+	if p.Elevation != 0.0 {
+		f.WriteFloat(30, p.Elevation)
+	} else {
+		f.WriteString(30, "0.0")
+	}
+	if p.Thickness != 0.0 {
+		f.WriteFloat(39, p.Thickness)
+	}
 	f.WriteInt(70, p.Flag)
+	if p.StartWidth != 0.0 {
+		f.WriteFloat(40, p.StartWidth)
+	}
+	if p.EndWidth != 0.0 {
+		f.WriteFloat(41, p.EndWidth)
+	}
+	if p.IsPolygonMesh() || p.IsPolyfaceMesh() {
+		f.WriteInt(71, p.MCount)
+		f.WriteInt(72, p.NCount)
+	}
+	if p.IsPolygonMesh() {
+		f.WriteInt(73, p.MDensity)
+		f.WriteInt(74, p.NDensity)
+		f.WriteInt(75, p.SmoothType)
+	}
+	if !isDefaultDirection(p.Direction) {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat(200+(i+1)*10, p.Direction[i])
+		}
+	}
+	p.FormatXData(f)
 	for _, v := range p.Vertices {
 		v.Format(f)
 	}
@@ -66,14 +151,45 @@ func (p *Polyline) Close() {
 	p.Flag |= 1
 }
 
+// vertexFlag returns the flag of a new vertex according to the type of Polyline.
+func (p *Polyline) vertexFlag() int {
+	switch {
+	case p.Is3D():
+		return VERTEX_3D_POLYLINE
+	case p.IsPolygonMesh():
+		return VERTEX_POLYGON_MESH
+	case p.IsPolyfaceMesh():
+		return VERTEX_POLYGON_MESH | VERTEX_POLYFACE_MESH
+	default:
+		return 0
+	}
+}
+
 // AddVertex adds a new vertex to Polyline.
 func (p *Polyline) AddVertex(x, y, z float64) *Vertex {
 	v := NewVertex(x, y, z)
+	v.Flag = p.vertexFlag()
+	p.AppendVertex(v)
+	v.SetLayer(p.Layer())
+	return v
+}
+
+// AddFace adds a new face record to polyface mesh.
+// Indices are 1-based vertex numbers; negative value means invisible edge.
+func (p *Polyline) AddFace(indices ...int) *Vertex {
+	v := NewVertex(0.0, 0.0, 0.0)
+	v.Flag = VERTEX_POLYFACE_MESH
+	copy(v.Indices, indices)
+	p.AppendVertex(v)
+	v.SetLayer(p.Layer())
+	return v
+}
+
+// AppendVertex appends an existing vertex to Polyline.
+func (p *Polyline) AppendVertex(v *Vertex) {
 	p.Vertices = append(p.Vertices, v)
 	p.size++
-	v.SetLayer(p.Layer())
 	v.SetOwner(p)
-	return v
 }
 
 // SetHandle sets handles to itself and its vertices.
@@ -86,18 +202,103 @@ func (p *Polyline) SetHandle(h *int) {
 	(*h)++
 }
 
+// SetEndHandle sets a handle to SEQEND only.
+func (p *Polyline) SetEndHandle(h *int) {
+	p.endhandle = *h
+	(*h)++
+}
+
 func (p *Polyline) BBox() ([]float64, []float64) {
-	mins := make([]float64, 3)
-	maxs := make([]float64, 3)
+	mins := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+	maxs := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	m := geometry.Identity()
+	if p.Is2D() {
+		m = geometry.OCS(p.Direction)
+	}
 	for _, v := range p.Vertices {
+		if v.IsFaceRecord() {
+			continue
+		}
+		c := v.Coord
+		if p.Is2D() {
+			c = m.Apply([]float64{c[0], c[1], p.Elevation})
+		}
 		for i := 0; i < 3; i++ {
-			if v.Coord[i] < mins[i] {
-				mins[i] = v.Coord[i]
+			mins[i] = math.Min(mins[i], c[i])
+			maxs[i] = math.Max(maxs[i], c[i])
+		}
+	}
+	return mins, maxs
+}
+
+// Clone returns a copy of Polyline and its vertices.
+func (p *Polyline) Clone() Entity {
+	c := *p
+	c.entity = p.entity.clone()
+	c.Direction = copyPoint(p.Direction)
+	c.Vertices = make([]*Vertex, len(p.Vertices))
+	c.endhandle = 0
+	for i, v := range p.Vertices {
+		c.Vertices[i] = v.Clone().(*Vertex)
+		c.Vertices[i].SetOwner(&c)
+	}
+	return &c
+}
+
+// tessellate replaces arc segments of 2D polyline with straight segments within tolerance.
+// Vertices added are copies of the vertex starting the arc, and widths are interpolated along the arc.
+func (p *Polyline) tessellate(tolerance float64) {
+	n := len(p.Vertices)
+	vs := make([]*Vertex, 0, n)
+	for i, v := range p.Vertices {
+		if v.Bulge == 0.0 || (p.Flag&1 == 0 && i == n-1) {
+			vs = append(vs, v)
+			continue
+		}
+		ps := geometry.BulgePoints(v.Coord, p.Vertices[(i+1)%n].Coord, v.Bulge, tolerance)
+		sw, ew := v.StartWidth, v.EndWidth
+		for k, q := range ps {
+			c := v
+			if k > 0 {
+				c = v.Clone().(*Vertex)
 			}
-			if v.Coord[i] > maxs[i] {
-				maxs[i] = v.Coord[i]
+			c.Coord = []float64{q[0], q[1], v.Coord[2]}
+			c.Bulge = 0.0
+			c.StartWidth = sw + (ew-sw)*float64(k)/float64(len(ps))
+			c.EndWidth = sw + (ew-sw)*float64(k+1)/float64(len(ps))
+			vs = append(vs, c)
+		}
+	}
+	p.Vertices = vs
+	p.size = len(vs)
+}
+
+// Transform transforms Polyline by m.
+// Vertices of 2D polyline are kept in OCS of the transformed extrusion direction.
+// Arc segments of 2D polyline remain circular only if m scales uniformly in the plane,
+// so use TransformEntity for other matrices.
+func (p *Polyline) Transform(m geometry.Matrix) {
+	if !p.Is2D() {
+		for _, v := range p.Vertices {
+			if !v.IsFaceRecord() {
+				v.Transform(m)
 			}
 		}
+		return
 	}
-	return mins, maxs
+	t := newOCSTransform(m, p.Direction)
+	n := len(p.Vertices)
+	for i, v := range p.Vertices {
+		s := t.widthScale(v.Coord, p.Vertices[(i+1)%n].Coord)
+		v.StartWidth *= s
+		v.EndWidth *= s
+	}
+	for _, v := range p.Vertices {
+		v.Coord = t.m.Apply([]float64{v.Coord[0], v.Coord[1], p.Elevation})
+	}
+	p.Elevation = t.m.Apply([]float64{0.0, 0.0, p.Elevation})[2]
+	p.StartWidth *= t.xscale
+	p.EndWidth *= t.xscale
+	p.Thickness *= t.zscale
+	p.Direction = t.normal
 }
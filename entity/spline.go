@@ -1,19 +1,25 @@
 package entity
 
 import (
+	"math"
+
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 )
 
-// Spline represents LINE Entity.
+// Spline represents SPLINE Entity.
 type Spline struct {
 	*entity
-	Normal    []float64   // 210, 220, 230
-	Flag      int         // 70
-	Degree    int         // 71
-	Knots     []float64   // 72, 40
-	Controls  [][]float64 // 73, 10, 20, 30
-	Fits      [][]float64 // 74, 11, 21, 31
-	Tolerance []float64   // 42, 43, 44
+	Normal       []float64   // 210, 220, 230
+	Flag         int         // 70
+	Degree       int         // 71
+	Knots        []float64   // 72, 40
+	Controls     [][]float64 // 73, 10, 20, 30
+	Weights      []float64   // 41: nil means all weights are 1
+	Fits         [][]float64 // 74, 11, 21, 31
+	Tolerance    []float64   // 42, 43, 44
+	StartTangent []float64   // 12, 22, 32: nil if not specified
+	EndTangent   []float64   // 13, 23, 33: nil if not specified
 }
 
 // IsEntity is for Entity interface.
@@ -51,19 +57,33 @@ func (s *Spline) Format(f format.Formatter) {
 	for i := 0; i < 3; i++ {
 		f.WriteFloat(42+i, s.Tolerance[i])
 	}
+	if s.StartTangent != nil {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat((i+1)*10+2, s.StartTangent[i])
+		}
+	}
+	if s.EndTangent != nil {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat((i+1)*10+3, s.EndTangent[i])
+		}
+	}
 	for _, k := range s.Knots {
 		f.WriteFloat(40, k)
 	}
-	for _, c := range s.Controls {
+	for j, c := range s.Controls {
 		for i := 0; i < 3; i++ {
 			f.WriteFloat((i+1)*10, c[i])
 		}
+		if j < len(s.Weights) {
+			f.WriteFloat(41, s.Weights[j])
+		}
 	}
 	for _, ft := range s.Fits {
 		for i := 0; i < 3; i++ {
 			f.WriteFloat((i+1)*10+1, ft[i])
 		}
 	}
+	s.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -78,17 +98,62 @@ func (s *Spline) FormatString(f format.Formatter) string {
 	return f.Output()
 }
 
-// func (s *Spline) BBox() ([]float64, []float64) {
-// 	mins := make([]float64, 3)
-// 	maxs := make([]float64, 3)
-// 	for i := 0; i < 3; i++ {
-// 		if l.Start[i] <= l.End[i] {
-// 			mins[i] = l.Start[i]
-// 			maxs[i] = l.End[i]
-// 		} else {
-// 			mins[i] = l.End[i]
-// 			maxs[i] = l.Start[i]
-// 		}
-// 	}
-// 	return mins, maxs
-// }
+// NURBS returns the curve defined by the control points.
+func (s *Spline) NURBS() (*geometry.NURBS, error) {
+	return geometry.NewNURBS(s.Degree, s.Knots, s.Controls, s.Weights)
+}
+
+// Points approximates Spline by a polyline within tolerance.
+// If Spline has no valid control points, fit points are returned.
+func (s *Spline) Points(tolerance float64) [][]float64 {
+	n, err := s.NURBS()
+	if err != nil {
+		return copyPoints(s.Fits)
+	}
+	return n.Tessellate(tolerance)
+}
+
+// BBox returns the exact bounding box of the curve.
+// If Spline has no valid control points, bounding box of fit points is returned.
+func (s *Spline) BBox() ([]float64, []float64) {
+	if n, err := s.NURBS(); err == nil {
+		return n.BBox()
+	}
+	mins := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+	maxs := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range s.Fits {
+		for i := 0; i < 3; i++ {
+			mins[i] = math.Min(mins[i], p[i])
+			maxs[i] = math.Max(maxs[i], p[i])
+		}
+	}
+	return mins, maxs
+}
+
+// Clone returns a copy of Spline.
+func (s *Spline) Clone() Entity {
+	c := *s
+	c.entity = s.entity.clone()
+	c.Normal = copyPoint(s.Normal)
+	c.Knots = copyPoint(s.Knots)
+	c.Controls = copyPoints(s.Controls)
+	c.Weights = copyPoint(s.Weights)
+	c.Fits = copyPoints(s.Fits)
+	c.Tolerance = copyPoint(s.Tolerance)
+	c.StartTangent = copyPoint(s.StartTangent)
+	c.EndTangent = copyPoint(s.EndTangent)
+	return &c
+}
+
+// Transform transforms Spline by m.
+func (s *Spline) Transform(m geometry.Matrix) {
+	transformPoints(m, s.Controls)
+	transformPoints(m, s.Fits)
+	if s.StartTangent != nil {
+		s.StartTangent = m.ApplyVector(s.StartTangent)
+	}
+	if s.EndTangent != nil {
+		s.EndTangent = m.ApplyVector(s.EndTangent)
+	}
+	s.Normal = geometry.Normalize(m.ApplyVector(s.Normal))
+}
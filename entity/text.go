@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"math"
+
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
 	"github.com/flywave/go-dxf/table"
 )
 
@@ -35,6 +38,7 @@ type Text struct {
 	GenFlag        int          // 71
 	HorizontalFlag int          // 72
 	VerticalFlag   int          // 73
+	Direction      []float64    // 210, 220, 230
 }
 
 // IsEntity is for Entity interface.
@@ -54,6 +58,7 @@ func NewText() *Text {
 		GenFlag:        0,
 		HorizontalFlag: 0,
 		VerticalFlag:   0,
+		Direction:      []float64{0.0, 0.0, 1.0},
 	}
 	return t
 }
@@ -82,10 +87,16 @@ func (t *Text) Format(f format.Formatter) {
 			}
 		}
 	}
+	if !isDefaultDirection(t.Direction) {
+		for i := 0; i < 3; i++ {
+			f.WriteFloat(200+(i+1)*10, t.Direction[i])
+		}
+	}
 	f.WriteString(100, "AcDbText")
 	if t.VerticalFlag != 0 {
 		f.WriteInt(73, t.VerticalFlag)
 	}
+	t.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -166,3 +177,44 @@ func (t *Text) BBox() ([]float64, []float64) {
 	maxs := []float64{t.Coord1[0], t.Coord1[1] + t.Height, t.Coord1[2]}
 	return mins, maxs
 }
+
+// Clone returns a copy of Text.
+func (t *Text) Clone() Entity {
+	c := *t
+	c.entity = t.entity.clone()
+	c.Coord1 = copyPoint(t.Coord1)
+	c.Coord2 = copyPoint(t.Coord2)
+	c.Direction = copyPoint(t.Direction)
+	return &c
+}
+
+// Transform transforms Text by m.
+// Coordinates are kept in OCS of the transformed extrusion direction,
+// so mirrored text gets the flipped extrusion direction.
+// Height, width factor and oblique angle follow the transformed baseline
+// and the slanted vertical stroke of characters.
+func (t *Text) Transform(m geometry.Matrix) {
+	o := newOCSTransform(m, t.Direction)
+	s, c := math.Sincos(t.Rotation * math.Pi / 180.0)
+	tan := math.Tan(t.ObliqueAngle * math.Pi / 180.0)
+	base := o.m.ApplyVector([]float64{c, s, 0.0})
+	stroke := o.m.ApplyVector([]float64{-s + tan*c, c + tan*s, 0.0})
+	t.Coord1 = o.m.Apply(t.Coord1)
+	t.Coord2 = o.m.Apply(t.Coord2)
+	t.Direction = o.normal
+	xscale := math.Hypot(base[0], base[1])
+	if xscale == 0.0 {
+		return
+	}
+	t.Rotation = math.Atan2(base[1], base[0]) * 180.0 / math.Pi
+	yscale := (base[0]*stroke[1] - base[1]*stroke[0]) / xscale
+	if yscale == 0.0 {
+		return
+	}
+	along := (base[0]*stroke[0] + base[1]*stroke[1]) / xscale
+	t.Height *= yscale
+	if t.WidthFactor != 0.0 {
+		t.WidthFactor *= xscale / yscale
+	}
+	t.ObliqueAngle = math.Atan2(along, yscale) * 180.0 / math.Pi
+}
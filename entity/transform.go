@@ -0,0 +1,186 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/flywave/go-dxf/geometry"
+)
+
+// ocsTransform represents an affine transformation applied to
+// planar geometry defined in OCS (Object Coordinate System).
+type ocsTransform struct {
+	m        geometry.Matrix // old OCS -> new OCS
+	normal   []float64       // new extrusion direction
+	xscale   float64         // length of transformed OCS X axis
+	yscale   float64         // length of transformed OCS Y axis
+	zscale   float64         // signed length of transformed OCS Z axis along new normal
+	rotation float64         // angle of transformed OCS X axis in new OCS (Radian)
+}
+
+// newOCSTransform creates ocsTransform for m and the current extrusion direction.
+// The new extrusion direction is chosen so that the transformed OCS X and Y axes
+// keep counterclockwise order, so angles remain counterclockwise.
+func newOCSTransform(m geometry.Matrix, normal []float64) *ocsTransform {
+	from := geometry.OCS(normal)
+	ax := m.ApplyVector([]float64{from[0][0], from[1][0], from[2][0]})
+	ay := m.ApplyVector([]float64{from[0][1], from[1][1], from[2][1]})
+	az := m.ApplyVector([]float64{from[0][2], from[1][2], from[2][2]})
+	n := geometry.Normalize(geometry.Cross(ax, ay))
+	if geometry.Length(n) == 0.0 {
+		n = geometry.Normalize(normal)
+	}
+	to := geometry.OCS(n)
+	t := &ocsTransform{
+		m:      to.Transpose().Multiply(m).Multiply(from),
+		normal: n,
+		xscale: geometry.Length(ax),
+		yscale: geometry.Length(ay),
+		zscale: geometry.Dot(az, n),
+	}
+	x := t.m.ApplyVector([]float64{1.0, 0.0, 0.0})
+	t.rotation = math.Atan2(x[1], x[0])
+	return t
+}
+
+// conformal reports whether the transformed OCS X and Y axes are
+// perpendicular and of the same length, so circles remain circles.
+func (t *ocsTransform) conformal() bool {
+	x := t.m.ApplyVector([]float64{1.0, 0.0, 0.0})
+	y := t.m.ApplyVector([]float64{0.0, 1.0, 0.0})
+	tol := 1e-9 * math.Max(t.xscale, t.yscale)
+	return math.Abs(t.xscale-t.yscale) <= tol && math.Abs(geometry.Dot(x, y)) <= tol*math.Max(t.xscale, t.yscale)
+}
+
+// widthScale returns the factor by which the width of the straight segment from p1 to p2
+// in old OCS is scaled, which is the distance between its transformed edges.
+func (t *ocsTransform) widthScale(p1, p2 []float64) float64 {
+	if t.conformal() {
+		return t.xscale
+	}
+	v := t.m.ApplyVector([]float64{p2[0] - p1[0], p2[1] - p1[1], 0.0})
+	l := math.Hypot(v[0], v[1])
+	if l == 0.0 {
+		return t.xscale
+	}
+	det := t.m[0][0]*t.m[1][1] - t.m[0][1]*t.m[1][0]
+	return math.Abs(det) * math.Hypot(p2[0]-p1[0], p2[1]-p1[1]) / l
+}
+
+// angle transforms an angle (Degree) in old OCS into new OCS.
+func (t *ocsTransform) angle(deg float64) float64 {
+	s, c := math.Sincos(deg * math.Pi / 180.0)
+	v := t.m.ApplyVector([]float64{c, s, 0.0})
+	return math.Atan2(v[1], v[0]) * 180.0 / math.Pi
+}
+
+// principalAxes returns the major and minor semi-axes of the ellipse
+// p(t) = cos(t)*a + sin(t)*b given by conjugate semi-diameters a and b,
+// and the parameter t0 of the major axis, so that p(t) = cos(t-t0)*major + sin(t-t0)*minor.
+func principalAxes(a, b []float64) ([]float64, []float64, float64) {
+	aa, bb, ab := geometry.Dot(a, a), geometry.Dot(b, b), geometry.Dot(a, b)
+	t0 := 0.5 * math.Atan2(2.0*ab, aa-bb)
+	s, c := math.Sincos(t0)
+	major := []float64{c*a[0] + s*b[0], c*a[1] + s*b[1], c*a[2] + s*b[2]}
+	minor := []float64{-s*a[0] + c*b[0], -s*a[1] + c*b[1], -s*a[2] + c*b[2]}
+	if geometry.Length(minor) > geometry.Length(major) {
+		major, minor = minor, []float64{-major[0], -major[1], -major[2]}
+		t0 += math.Pi / 2.0
+	}
+	return major, minor, t0
+}
+
+// isDefaultDirection reports whether d is (0, 0, 1).
+func isDefaultDirection(d []float64) bool {
+	return d[0] == 0.0 && d[1] == 0.0 && d[2] == 1.0
+}
+
+// copyPoint returns a copy of p.
+func copyPoint(p []float64) []float64 {
+	if p == nil {
+		return nil
+	}
+	c := make([]float64, len(p))
+	copy(c, p)
+	return c
+}
+
+// copyPoints returns a deep copy of ps.
+func copyPoints(ps [][]float64) [][]float64 {
+	if ps == nil {
+		return nil
+	}
+	c := make([][]float64, len(ps))
+	for i, p := range ps {
+		c[i] = copyPoint(p)
+	}
+	return c
+}
+
+// transformPoints transforms each point in ps.
+func transformPoints(m geometry.Matrix, ps [][]float64) {
+	for i, p := range ps {
+		ps[i] = m.Apply(p)
+	}
+}
+
+// sizeTolerance returns the tolerance to tessellate arcs of e,
+// which is 1/1000 of the size of e.
+func sizeTolerance(e Entity) float64 {
+	mins, maxs := e.BBox()
+	size := geometry.Length([]float64{maxs[0] - mins[0], maxs[1] - mins[1], maxs[2] - mins[2]})
+	if math.IsInf(size, 0) || math.IsNaN(size) || size == 0.0 {
+		return 1e-6
+	}
+	return size * 1e-3
+}
+
+// CanTransform returns an error if TransformEntity cannot transform e by m,
+// which is the case for an Insert whose block would be sheared
+// and for an Unknown whose coordinates are not known.
+func CanTransform(e Entity, m geometry.Matrix) error {
+	switch c := e.(type) {
+	case *Insert:
+		if c.sheared(m) {
+			return fmt.Errorf("INSERT of block %s cannot be sheared", c.BlockName)
+		}
+	case *Unknown:
+		if !c.Transformable() {
+			return fmt.Errorf("%s cannot be transformed", c.Name)
+		}
+	}
+	return nil
+}
+
+// TransformEntity transforms e by m and returns the result.
+// Transform of each entity expects m to keep circles in the plane of the entity circular,
+// so TransformEntity converts entities which would lose their shape otherwise:
+// a Circle or an Arc is returned as an Ellipse, and arc segments of an LwPolyline
+// or a 2D Polyline are tessellated into straight segments.
+// Any other entity is transformed in place.
+// If CanTransform returns an error, it is returned and e is not changed.
+func TransformEntity(e Entity, m geometry.Matrix) (Entity, error) {
+	if err := CanTransform(e, m); err != nil {
+		return nil, err
+	}
+	switch c := e.(type) {
+	case *Arc:
+		if !newOCSTransform(m, c.Direction).conformal() {
+			e = c.ToEllipse()
+		}
+	case *Circle:
+		if !newOCSTransform(m, c.Direction).conformal() {
+			e = c.ToEllipse()
+		}
+	case *LwPolyline:
+		if !newOCSTransform(m, c.Direction).conformal() {
+			c.tessellate(sizeTolerance(c))
+		}
+	case *Polyline:
+		if c.Is2D() && !newOCSTransform(m, c.Direction).conformal() {
+			c.tessellate(sizeTolerance(c))
+		}
+	}
+	e.Transform(m)
+	return e, nil
+}
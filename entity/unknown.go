@@ -0,0 +1,286 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+)
+
+// Unknown represents an entity which is not supported by this package.
+// Tags after the entity type and handle are written back as they are read,
+// except that the layer (code 8) is the current layer of the entity,
+// and handle references are remapped to the handles assigned on writing.
+// If the owner is set, it is written as the owner (code 330) instead of the one read.
+type Unknown struct {
+	*entity
+	Name string       // 0
+	Tags []format.Tag // all tags except 0 and 5
+}
+
+// IsEntity is for Entity interface.
+func (u *Unknown) IsEntity() bool {
+	return true
+}
+
+// NewUnknown creates a new Unknown entity of given type name.
+func NewUnknown(name string, tags []format.Tag) *Unknown {
+	u := &Unknown{
+		entity: NewEntity(-1),
+		Name:   name,
+		Tags:   tags,
+	}
+	return u
+}
+
+// Format writes data to formatter.
+func (u *Unknown) Format(f format.Formatter) {
+	f.WriteString(0, u.Name)
+	f.WriteHex(5, u.handle)
+	group, owned := false, false
+	for _, t := range u.Tags {
+		if t.Code == 102 {
+			group = strings.HasPrefix(t.Value, "{")
+		}
+		switch {
+		case t.Code == 8:
+			f.WriteString(8, u.layer.Name())
+		case t.Code == 330 && !group && !owned && u.owner != nil:
+			owned = true
+			f.WriteHex(330, u.owner.Handle())
+		case t.Ref != nil:
+			f.WriteHex(t.Code, t.Ref.Handle())
+		default:
+			f.WriteString(t.Code, t.Value)
+		}
+	}
+	u.FormatXData(f)
+}
+
+// String outputs data using default formatter.
+func (u *Unknown) String() string {
+	f := format.NewASCII()
+	return u.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (u *Unknown) FormatString(f format.Formatter) string {
+	u.Format(f)
+	return f.Output()
+}
+
+// points returns points given by codes 10-18, 20-28 and 30-38.
+func (u *Unknown) points() [][]float64 {
+	ps := make([][]float64, 0)
+	var p []float64
+	for _, t := range u.Tags {
+		if t.Code < 10 || t.Code > 38 || t.Code%10 > 8 {
+			continue
+		}
+		val, err := strconv.ParseFloat(strings.TrimSpace(t.Value), 64)
+		if err != nil {
+			continue
+		}
+		switch t.Code / 10 {
+		case 1:
+			p = []float64{val, 0.0, 0.0}
+			ps = append(ps, p)
+		case 2, 3:
+			if p != nil {
+				p[t.Code/10-1] = val
+			}
+		}
+	}
+	return ps
+}
+
+// BBox returns bounding box of coordinates found in tags.
+// It is only an approximation, since the meaning of the tags is unknown.
+func (u *Unknown) BBox() ([]float64, []float64) {
+	return pointsBBox(u.points())
+}
+
+// Clone returns a copy of Unknown.
+// References in tags are shared with the original.
+func (u *Unknown) Clone() Entity {
+	c := *u
+	c.entity = u.entity.clone()
+	c.Tags = format.CopyTags(u.Tags)
+	return &c
+}
+
+// Transformable reports whether the coordinates of Unknown can be transformed,
+// which are known for SOLID, TRACE, ATTRIB, ATTDEF, SHAPE, RAY, XLINE, IMAGE and WIPEOUT.
+func (u *Unknown) Transformable() bool {
+	switch u.Name {
+	case "SOLID", "TRACE", "ATTRIB", "ATTDEF", "SHAPE", "RAY", "XLINE", "IMAGE", "WIPEOUT":
+		return true
+	}
+	return false
+}
+
+// Transform transforms the coordinates in tags by m.
+// Points of SOLID, TRACE, ATTRIB, ATTDEF and SHAPE are in OCS of the extrusion direction (code 210),
+// and text height, rotation, width factor and oblique angle of ATTRIB, ATTDEF and SHAPE
+// are transformed as those of Text.
+// Other entities are not changed, unless Transformable reports true.
+// An object embedded in ATTRIB (multiline attribute) is not transformed.
+func (u *Unknown) Transform(m geometry.Matrix) {
+	switch u.Name {
+	case "SOLID", "TRACE":
+		t := newOCSTransform(m, u.direction())
+		for code := 10; code <= 13; code++ {
+			u.transformPoint(code, t.m.Apply)
+		}
+		if v, ok := u.float(39); ok {
+			u.setFloat(39, v*t.zscale, 0.0, -1)
+		}
+		u.setDirection(t.normal)
+	case "ATTRIB", "ATTDEF", "SHAPE":
+		u.transformText(m)
+	case "RAY", "XLINE":
+		u.transformPoint(10, m.Apply)
+		u.transformPoint(11, func(v []float64) []float64 {
+			return geometry.Normalize(m.ApplyVector(v))
+		})
+	case "IMAGE", "WIPEOUT":
+		u.transformPoint(10, m.Apply)
+		u.transformPoint(11, m.ApplyVector)
+		u.transformPoint(12, m.ApplyVector)
+	}
+}
+
+// transformText transforms ATTRIB, ATTDEF or SHAPE as Text.
+// Code 40 is the size and code 41 is the X scale factor of SHAPE.
+func (u *Unknown) transformText(m geometry.Matrix) {
+	t := &Text{
+		Coord1:      u.point(10),
+		Coord2:      u.point(11),
+		Direction:   u.direction(),
+		WidthFactor: 1.0,
+	}
+	t.Height, _ = u.float(40)
+	t.Rotation, _ = u.float(50)
+	t.ObliqueAngle, _ = u.float(51)
+	if v, ok := u.float(41); ok {
+		t.WidthFactor = v
+	}
+	t.Transform(m)
+	u.setPoint(10, t.Coord1)
+	if u.index(11) >= 0 {
+		u.setPoint(11, t.Coord2)
+	}
+	after := u.index(40)
+	u.setFloat(40, t.Height, 0.0, after)
+	u.setFloat(51, t.ObliqueAngle, 0.0, after)
+	u.setFloat(41, t.WidthFactor, 1.0, after)
+	u.setFloat(50, t.Rotation, 0.0, after)
+	u.setDirection(t.Direction)
+}
+
+// transformPoint applies f to the point given by code, code+10 and code+20, if any.
+func (u *Unknown) transformPoint(code int, f func([]float64) []float64) {
+	if u.index(code) < 0 {
+		return
+	}
+	u.setPoint(code, f(u.point(code)))
+}
+
+// index returns the index of the first tag of given code, or -1 if not found.
+// Tags of an embedded object (after code 101) are not searched.
+func (u *Unknown) index(code int) int {
+	for i, t := range u.Tags {
+		if t.Code == 101 {
+			break
+		}
+		if t.Code == code {
+			return i
+		}
+	}
+	return -1
+}
+
+// float returns the value of the first tag of given code.
+func (u *Unknown) float(code int) (float64, bool) {
+	i := u.index(code)
+	if i < 0 {
+		return 0.0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(u.Tags[i].Value), 64)
+	return v, err == nil
+}
+
+// setFloat sets the value of the first tag of given code.
+// If there is no such tag and v is not the default value def,
+// a new tag is inserted after the tag at index after, or at the end if after is negative.
+func (u *Unknown) setFloat(code int, v, def float64, after int) {
+	val := strconv.FormatFloat(v, 'f', -1, 64)
+	if i := u.index(code); i >= 0 {
+		u.Tags[i].Value = val
+		return
+	}
+	if v == def {
+		return
+	}
+	u.insert(after, format.Tag{Code: code, Value: val})
+}
+
+// insert inserts tags after the tag at index after, or at the end if after is negative.
+func (u *Unknown) insert(after int, tags ...format.Tag) {
+	if after < 0 || after >= len(u.Tags) {
+		after = len(u.Tags) - 1
+	}
+	rest := format.CopyTags(u.Tags[after+1:])
+	u.Tags = append(append(u.Tags[:after+1], tags...), rest...)
+}
+
+// point returns the point given by code, code+10 and code+20.
+func (u *Unknown) point(code int) []float64 {
+	p := make([]float64, 3)
+	for j := 0; j < 3; j++ {
+		p[j], _ = u.float(code + 10*j)
+	}
+	return p
+}
+
+// setPoint sets the point given by code, code+10 and code+20.
+func (u *Unknown) setPoint(code int, p []float64) {
+	for j := 0; j < 3; j++ {
+		u.setFloat(code+10*j, p[j], 0.0, u.index(code+10*j-10))
+	}
+}
+
+// direction returns the extrusion direction (code 210), which defaults to (0, 0, 1).
+func (u *Unknown) direction() []float64 {
+	if u.index(210) < 0 {
+		return []float64{0.0, 0.0, 1.0}
+	}
+	return u.point(210)
+}
+
+// setDirection sets the extrusion direction (code 210).
+// If there is no such tag, the tags are inserted after the last coordinate.
+func (u *Unknown) setDirection(d []float64) {
+	if u.index(210) >= 0 {
+		u.setPoint(210, d)
+		return
+	}
+	if isDefaultDirection(d) {
+		return
+	}
+	after := -1
+	for i, t := range u.Tags {
+		if t.Code == 101 {
+			break
+		}
+		if t.Code >= 10 && t.Code < 40 {
+			after = i
+		}
+	}
+	tags := make([]format.Tag, 3)
+	for j := 0; j < 3; j++ {
+		tags[j] = format.Tag{Code: 210 + 10*j, Value: strconv.FormatFloat(d[j], 'f', -1, 64)}
+	}
+	u.insert(after, tags...)
+}
@@ -2,13 +2,30 @@ package entity
 
 import (
 	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/geometry"
+)
+
+// Vertex flags (code 70).
+const (
+	VERTEX_EXTRA         = 1
+	VERTEX_CURVE_FIT     = 2
+	VERTEX_SPLINE        = 8
+	VERTEX_SPLINE_FRAME  = 16
+	VERTEX_3D_POLYLINE   = 32
+	VERTEX_POLYGON_MESH  = 64
+	VERTEX_POLYFACE_MESH = 128
 )
 
 // Vertex represents VERTEX Entity.
 type Vertex struct {
 	*entity
-	Flag  int
-	Coord []float64
+	Flag       int       // 70
+	Coord      []float64 // 10, 20, 30
+	StartWidth float64   // 40
+	EndWidth   float64   // 41
+	Bulge      float64   // 42
+	Tangent    float64   // 50 (Degree)
+	Indices    []int     // 71, 72, 73, 74: vertex indices of polyface mesh face record
 }
 
 // IsEntity is for Entity interface.
@@ -19,22 +36,61 @@ func (v *Vertex) IsEntity() bool {
 // NewVertex creates a new Vertex.
 func NewVertex(x, y, z float64) *Vertex {
 	v := &Vertex{
-		entity: NewEntity(VERTEX),
-		Flag:   32,
-		Coord:  []float64{x, y, z},
+		entity:  NewEntity(VERTEX),
+		Flag:    32,
+		Coord:   []float64{x, y, z},
+		Indices: []int{0, 0, 0, 0},
 	}
 	return v
 }
 
+// IsFaceRecord reports whether Vertex is a face record of polyface mesh.
+func (v *Vertex) IsFaceRecord() bool {
+	return v.Flag&VERTEX_POLYFACE_MESH != 0 && v.Flag&VERTEX_POLYGON_MESH == 0
+}
+
 // Format writes data to formatter.
 func (v *Vertex) Format(f format.Formatter) {
 	v.entity.Format(f)
-	f.WriteString(100, "AcDbVertex")
-	f.WriteString(100, "AcDb3dPolylineVertex")
+	if !v.IsFaceRecord() {
+		f.WriteString(100, "AcDbVertex")
+	}
+	switch {
+	case v.IsFaceRecord():
+		f.WriteString(100, "AcDbFaceRecord")
+	case v.Flag&VERTEX_POLYFACE_MESH != 0:
+		f.WriteString(100, "AcDbPolyFaceMeshVertex")
+	case v.Flag&VERTEX_POLYGON_MESH != 0:
+		f.WriteString(100, "AcDbPolygonMeshVertex")
+	case v.Flag&VERTEX_3D_POLYLINE != 0:
+		f.WriteString(100, "AcDb3dPolylineVertex")
+	default:
+		f.WriteString(100, "AcDb2dVertex")
+	}
 	for i := 0; i < 3; i++ {
 		f.WriteFloat((i+1)*10, v.Coord[i])
 	}
+	if v.StartWidth != 0.0 {
+		f.WriteFloat(40, v.StartWidth)
+	}
+	if v.EndWidth != 0.0 {
+		f.WriteFloat(41, v.EndWidth)
+	}
+	if v.Bulge != 0.0 {
+		f.WriteFloat(42, v.Bulge)
+	}
 	f.WriteInt(70, v.Flag)
+	if v.Flag&VERTEX_CURVE_FIT != 0 {
+		f.WriteFloat(50, v.Tangent)
+	}
+	if v.IsFaceRecord() {
+		for i, idx := range v.Indices {
+			if idx != 0 {
+				f.WriteInt(71+i, idx)
+			}
+		}
+	}
+	v.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -54,3 +110,18 @@ func (v *Vertex) BBox() ([]float64, []float64) {
 	maxs := []float64{v.Coord[0], v.Coord[1], v.Coord[2]}
 	return mins, maxs
 }
+
+// Clone returns a copy of Vertex.
+func (v *Vertex) Clone() Entity {
+	c := *v
+	c.entity = v.entity.clone()
+	c.Coord = copyPoint(v.Coord)
+	c.Indices = make([]int, len(v.Indices))
+	copy(c.Indices, v.Indices)
+	return &c
+}
+
+// Transform transforms Vertex by m.
+func (v *Vertex) Transform(m geometry.Matrix) {
+	v.Coord = m.Apply(v.Coord)
+}
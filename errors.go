@@ -0,0 +1,97 @@
+package dxf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrGroupCode is the cause of ParseError for a line which is not a group code.
+var ErrGroupCode = errors.New("invalid group code")
+
+// ParseError represents an error of reading DXF.
+// The cause is available through errors.Is and errors.As.
+type ParseError struct {
+	Section string // e.g. "ENTITIES", "" outside of sections
+	Type    string // entity, table record or object type, or header variable name
+	Handle  int    // handle of the record in the file, 0 if unknown
+	Code    int    // group code of the offending tag, -1 if it is not a valid group code
+	Value   string // raw value of the offending tag
+	Line    int    // 1-based line number of the offending group code
+	Err     error  // cause
+}
+
+// Error returns a description of the error.
+func (e *ParseError) Error() string {
+	s := fmt.Sprintf("line %d", e.Line)
+	if e.Section != "" {
+		s += ": " + e.Section
+	}
+	if e.Type != "" {
+		s += " " + e.Type
+	}
+	if e.Handle != 0 {
+		s += fmt.Sprintf(" (handle %X)", e.Handle)
+	}
+	if e.Code >= 0 {
+		s += fmt.Sprintf(": code %d", e.Code)
+	}
+	return s + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// tagError is an error caused by a tag, which is converted into ParseError
+// with the record information.
+type tagError struct {
+	tag Tag
+	err error
+}
+
+// newTagError creates a new tagError of tag t caused by err.
+func newTagError(t Tag, err error) *tagError {
+	return &tagError{tag: t, err: err}
+}
+
+// Error returns a description of the error.
+func (e *tagError) Error() string {
+	return fmt.Sprintf("code %d: %s", e.tag.Code, e.err.Error())
+}
+
+// Unwrap returns the cause.
+func (e *tagError) Unwrap() error {
+	return e.err
+}
+
+// parseError converts err which occurred while parsing the record data in section into ParseError.
+// The offending tag is the one of tagError, or the first tag of the record.
+func parseError(section string, data []Tag, err error) *ParseError {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return pe
+	}
+	e := &ParseError{
+		Section: section,
+		Code:    -1,
+		Err:     err,
+	}
+	if len(data) > 0 {
+		if data[0].Code == 0 || data[0].Code == 9 {
+			e.Type = data[0].Value
+		}
+		e.Handle = recordHandle(data)
+		e.Code = data[0].Code
+		e.Value = data[0].Value
+		e.Line = data[0].Line
+	}
+	var te *tagError
+	if errors.As(err, &te) {
+		e.Code = te.tag.Code
+		e.Value = te.tag.Value
+		e.Line = te.tag.Line
+		e.Err = te.err
+	}
+	return e
+}
@@ -0,0 +1,205 @@
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BinarySentinel is the 22 byte sequence every binary DXF file starts with.
+const BinarySentinel = "AutoCAD Binary DXF\r\n\x1a\x00"
+
+// binaryChunkSize is the maximum length of each binary chunk (code 310-319, 1004).
+const binaryChunkSize = 127
+
+// Binary is Formatter for binary format.
+type Binary struct {
+	buffer bytes.Buffer
+	float  string
+	narrow bool // 1 byte group codes before R13
+}
+
+// NewBinary creates a new Binary formatter.
+func NewBinary() *Binary {
+	var b bytes.Buffer
+	return &Binary{
+		buffer: b,
+		float:  "%.6f",
+	}
+}
+
+// Reset resets the buffer.
+func (f *Binary) Reset() {
+	f.buffer.Reset()
+}
+
+// WriteTo writes the sentinel and data stored in the buffer to w.
+func (f *Binary) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, BinarySentinel)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := f.buffer.WriteTo(w)
+	return int64(n) + m, err
+}
+
+// SetPrecision sets precision part for floating point values
+// which have to be written as strings.
+func (f *Binary) SetPrecision(p int) {
+	f.float = fmt.Sprintf("%%.%df", p)
+}
+
+// Output outputs data stored in the buffer in binary DXF format.
+func (f *Binary) Output() string {
+	rtn := f.buffer.String()
+	f.buffer.Reset()
+	return rtn
+}
+
+// SetVersion sets the DXF version to write.
+// Before R13, group codes are written in 1 byte.
+func (f *Binary) SetVersion(v Version) {
+	f.narrow = v < R13
+}
+
+// code encodes a group code as a little-endian 16-bit integer.
+// Before R13, it is a byte, and codes above 254 are 255 followed by 16-bit integer.
+func (f *Binary) code(num int) []byte {
+	if f.narrow {
+		if num >= 0 && num < 255 {
+			return []byte{byte(num)}
+		}
+		b := []byte{255, 0, 0}
+		binary.LittleEndian.PutUint16(b[1:], uint16(num))
+		return b
+	}
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, uint16(num))
+	return b
+}
+
+// integer encodes an integer value according to the group code type.
+func (f *Binary) integer(num int, val int64) []byte {
+	var b []byte
+	switch CodeType(num) {
+	case BOOL:
+		b = []byte{byte(val)}
+	case INT16:
+		b = make([]byte, 2)
+		binary.LittleEndian.PutUint16(b, uint16(val))
+	case INT32:
+		b = make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, uint32(val))
+	case INT64:
+		b = make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(val))
+	case FLOAT:
+		return f.double(float64(val))
+	default:
+		return f.str(strconv.FormatInt(val, 10))
+	}
+	return b
+}
+
+// double encodes a floating point value as a little-endian double.
+func (f *Binary) double(val float64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, math.Float64bits(val))
+	return b
+}
+
+// str encodes a string value as a null-terminated string.
+func (f *Binary) str(val string) []byte {
+	return append([]byte(val), 0)
+}
+
+// chunk encodes a hex string as a length-prefixed binary chunk.
+func (f *Binary) chunk(num int, val string) []byte {
+	data, err := hex.DecodeString(strings.TrimSpace(val))
+	if err != nil {
+		data = []byte{}
+	}
+	var b []byte
+	for {
+		size := len(data)
+		if size > binaryChunkSize {
+			size = binaryChunkSize
+		}
+		b = append(b, byte(size))
+		b = append(b, data[:size]...)
+		data = data[size:]
+		if len(data) == 0 {
+			return b
+		}
+		b = append(b, f.code(num)...)
+	}
+}
+
+// String outputs given code & string in binary DXF format.
+// Values of numeric group codes are converted to their binary representation.
+func (f *Binary) String(num int, val string) string {
+	b := f.code(num)
+	switch CodeType(num) {
+	case FLOAT:
+		v, _ := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		b = append(b, f.double(v)...)
+	case BOOL, INT16, INT32, INT64:
+		v, _ := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		b = append(b, f.integer(num, v)...)
+	case BINARY:
+		b = append(b, f.chunk(num, val)...)
+	default:
+		b = append(b, f.str(val)...)
+	}
+	return string(b)
+}
+
+// Hex outputs given code & hex in binary DXF format.
+// It is used for outputting handles.
+func (f *Binary) Hex(num int, h int) string {
+	return string(append(f.code(num), f.str(fmt.Sprintf("%X", h))...))
+}
+
+// Int outputs given code & int in binary DXF format.
+func (f *Binary) Int(num int, val int) string {
+	return string(append(f.code(num), f.integer(num, int64(val))...))
+}
+
+// Float outputs given code & floating point in binary DXF format.
+func (f *Binary) Float(num int, val float64) string {
+	b := f.code(num)
+	switch CodeType(num) {
+	case FLOAT:
+		b = append(b, f.double(val)...)
+	case BOOL, INT16, INT32, INT64:
+		b = append(b, f.integer(num, int64(val))...)
+	default:
+		b = append(b, f.str(fmt.Sprintf(f.float, val))...)
+	}
+	return string(b)
+}
+
+// WriteString appends string data to the buffer.
+func (f *Binary) WriteString(num int, val string) {
+	f.buffer.WriteString(f.String(num, val))
+}
+
+// WriteHex appends hex data to the buffer.
+func (f *Binary) WriteHex(num int, h int) {
+	f.buffer.WriteString(f.Hex(num, h))
+}
+
+// WriteInt appends int data to the buffer.
+func (f *Binary) WriteInt(num int, val int) {
+	f.buffer.WriteString(f.Int(num, val))
+}
+
+// WriteFloat appends floating point data to the buffer.
+func (f *Binary) WriteFloat(num int, val float64) {
+	f.buffer.WriteString(f.Float(num, val))
+}
@@ -0,0 +1,98 @@
+package format
+
+// ValueType represents the type of value associated with a group code.
+type ValueType int
+
+// Group code value types.
+const (
+	STRING ValueType = iota
+	FLOAT
+	INT16
+	INT32
+	INT64
+	BOOL
+	HANDLE
+	BINARY
+)
+
+// CodeType returns the type of value associated with the given group code.
+//
+//	0-9:       String
+//	10-59:     Double precision floating point value
+//	60-79:     16-bit integer value
+//	90-99:     32-bit integer value
+//	105:       Handle
+//	110-149:   Double precision floating point value
+//	160-169:   64-bit integer value
+//	170-179:   16-bit integer value
+//	210-239:   Double precision floating point value
+//	270-289:   16-bit integer value
+//	290-299:   Boolean flag value
+//	310-319:   Binary chunk
+//	320-369:   Handle
+//	370-389:   16-bit integer value
+//	390-399:   Handle
+//	400-409:   16-bit integer value
+//	420-429:   32-bit integer value
+//	440-459:   32-bit integer value
+//	460-469:   Double precision floating point value
+//	480-481:   Handle
+//	1004:      Binary chunk
+//	1005:      Handle
+//	1010-1059: Double precision floating point value
+//	1060-1070: 16-bit integer value
+//	1071:      32-bit integer value
+func CodeType(code int) ValueType {
+	switch {
+	case code >= 10 && code <= 59:
+		return FLOAT
+	case code >= 60 && code <= 79:
+		return INT16
+	case code >= 90 && code <= 99:
+		return INT32
+	case code == 105:
+		return HANDLE
+	case code >= 110 && code <= 149:
+		return FLOAT
+	case code >= 160 && code <= 169:
+		return INT64
+	case code >= 170 && code <= 179:
+		return INT16
+	case code >= 210 && code <= 239:
+		return FLOAT
+	case code >= 270 && code <= 289:
+		return INT16
+	case code >= 290 && code <= 299:
+		return BOOL
+	case code >= 310 && code <= 319:
+		return BINARY
+	case code >= 320 && code <= 369:
+		return HANDLE
+	case code >= 370 && code <= 389:
+		return INT16
+	case code >= 390 && code <= 399:
+		return HANDLE
+	case code >= 400 && code <= 409:
+		return INT16
+	case code >= 420 && code <= 429:
+		return INT32
+	case code >= 440 && code <= 459:
+		return INT32
+	case code >= 460 && code <= 469:
+		return FLOAT
+	case code == 480 || code == 481:
+		return HANDLE
+	case code == 1004:
+		return BINARY
+	case code == 1005:
+		return HANDLE
+	case code >= 1010 && code <= 1059:
+		return FLOAT
+	case code >= 1060 && code <= 1070:
+		return INT16
+	case code == 1071:
+		return INT32
+	default:
+		return STRING
+	}
+}
@@ -0,0 +1,36 @@
+package format
+
+import (
+	"github.com/flywave/go-dxf/handle"
+)
+
+// Tag represents a raw pair of group code and value,
+// kept for data which is written back as it is read.
+// If Ref is not nil, the value is a handle and the current handle of Ref is written instead.
+type Tag struct {
+	Code  int
+	Value string
+	Ref   handle.Handler
+}
+
+// WriteTags writes tags to formatter.
+func WriteTags(f Formatter, tags []Tag) {
+	for _, t := range tags {
+		if t.Ref != nil {
+			f.WriteHex(t.Code, t.Ref.Handle())
+			continue
+		}
+		f.WriteString(t.Code, t.Value)
+	}
+}
+
+// CopyTags returns a copy of tags.
+// References are shared with the original.
+func CopyTags(tags []Tag) []Tag {
+	if tags == nil {
+		return nil
+	}
+	c := make([]Tag, len(tags))
+	copy(c, tags)
+	return c
+}
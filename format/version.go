@@ -0,0 +1,51 @@
+package format
+
+import (
+	"fmt"
+)
+
+// Version is DXF version written as $ACADVER.
+type Version int
+
+// DXF versions
+const (
+	R12   Version = iota + 1 // AC1009
+	R13                      // AC1012
+	R14                      // AC1014
+	R2000                    // AC1015
+	R2004                    // AC1018
+	R2007                    // AC1021
+	R2010                    // AC1024
+	R2013                    // AC1027
+	R2018                    // AC1032
+)
+
+var versionNames = []string{
+	R12:   "AC1009",
+	R13:   "AC1012",
+	R14:   "AC1014",
+	R2000: "AC1015",
+	R2004: "AC1018",
+	R2007: "AC1021",
+	R2010: "AC1024",
+	R2013: "AC1027",
+	R2018: "AC1032",
+}
+
+// String returns $ACADVER value of the version (e.g. "AC1015").
+func (v Version) String() string {
+	if v < R12 || v > R2018 {
+		return ""
+	}
+	return versionNames[v]
+}
+
+// ParseVersion returns Version of $ACADVER value.
+func ParseVersion(s string) (Version, error) {
+	for v := R12; v <= R2018; v++ {
+		if versionNames[v] == s {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown version: %s", s)
+}
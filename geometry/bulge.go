@@ -0,0 +1,75 @@
+package geometry
+
+import (
+	"math"
+)
+
+// BulgeArc returns the arc between p1 and p2 of given bulge.
+// Bulge is the tangent of 1/4 of the included angle; negative bulge means clockwise.
+// The arc starts at p1 with angle start (Radian) and sweeps by sweep (Radian, signed).
+// If bulge is 0, radius is 0 and the segment is a straight line.
+func BulgeArc(p1, p2 []float64, bulge float64) (center []float64, radius, start, sweep float64) {
+	if bulge == 0.0 {
+		return nil, 0.0, 0.0, 0.0
+	}
+	dx := p2[0] - p1[0]
+	dy := p2[1] - p1[1]
+	chord := math.Hypot(dx, dy)
+	if chord == 0.0 {
+		return nil, 0.0, 0.0, 0.0
+	}
+	sweep = 4.0 * math.Atan(bulge)
+	radius = chord * (1.0 + bulge*bulge) / (4.0 * math.Abs(bulge))
+	// signed distance from the chord midpoint to the center, to the left of p1->p2
+	h := chord * (1.0 - bulge*bulge) / (4.0 * bulge)
+	center = []float64{
+		(p1[0]+p2[0])/2.0 - h*dy/chord,
+		(p1[1]+p2[1])/2.0 + h*dx/chord,
+	}
+	start = math.Atan2(p1[1]-center[1], p1[0]-center[0])
+	return center, radius, start, sweep
+}
+
+// BulgePoints tessellates the segment between p1 and p2 of given bulge.
+// The deviation from the arc is kept within tolerance.
+// Returned points include p1 but not p2.
+func BulgePoints(p1, p2 []float64, bulge, tolerance float64) [][]float64 {
+	center, radius, start, sweep := BulgeArc(p1, p2, bulge)
+	if center == nil {
+		return [][]float64{{p1[0], p1[1]}}
+	}
+	n := arcSegments(radius, sweep, tolerance)
+	ps := make([][]float64, n)
+	ps[0] = []float64{p1[0], p1[1]}
+	for i := 1; i < n; i++ {
+		a := start + sweep*float64(i)/float64(n)
+		ps[i] = []float64{center[0] + radius*math.Cos(a), center[1] + radius*math.Sin(a)}
+	}
+	return ps
+}
+
+// arcSegments returns the number of segments approximating an arc within tolerance.
+func arcSegments(radius, sweep, tolerance float64) int {
+	if tolerance <= 0.0 || tolerance >= radius {
+		return int(math.Ceil(math.Abs(sweep) / (math.Pi / 2.0)))
+	}
+	step := 2.0 * math.Acos(1.0-tolerance/radius)
+	n := int(math.Ceil(math.Abs(sweep) / step))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// AngleInSweep reports whether angle a (Radian) is on the arc from start sweeping by sweep.
+func AngleInSweep(a, start, sweep float64) bool {
+	if sweep < 0.0 {
+		start += sweep
+		sweep = -sweep
+	}
+	d := math.Mod(a-start, 2.0*math.Pi)
+	if d < 0.0 {
+		d += 2.0 * math.Pi
+	}
+	return d <= sweep
+}
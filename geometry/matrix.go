@@ -0,0 +1,182 @@
+package geometry
+
+import (
+	"errors"
+	"math"
+)
+
+// Matrix represents a 4x4 affine transformation matrix in row-major order.
+// Points are treated as column vectors, so p' = M * p.
+type Matrix [4][4]float64
+
+// Identity returns an identity matrix.
+func Identity() Matrix {
+	return Matrix{
+		{1.0, 0.0, 0.0, 0.0},
+		{0.0, 1.0, 0.0, 0.0},
+		{0.0, 0.0, 1.0, 0.0},
+		{0.0, 0.0, 0.0, 1.0},
+	}
+}
+
+// Translation returns a matrix which moves points by (x, y, z).
+func Translation(x, y, z float64) Matrix {
+	m := Identity()
+	m[0][3] = x
+	m[1][3] = y
+	m[2][3] = z
+	return m
+}
+
+// Scaling returns a matrix which scales points by (x, y, z) about the origin.
+func Scaling(x, y, z float64) Matrix {
+	m := Identity()
+	m[0][0] = x
+	m[1][1] = y
+	m[2][2] = z
+	return m
+}
+
+// RotationZ returns a matrix which rotates points about Z axis by angle (Radian).
+func RotationZ(angle float64) Matrix {
+	s, c := math.Sincos(angle)
+	m := Identity()
+	m[0][0] = c
+	m[0][1] = -s
+	m[1][0] = s
+	m[1][1] = c
+	return m
+}
+
+// Rotation returns a matrix which rotates points about axis through the origin
+// by angle (Radian), counterclockwise when looking from the tip of axis.
+func Rotation(axis []float64, angle float64) Matrix {
+	u := Normalize(axis)
+	m := Identity()
+	if Length(u) == 0.0 {
+		return m
+	}
+	s, c := math.Sincos(angle)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m[i][j] = (1.0 - c) * u[i] * u[j]
+		}
+		m[i][i] += c
+	}
+	m[0][1] -= s * u[2]
+	m[0][2] += s * u[1]
+	m[1][0] += s * u[2]
+	m[1][2] -= s * u[0]
+	m[2][0] -= s * u[1]
+	m[2][1] += s * u[0]
+	return m
+}
+
+// Mirroring returns a matrix which reflects points about the plane
+// through the origin with given normal.
+func Mirroring(normal []float64) Matrix {
+	n := Normalize(normal)
+	m := Identity()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m[i][j] -= 2.0 * n[i] * n[j]
+		}
+	}
+	return m
+}
+
+// OCS returns a matrix which converts OCS coordinates of given extrusion direction
+// to WCS coordinates using Arbitrary Axis Algorithm.
+func OCS(d []float64) Matrix {
+	m := Identity()
+	az := Normalize(d)
+	ax, ay, err := ArbitraryAxis(az)
+	if err != nil {
+		return m
+	}
+	for i := 0; i < 3; i++ {
+		m[i][0] = ax[i]
+		m[i][1] = ay[i]
+		m[i][2] = az[i]
+	}
+	return m
+}
+
+// Multiply returns m * n, which applies n first, then m.
+func (m Matrix) Multiply(n Matrix) Matrix {
+	var r Matrix
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				r[i][j] += m[i][k] * n[k][j]
+			}
+		}
+	}
+	return r
+}
+
+// Apply transforms point p.
+func (m Matrix) Apply(p []float64) []float64 {
+	r := make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		r[i] = m[i][3]
+		for j := 0; j < 3 && j < len(p); j++ {
+			r[i] += m[i][j] * p[j]
+		}
+	}
+	return r
+}
+
+// ApplyVector transforms direction vector v, ignoring translation.
+func (m Matrix) ApplyVector(v []float64) []float64 {
+	r := make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3 && j < len(v); j++ {
+			r[i] += m[i][j] * v[j]
+		}
+	}
+	return r
+}
+
+// Transpose returns the transposed matrix.
+func (m Matrix) Transpose() Matrix {
+	var r Matrix
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			r[i][j] = m[j][i]
+		}
+	}
+	return r
+}
+
+// Determinant returns the determinant of the linear (upper-left 3x3) part.
+// A negative value means the matrix mirrors geometry.
+func (m Matrix) Determinant() float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+// Inverse returns the inverse of an affine matrix.
+func (m Matrix) Inverse() (Matrix, error) {
+	det := m.Determinant()
+	if math.Abs(det) < 1e-15 {
+		return Identity(), errors.New("matrix is not invertible")
+	}
+	r := Identity()
+	r[0][0] = (m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det
+	r[0][1] = (m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det
+	r[0][2] = (m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det
+	r[1][0] = (m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det
+	r[1][1] = (m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det
+	r[1][2] = (m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det
+	r[2][0] = (m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det
+	r[2][1] = (m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det
+	r[2][2] = (m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][3] -= r[i][j] * m[j][3]
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,283 @@
+package geometry
+
+import (
+	"errors"
+	"math"
+)
+
+// maxSubdivision is the maximum depth of adaptive tessellation.
+const maxSubdivision = 24
+
+// NURBS represents a non-uniform rational B-spline curve.
+type NURBS struct {
+	Degree   int
+	Knots    []float64
+	Controls [][]float64
+	Weights  []float64
+}
+
+// NewNURBS creates a new NURBS curve.
+// If knots is empty, clamped uniform knots are generated.
+// If weights is empty, all weights are 1.
+func NewNURBS(degree int, knots []float64, controls [][]float64, weights []float64) (*NURBS, error) {
+	if degree < 1 {
+		return nil, errors.New("degree must be positive")
+	}
+	if len(controls) < degree+1 {
+		return nil, errors.New("not enough control points")
+	}
+	if len(knots) == 0 {
+		knots = clampedKnots(degree, len(controls))
+	}
+	if len(knots) != len(controls)+degree+1 {
+		return nil, errors.New("number of knots must be number of control points + degree + 1")
+	}
+	for i := 1; i < len(knots); i++ {
+		if knots[i] < knots[i-1] {
+			return nil, errors.New("knots must be non-decreasing")
+		}
+	}
+	if len(weights) == 0 {
+		weights = make([]float64, len(controls))
+		for i := range weights {
+			weights[i] = 1.0
+		}
+	}
+	if len(weights) != len(controls) {
+		return nil, errors.New("number of weights must be number of control points")
+	}
+	return &NURBS{Degree: degree, Knots: knots, Controls: controls, Weights: weights}, nil
+}
+
+// clampedKnots returns clamped uniform knots in range [0, 1].
+func clampedKnots(degree, size int) []float64 {
+	knots := make([]float64, size+degree+1)
+	inner := size - degree
+	for i := range knots {
+		switch {
+		case i <= degree:
+			knots[i] = 0.0
+		case i >= size:
+			knots[i] = 1.0
+		default:
+			knots[i] = float64(i-degree) / float64(inner)
+		}
+	}
+	return knots
+}
+
+// Domain returns the valid parameter range.
+func (n *NURBS) Domain() (float64, float64) {
+	return n.Knots[n.Degree], n.Knots[len(n.Controls)]
+}
+
+// span returns the knot span index containing u.
+func (n *NURBS) span(u float64) int {
+	last := len(n.Controls) - 1
+	if u >= n.Knots[last+1] {
+		// the last non-empty span
+		for i := last; i > n.Degree; i-- {
+			if n.Knots[i] < n.Knots[i+1] {
+				return i
+			}
+		}
+		return last
+	}
+	if u <= n.Knots[n.Degree] {
+		return n.Degree
+	}
+	lo, hi := n.Degree, last+1
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		if u < n.Knots[mid] {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return lo
+}
+
+// basis returns the non-zero basis functions and their first derivatives at u in span i.
+func (n *NURBS) basis(i int, u float64) ([]float64, []float64) {
+	p := n.Degree
+	// ndu holds basis functions in upper triangle and knot differences in lower triangle.
+	ndu := make([][]float64, p+1)
+	for j := range ndu {
+		ndu[j] = make([]float64, p+1)
+	}
+	left := make([]float64, p+1)
+	right := make([]float64, p+1)
+	ndu[0][0] = 1.0
+	for j := 1; j <= p; j++ {
+		left[j] = u - n.Knots[i+1-j]
+		right[j] = n.Knots[i+j] - u
+		saved := 0.0
+		for r := 0; r < j; r++ {
+			ndu[j][r] = right[r+1] + left[j-r]
+			temp := 0.0
+			if ndu[j][r] != 0.0 {
+				temp = ndu[r][j-1] / ndu[j][r]
+			}
+			ndu[r][j] = saved + right[r+1]*temp
+			saved = left[j-r] * temp
+		}
+		ndu[j][j] = saved
+	}
+	ns := make([]float64, p+1)
+	ds := make([]float64, p+1)
+	for j := 0; j <= p; j++ {
+		ns[j] = ndu[j][p]
+	}
+	for r := 0; r <= p; r++ {
+		d := 0.0
+		if r >= 1 && ndu[p][r-1] != 0.0 {
+			d += ndu[r-1][p-1] / ndu[p][r-1]
+		}
+		if r <= p-1 && ndu[p][r] != 0.0 {
+			d -= ndu[r][p-1] / ndu[p][r]
+		}
+		ds[r] = d * float64(p)
+	}
+	return ns, ds
+}
+
+// evaluate returns the point and the first derivative at u.
+func (n *NURBS) evaluate(u float64) ([]float64, []float64) {
+	i := n.span(u)
+	ns, ds := n.basis(i, u)
+	a := make([]float64, 3)
+	da := make([]float64, 3)
+	w, dw := 0.0, 0.0
+	for j := 0; j <= n.Degree; j++ {
+		k := i - n.Degree + j
+		wk := n.Weights[k]
+		c := n.Controls[k]
+		for d := 0; d < 3 && d < len(c); d++ {
+			a[d] += ns[j] * wk * c[d]
+			da[d] += ds[j] * wk * c[d]
+		}
+		w += ns[j] * wk
+		dw += ds[j] * wk
+	}
+	p := make([]float64, 3)
+	dp := make([]float64, 3)
+	if w == 0.0 {
+		return p, dp
+	}
+	for d := 0; d < 3; d++ {
+		p[d] = a[d] / w
+		dp[d] = (da[d] - dw*p[d]) / w
+	}
+	return p, dp
+}
+
+// Point returns the point at parameter u.
+func (n *NURBS) Point(u float64) []float64 {
+	p, _ := n.evaluate(u)
+	return p
+}
+
+// Derivative returns the first derivative at parameter u.
+func (n *NURBS) Derivative(u float64) []float64 {
+	_, d := n.evaluate(u)
+	return d
+}
+
+// spans returns parameter ranges of non-empty knot spans.
+func (n *NURBS) spans() [][2]float64 {
+	rs := make([][2]float64, 0)
+	for i := n.Degree; i < len(n.Controls); i++ {
+		if n.Knots[i] < n.Knots[i+1] {
+			rs = append(rs, [2]float64{n.Knots[i], n.Knots[i+1]})
+		}
+	}
+	return rs
+}
+
+// Tessellate approximates the curve by a polyline whose distance
+// from the curve is within tolerance.
+func (n *NURBS) Tessellate(tolerance float64) [][]float64 {
+	start, _ := n.Domain()
+	ps := [][]float64{n.Point(start)}
+	for _, r := range n.spans() {
+		// split each span into degree pieces first, so that symmetric curves are not missed
+		pieces := n.Degree + 1
+		for k := 0; k < pieces; k++ {
+			a := r[0] + (r[1]-r[0])*float64(k)/float64(pieces)
+			b := r[0] + (r[1]-r[0])*float64(k+1)/float64(pieces)
+			ps = n.subdivide(ps, a, b, n.Point(a), n.Point(b), tolerance, 0)
+		}
+	}
+	return ps
+}
+
+// subdivide appends points of range (a, b] to ps.
+func (n *NURBS) subdivide(ps [][]float64, a, b float64, pa, pb []float64, tolerance float64, depth int) [][]float64 {
+	m := (a + b) / 2.0
+	pm := n.Point(m)
+	if depth >= maxSubdivision || distanceToSegment(pm, pa, pb) <= tolerance {
+		return append(ps, pb)
+	}
+	ps = n.subdivide(ps, a, m, pa, pm, tolerance, depth+1)
+	return n.subdivide(ps, m, b, pm, pb, tolerance, depth+1)
+}
+
+// BBox returns the bounding box of the curve.
+// Extremes are found where a component of the derivative is zero.
+func (n *NURBS) BBox() ([]float64, []float64) {
+	mins := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+	maxs := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	add := func(p []float64) {
+		for i := 0; i < 3; i++ {
+			mins[i] = math.Min(mins[i], p[i])
+			maxs[i] = math.Max(maxs[i], p[i])
+		}
+	}
+	start, end := n.Domain()
+	add(n.Point(start))
+	add(n.Point(end))
+	samples := 4 * (n.Degree + 1)
+	for _, r := range n.spans() {
+		prev := n.Derivative(r[0])
+		a := r[0]
+		for k := 1; k <= samples; k++ {
+			b := r[0] + (r[1]-r[0])*float64(k)/float64(samples)
+			next := n.Derivative(b)
+			for i := 0; i < 3; i++ {
+				if prev[i]*next[i] < 0.0 {
+					add(n.Point(n.root(i, a, b, prev[i])))
+				}
+			}
+			a, prev = b, next
+		}
+	}
+	return mins, maxs
+}
+
+// root finds the parameter in (a, b) where the i-th component of the derivative is zero
+// by bisection. da is the derivative component at a.
+func (n *NURBS) root(i int, a, b, da float64) float64 {
+	for k := 0; k < 60 && b-a > 1e-15; k++ {
+		m := (a + b) / 2.0
+		dm := n.Derivative(m)[i]
+		if dm*da > 0.0 {
+			a, da = m, dm
+		} else {
+			b = m
+		}
+	}
+	return (a + b) / 2.0
+}
+
+// distanceToSegment returns the distance from p to segment ab.
+func distanceToSegment(p, a, b []float64) float64 {
+	ab := []float64{b[0] - a[0], b[1] - a[1], b[2] - a[2]}
+	ap := []float64{p[0] - a[0], p[1] - a[1], p[2] - a[2]}
+	l := Dot(ab, ab)
+	if l == 0.0 {
+		return Length(ap)
+	}
+	t := math.Max(0.0, math.Min(1.0, Dot(ap, ab)/l))
+	return Length([]float64{ap[0] - t*ab[0], ap[1] - t*ab[1], ap[2] - t*ab[2]})
+}
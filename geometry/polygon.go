@@ -0,0 +1,48 @@
+package geometry
+
+import (
+	"math"
+)
+
+// PointInPolygon reports whether 2D point p is inside ring using even-odd rule.
+func PointInPolygon(p []float64, ring [][]float64) bool {
+	in := false
+	n := len(ring)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) &&
+			p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			in = !in
+		}
+	}
+	return in
+}
+
+// PolygonArea returns signed area of 2D ring.
+// It is positive if ring is counterclockwise.
+func PolygonArea(ring [][]float64) float64 {
+	sum := 0.0
+	n := len(ring)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		sum += ring[j][0]*ring[i][1] - ring[i][0]*ring[j][1]
+	}
+	return sum / 2.0
+}
+
+// ArcPoints tessellates 2D arc of center and radius from start sweeping by sweep (Radian)
+// within tolerance. Returned points include both ends.
+// If sweep is 0, the two coincident ends are returned.
+func ArcPoints(center []float64, radius, start, sweep, tolerance float64) [][]float64 {
+	if sweep == 0.0 {
+		s, c := math.Sincos(start)
+		p := []float64{center[0] + radius*c, center[1] + radius*s}
+		return [][]float64{p, {p[0], p[1]}}
+	}
+	n := arcSegments(radius, sweep, tolerance)
+	ps := make([][]float64, n+1)
+	for i := 0; i <= n; i++ {
+		s, c := math.Sincos(start + sweep*float64(i)/float64(n))
+		ps[i] = []float64{center[0] + radius*c, center[1] + radius*s}
+	}
+	return ps
+}
@@ -0,0 +1,43 @@
+package geometry
+
+import (
+	"math"
+)
+
+// Normalize returns unit-length vector of v.
+// If v is zero vector, it returns v as it is.
+func Normalize(v []float64) []float64 {
+	r := make([]float64, 3)
+	l := Length(v)
+	for i := 0; i < 3 && i < len(v); i++ {
+		if l == 0.0 {
+			r[i] = v[i]
+		} else {
+			r[i] = v[i] / l
+		}
+	}
+	return r
+}
+
+// Length returns length of vector v.
+func Length(v []float64) float64 {
+	sum := 0.0
+	for i := 0; i < 3 && i < len(v); i++ {
+		sum += v[i] * v[i]
+	}
+	return math.Sqrt(sum)
+}
+
+// Cross returns cross product of a and b.
+func Cross(a, b []float64) []float64 {
+	return []float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+// Dot returns dot product of a and b.
+func Dot(a, b []float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
@@ -7,6 +7,7 @@ require (
 	github.com/flywave/go3d v0.0.0-20231225024313-0ec3c9c64b46
 	github.com/pborman/uuid v1.2.1
 	github.com/tidwall/rtree v1.10.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -16,3 +16,16 @@ type Handler interface {
 	Handle() int
 	SetHandle(*int)
 }
+
+// Ref is a Handler which refers to a handle value as it is,
+// used for references to objects not loaded into a drawing.
+type Ref int
+
+// Handle returns the handle value.
+func (r Ref) Handle() int {
+	return int(r)
+}
+
+// SetHandle does nothing, since the referred handle is fixed.
+func (r Ref) SetHandle(v *int) {
+}
@@ -7,6 +7,8 @@ import (
 )
 
 // Header contains information written in HEADER section.
+// Well-known variables are exposed as fields, and the others are kept as typed values.
+// Use Get and Set to access any variable by name.
 type Header struct {
 	Version  string
 	InsBase  []float64
@@ -15,7 +17,24 @@ type Header struct {
 	ExtMin   []float64
 	ExtMax   []float64
 	LtScale  float64
+
+	CLayer          string  // $CLAYER: current layer name
+	CeLType         string  // $CELTYPE: current linetype name
+	TextStyle       string  // $TEXTSTYLE: current text style name
+	DimStyle        string  // $DIMSTYLE: current dimension style name
+	DwgCodePage     string  // $DWGCODEPAGE: drawing code page (e.g. ANSI_1252)
+	TdCreate        float64 // $TDCREATE: local date/time of creation (Julian date)
+	TdUpdate        float64 // $TDUPDATE: local date/time of last update (Julian date)
+	Measurement     int     // $MEASUREMENT: 0 = English, 1 = Metric
+	PdMode          int     // $PDMODE: point display mode
+	PdSize          float64 // $PDSIZE: point display size
+	TextSize        float64 // $TEXTSIZE: default text height
+	FingerprintGUID string  // $FINGERPRINTGUID
+	VersionGUID     string  // $VERSIONGUID
+
 	handseed int
+	names    []string           // variables read or set, in the order
+	values   map[string][]Value // variables not exposed as fields
 }
 
 // New creates a new Header.
@@ -54,6 +73,7 @@ func (h *Header) Format(f format.Formatter) {
 	}
 	f.WriteString(9, "$LTSCALE")
 	f.WriteFloat(40, h.LtScale)
+	h.formatVariables(f)
 	f.WriteString(9, "$HANDSEED")
 	f.WriteHex(5, h.handseed)
 	f.WriteString(0, "ENDSEC")
@@ -63,3 +83,8 @@ func (h *Header) Format(f format.Formatter) {
 func (h *Header) SetHandle(v *int) {
 	h.handseed = *v
 }
+
+// HandSeed returns $HANDSEED, which is read from a file or set on writing.
+func (h *Header) HandSeed() int {
+	return h.handseed
+}
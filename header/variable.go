@@ -0,0 +1,325 @@
+package header
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/insunit"
+)
+
+// Value represents a value of header variable with its group code.
+// The type of Value is one of string, float64, int, int64, bool or []byte.
+// Each coordinate of a point is a separate Value (code 10, 20, 30).
+type Value struct {
+	Code  int
+	Value interface{}
+}
+
+// Format writes data to formatter.
+func (v Value) Format(f format.Formatter) {
+	switch val := v.Value.(type) {
+	case string:
+		f.WriteString(v.Code, val)
+	case float64:
+		f.WriteFloat(v.Code, val)
+	case int:
+		if format.CodeType(v.Code) == format.HANDLE {
+			f.WriteHex(v.Code, val)
+		} else {
+			f.WriteInt(v.Code, val)
+		}
+	case int64:
+		f.WriteString(v.Code, strconv.FormatInt(val, 10))
+	case bool:
+		if val {
+			f.WriteInt(v.Code, 1)
+		} else {
+			f.WriteInt(v.Code, 0)
+		}
+	case []byte:
+		f.WriteString(v.Code, strings.ToUpper(hex.EncodeToString(val)))
+	}
+}
+
+// wellKnown is the list of variables exposed as fields of Header,
+// except those always written ($ACADVER, $INSBASE, $INSUNITS, $LUNITS, $EXTMIN, $EXTMAX, $LTSCALE).
+var wellKnown = []struct {
+	name string
+	code int
+}{
+	{"$CLAYER", 8},
+	{"$CELTYPE", 6},
+	{"$TEXTSTYLE", 7},
+	{"$DIMSTYLE", 2},
+	{"$DWGCODEPAGE", 3},
+	{"$TDCREATE", 40},
+	{"$TDUPDATE", 40},
+	{"$MEASUREMENT", 70},
+	{"$PDMODE", 70},
+	{"$PDSIZE", 40},
+	{"$TEXTSIZE", 40},
+	{"$FINGERPRINTGUID", 2},
+	{"$VERSIONGUID", 2},
+}
+
+// field returns a pointer to the field of well-known variable,
+// or nil if name is not well-known.
+func (h *Header) field(name string) interface{} {
+	switch name {
+	case "$CLAYER":
+		return &h.CLayer
+	case "$CELTYPE":
+		return &h.CeLType
+	case "$TEXTSTYLE":
+		return &h.TextStyle
+	case "$DIMSTYLE":
+		return &h.DimStyle
+	case "$DWGCODEPAGE":
+		return &h.DwgCodePage
+	case "$TDCREATE":
+		return &h.TdCreate
+	case "$TDUPDATE":
+		return &h.TdUpdate
+	case "$MEASUREMENT":
+		return &h.Measurement
+	case "$PDMODE":
+		return &h.PdMode
+	case "$PDSIZE":
+		return &h.PdSize
+	case "$TEXTSIZE":
+		return &h.TextSize
+	case "$FINGERPRINTGUID":
+		return &h.FingerprintGUID
+	case "$VERSIONGUID":
+		return &h.VersionGUID
+	}
+	return nil
+}
+
+// fieldValue returns the value of well-known variable and whether it is set (non-zero).
+func fieldValue(p interface{}) (interface{}, bool) {
+	switch p := p.(type) {
+	case *string:
+		return *p, *p != ""
+	case *float64:
+		return *p, *p != 0.0
+	case *int:
+		return *p, *p != 0
+	}
+	return nil, false
+}
+
+// Get returns the values of variable (e.g. "$CLAYER") if exists.
+func (h *Header) Get(name string) ([]Value, error) {
+	switch name {
+	case "$ACADVER":
+		return []Value{{1, h.Version}}, nil
+	case "$INSBASE":
+		return pointValues(h.InsBase), nil
+	case "$INSUNITS":
+		return []Value{{70, int(h.InsUnit)}}, nil
+	case "$LUNITS":
+		return []Value{{70, int(h.InsLUnit) + 2}}, nil
+	case "$EXTMIN":
+		return pointValues(h.ExtMin), nil
+	case "$EXTMAX":
+		return pointValues(h.ExtMax), nil
+	case "$LTSCALE":
+		return []Value{{40, h.LtScale}}, nil
+	case "$HANDSEED":
+		return []Value{{5, strconv.FormatInt(int64(h.handseed), 16)}}, nil
+	}
+	if p := h.field(name); p != nil {
+		v, set := fieldValue(p)
+		if !set && !h.has(name) {
+			return nil, fmt.Errorf("%s doesn't exist", name)
+		}
+		return []Value{{codeOf(name), v}}, nil
+	}
+	if vs, exist := h.values[name]; exist {
+		return vs, nil
+	}
+	return nil, fmt.Errorf("%s doesn't exist", name)
+}
+
+// Set sets the values of variable, replacing existing ones.
+// Variables exposed as fields are set to the fields.
+// $HANDSEED is kept as HandSeed until it is set on writing.
+func (h *Header) Set(name string, values ...Value) error {
+	if !strings.HasPrefix(name, "$") {
+		return fmt.Errorf("invalid variable name: %s", name)
+	}
+	var err error
+	switch name {
+	case "$HANDSEED":
+		var seed string
+		if err = setValue(name, values, &seed); err == nil {
+			var v int64
+			if v, err = strconv.ParseInt(seed, 16, 64); err == nil {
+				h.handseed = int(v)
+			}
+		}
+		return err
+	case "$ACADVER":
+		err = setValue(name, values, &h.Version)
+	case "$INSBASE":
+		err = setPoint(name, values, h.InsBase)
+	case "$INSUNITS":
+		var u int
+		if err = setValue(name, values, &u); err == nil {
+			h.InsUnit = insunit.Unit(u)
+		}
+	case "$LUNITS":
+		// Need to adjust the value back to the constants
+		var u int
+		if err = setValue(name, values, &u); err == nil {
+			h.InsLUnit = insunit.Type(u - 2)
+		}
+	case "$EXTMIN":
+		err = setPoint(name, values, h.ExtMin)
+	case "$EXTMAX":
+		err = setPoint(name, values, h.ExtMax)
+	case "$LTSCALE":
+		err = setValue(name, values, &h.LtScale)
+	default:
+		if p := h.field(name); p != nil {
+			err = setValue(name, values, p)
+		} else {
+			if h.values == nil {
+				h.values = make(map[string][]Value)
+			}
+			h.values[name] = values
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !h.has(name) {
+		h.names = append(h.names, name)
+	}
+	return nil
+}
+
+// Delete deletes variable which is not always written.
+func (h *Header) Delete(name string) {
+	if p := h.field(name); p != nil {
+		switch p := p.(type) {
+		case *string:
+			*p = ""
+		case *float64:
+			*p = 0.0
+		case *int:
+			*p = 0
+		}
+	}
+	delete(h.values, name)
+	for i, n := range h.names {
+		if n == name {
+			h.names = append(h.names[:i:i], h.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// Names returns names of variables which have been read or set, in the order.
+func (h *Header) Names() []string {
+	names := make([]string, len(h.names))
+	copy(names, h.names)
+	return names
+}
+
+// has reports if variable has been read or set.
+func (h *Header) has(name string) bool {
+	for _, n := range h.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// formatVariables writes variables other than those always written.
+// Variables read or set are written in the order,
+// followed by well-known variables set to the fields directly.
+func (h *Header) formatVariables(f format.Formatter) {
+	for _, name := range h.names {
+		if p := h.field(name); p != nil {
+			v, _ := fieldValue(p)
+			f.WriteString(9, name)
+			Value{codeOf(name), v}.Format(f)
+		} else if vs, exist := h.values[name]; exist {
+			f.WriteString(9, name)
+			for _, v := range vs {
+				v.Format(f)
+			}
+		}
+	}
+	for _, w := range wellKnown {
+		if h.has(w.name) {
+			continue
+		}
+		if v, set := fieldValue(h.field(w.name)); set {
+			f.WriteString(9, w.name)
+			Value{w.code, v}.Format(f)
+		}
+	}
+}
+
+// codeOf returns the group code of well-known variable.
+func codeOf(name string) int {
+	for _, w := range wellKnown {
+		if w.name == name {
+			return w.code
+		}
+	}
+	return 0
+}
+
+// pointValues converts a point into values with code 10, 20 and 30.
+func pointValues(p []float64) []Value {
+	return []Value{{10, p[0]}, {20, p[1]}, {30, p[2]}}
+}
+
+// setPoint sets values with code 10, 20 and 30 to p.
+func setPoint(name string, values []Value, p []float64) error {
+	for _, v := range values {
+		val, ok := v.Value.(float64)
+		if !ok || (v.Code != 10 && v.Code != 20 && v.Code != 30) {
+			return fmt.Errorf("%s: invalid value %v", name, v)
+		}
+		p[v.Code/10-1] = val
+	}
+	return nil
+}
+
+// setValue sets the first value to the variable pointed by p.
+func setValue(name string, values []Value, p interface{}) error {
+	if len(values) == 0 {
+		return fmt.Errorf("%s: no value", name)
+	}
+	ok := false
+	switch p := p.(type) {
+	case *string:
+		var val string
+		if val, ok = values[0].Value.(string); ok {
+			*p = val
+		}
+	case *float64:
+		var val float64
+		if val, ok = values[0].Value.(float64); ok {
+			*p = val
+		}
+	case *int:
+		var val int
+		if val, ok = values[0].Value.(int); ok {
+			*p = val
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%s: invalid value %v", name, values[0].Value)
+	}
+	return nil
+}
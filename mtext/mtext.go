@@ -0,0 +1,362 @@
+// Package mtext parses MTEXT inline formatting codes.
+package mtext
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Special characters of %% codes.
+const (
+	Degree    = "°" // %%d
+	Diameter  = "⌀" // %%c
+	PlusMinus = "±" // %%p
+)
+
+// Stack represents stacked text like fractions (\S code).
+type Stack struct {
+	Upper string
+	Lower string
+	Type  byte // '^': tolerance, '/': horizontal fraction, '#': diagonal fraction
+}
+
+// String returns plain representation of Stack.
+// Upper and Lower are separated by "/" whatever Type is.
+func (s *Stack) String() string {
+	return s.Upper + "/" + s.Lower
+}
+
+// Style represents formatting state of text.
+type Style struct {
+	Font         string  // \f or \F
+	Bold         bool    // \f...|b1
+	Italic       bool    // \f...|i1
+	Height       float64 // \H: absolute height, 0 means the height of MTEXT
+	HeightFactor float64 // \H...x: relative to Height, 1 means unchanged
+	Color        int     // \C: ACI color, 256 means BYLAYER
+	TrueColor    int     // \c: RGB color, -1 means not set
+	WidthFactor  float64 // \W
+	Oblique      float64 // \Q (Degree)
+	Tracking     float64 // \T
+	Underline    bool    // \L, \l
+	Overline     bool    // \O, \o
+	Strike       bool    // \K, \k
+}
+
+// DefaultStyle returns the style at the beginning of MTEXT.
+func DefaultStyle() Style {
+	return Style{
+		HeightFactor: 1.0,
+		Color:        256,
+		TrueColor:    -1,
+		WidthFactor:  1.0,
+		Tracking:     1.0,
+	}
+}
+
+// Run represents a piece of text with the same style.
+// Paragraph breaks are represented as "\n" in Text.
+// For stacked text, Text is the plain representation of Stack.
+type Run struct {
+	Text  string
+	Style Style
+	Stack *Stack // non-nil if the run is stacked text
+}
+
+// parser keeps the state of parsing.
+type parser struct {
+	src    []rune
+	pos    int
+	style  Style
+	stack  []Style
+	runs   []Run
+	buffer strings.Builder
+}
+
+// Parse parses MTEXT value and returns styled runs.
+func Parse(s string) []Run {
+	p := &parser{src: []rune(s), style: DefaultStyle()}
+	p.parse()
+	return p.runs
+}
+
+// PlainText returns MTEXT value without formatting codes.
+func PlainText(s string) string {
+	var b strings.Builder
+	for _, r := range Parse(s) {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+// flush appends buffered text as a run.
+func (p *parser) flush() {
+	if p.buffer.Len() == 0 {
+		return
+	}
+	p.runs = append(p.runs, Run{Text: p.buffer.String(), Style: p.style})
+	p.buffer.Reset()
+}
+
+// setStyle changes style, flushing text of the previous style.
+func (p *parser) setStyle(fn func(*Style)) {
+	p.flush()
+	fn(&p.style)
+}
+
+// peek returns the rune at pos+n, or 0 if it is out of range.
+func (p *parser) peek(n int) rune {
+	if p.pos+n < len(p.src) {
+		return p.src[p.pos+n]
+	}
+	return 0
+}
+
+// argument reads characters up to ';' and returns them without ';'.
+func (p *parser) argument() string {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] != ';' {
+		p.pos++
+	}
+	arg := string(p.src[start:p.pos])
+	if p.pos < len(p.src) {
+		p.pos++
+	}
+	return arg
+}
+
+func (p *parser) parse() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			p.escape()
+		case c == '{':
+			p.pos++
+			p.flush()
+			p.stack = append(p.stack, p.style)
+		case c == '}':
+			p.pos++
+			p.flush()
+			if n := len(p.stack); n > 0 {
+				p.style = p.stack[n-1]
+				p.stack = p.stack[:n-1]
+			}
+		case c == '%' && p.peek(1) == '%' && p.pos+2 < len(p.src):
+			p.special()
+		case c == '^' && p.pos+1 < len(p.src):
+			p.caret()
+		default:
+			p.pos++
+			p.buffer.WriteRune(c)
+		}
+	}
+	p.flush()
+}
+
+// escape handles a code starting with backslash. pos is at the code letter.
+func (p *parser) escape() {
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'P', 'N', 'X':
+		p.buffer.WriteString("\n")
+	case '~':
+		p.buffer.WriteString(" ")
+	case '\\', '{', '}':
+		p.buffer.WriteRune(c)
+	case 'L', 'l':
+		p.setStyle(func(s *Style) { s.Underline = c == 'L' })
+	case 'O', 'o':
+		p.setStyle(func(s *Style) { s.Overline = c == 'O' })
+	case 'K', 'k':
+		p.setStyle(func(s *Style) { s.Strike = c == 'K' })
+	case 'f', 'F':
+		p.font(p.argument())
+	case 'H':
+		arg := p.argument()
+		p.setStyle(func(s *Style) {
+			if v, rel := number(arg); rel {
+				s.HeightFactor *= v
+			} else if v > 0.0 {
+				s.Height = v
+				s.HeightFactor = 1.0
+			}
+		})
+	case 'W':
+		arg := p.argument()
+		p.setStyle(func(s *Style) {
+			if v, rel := number(arg); rel {
+				s.WidthFactor *= v
+			} else {
+				s.WidthFactor = v
+			}
+		})
+	case 'Q':
+		arg := p.argument()
+		p.setStyle(func(s *Style) { s.Oblique, _ = number(arg) })
+	case 'T':
+		arg := p.argument()
+		p.setStyle(func(s *Style) { s.Tracking, _ = number(arg) })
+	case 'C':
+		arg := p.argument()
+		p.setStyle(func(s *Style) {
+			if v, err := strconv.Atoi(arg); err == nil {
+				s.Color = v
+				s.TrueColor = -1
+			}
+		})
+	case 'c':
+		arg := p.argument()
+		p.setStyle(func(s *Style) {
+			if v, err := strconv.Atoi(arg); err == nil {
+				s.TrueColor = v
+			}
+		})
+	case 'A', 'p':
+		// alignment and paragraph properties do not affect runs
+		p.argument()
+	case 'S':
+		p.stacked()
+	case 'U':
+		if r, ok := p.unicode(); ok {
+			p.buffer.WriteRune(r)
+			break
+		}
+		p.buffer.WriteString("\\U")
+	default:
+		// unknown code is kept as it is
+		p.buffer.WriteRune('\\')
+		p.buffer.WriteRune(c)
+	}
+}
+
+// unicode reads "+XXXX" of \U+XXXX code and returns the character.
+func (p *parser) unicode() (rune, bool) {
+	if p.peek(0) != '+' || p.pos+5 > len(p.src) {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(string(p.src[p.pos+1:p.pos+5]), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	p.pos += 5
+	return rune(v), true
+}
+
+// font handles \f and \F codes like "Arial|b1|i0|c0|p34".
+func (p *parser) font(arg string) {
+	parts := strings.Split(arg, "|")
+	p.setStyle(func(s *Style) {
+		s.Font = parts[0]
+		s.Bold = false
+		s.Italic = false
+		for _, opt := range parts[1:] {
+			switch {
+			case opt == "b1":
+				s.Bold = true
+			case opt == "i1":
+				s.Italic = true
+			}
+		}
+	})
+}
+
+// stacked handles \S code like "1/2;", "1#2;" and "+0.1^-0.2;".
+func (p *parser) stacked() {
+	var upper, lower strings.Builder
+	var typ byte
+	cur := &upper
+	for p.pos < len(p.src) && p.src[p.pos] != ';' {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && p.pos < len(p.src):
+			// escaped separator
+			cur.WriteRune(p.src[p.pos])
+			p.pos++
+		case typ == 0 && (c == '^' || c == '/' || c == '#'):
+			typ = byte(c)
+			cur = &lower
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	if p.pos < len(p.src) {
+		p.pos++
+	}
+	if typ == 0 {
+		typ = '/'
+	}
+	p.flush()
+	s := &Stack{Upper: strings.TrimSpace(upper.String()), Lower: strings.TrimSpace(lower.String()), Type: typ}
+	p.runs = append(p.runs, Run{Text: s.String(), Style: p.style, Stack: s})
+}
+
+// special handles %% codes.
+func (p *parser) special() {
+	c := p.src[p.pos+2]
+	p.pos += 3
+	switch c {
+	case 'd', 'D':
+		p.buffer.WriteString(Degree)
+	case 'c', 'C':
+		p.buffer.WriteString(Diameter)
+	case 'p', 'P':
+		p.buffer.WriteString(PlusMinus)
+	case '%':
+		p.buffer.WriteString("%")
+	case 'u', 'U':
+		p.setStyle(func(s *Style) { s.Underline = !s.Underline })
+	case 'o', 'O':
+		p.setStyle(func(s *Style) { s.Overline = !s.Overline })
+	case 'k', 'K':
+		p.setStyle(func(s *Style) { s.Strike = !s.Strike })
+	default:
+		if c >= '0' && c <= '9' {
+			// %%nnn is a character code
+			p.pos -= 1
+			start := p.pos
+			for p.pos < len(p.src) && p.pos-start < 3 && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+				p.pos++
+			}
+			v, _ := strconv.Atoi(string(p.src[start:p.pos]))
+			p.buffer.WriteRune(rune(v))
+			return
+		}
+		p.buffer.WriteString("%%")
+		p.buffer.WriteRune(c)
+	}
+}
+
+// caret handles control characters like ^I (tab) and ^J (line feed).
+func (p *parser) caret() {
+	c := p.src[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'I':
+		p.buffer.WriteString("\t")
+	case 'J', 'M':
+		p.buffer.WriteString("\n")
+	case ' ':
+		p.buffer.WriteString("^")
+	default:
+		p.buffer.WriteRune('^')
+		p.buffer.WriteRune(c)
+	}
+}
+
+// number parses value of codes like \H, which may have "x" suffix for relative value.
+func number(arg string) (float64, bool) {
+	arg = strings.TrimSpace(arg)
+	rel := strings.HasSuffix(strings.ToLower(arg), "x")
+	if rel {
+		arg = arg[:len(arg)-1]
+	}
+	v, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 1.0, rel
+	}
+	return v, rel
+}
@@ -10,8 +10,11 @@ import (
 
 // Dictionary represents DICTIONARY Object.
 type Dictionary struct {
-	handle int
-	item   map[string]handle.Handler
+	handle    int
+	owner     handle.Handler
+	item      map[string]handle.Handler
+	HardOwner bool // 280: items are hard-owned (code 360)
+	Cloning   int  // 281: duplicate record cloning flag
 }
 
 // IsObject is for Object interface.
@@ -23,8 +26,9 @@ func (d *Dictionary) IsObject() bool {
 func NewDictionary() *Dictionary {
 	ds := make(map[string]handle.Handler)
 	d := &Dictionary{
-		handle: 0,
-		item:   ds,
+		handle:  0,
+		item:    ds,
+		Cloning: 1,
 	}
 	return d
 }
@@ -33,21 +37,24 @@ func NewDictionary() *Dictionary {
 func (d *Dictionary) Format(f format.Formatter) {
 	f.WriteString(0, "DICTIONARY")
 	f.WriteHex(5, d.handle)
+	if d.owner != nil {
+		f.WriteString(102, "{ACAD_REACTORS")
+		f.WriteHex(330, d.owner.Handle())
+		f.WriteString(102, "}")
+		f.WriteHex(330, d.owner.Handle())
+	}
 	f.WriteString(100, "AcDbDictionary")
-	f.WriteInt(281, 1)
-	keys := make([]string, len(d.item))
-	{
-		idx := 0
-		for k := range d.item {
-			keys[idx] = k
-			idx++
-		}
+	if d.HardOwner {
+		f.WriteInt(280, 1)
 	}
-	sort.Strings(keys)
-
-	for _, k := range keys {
+	f.WriteInt(281, d.Cloning)
+	code := 350
+	if d.HardOwner {
+		code = 360
+	}
+	for _, k := range d.Keys() {
 		f.WriteString(3, k)
-		f.WriteHex(350, d.item[k].Handle())
+		f.WriteHex(code, d.item[k].Handle())
 	}
 }
 
@@ -85,3 +92,36 @@ func (d *Dictionary) AddItem(key string, value handle.Handler) error {
 	d.item[key] = value
 	return nil
 }
+
+// Item returns the item of key if exists.
+func (d *Dictionary) Item(key string) (handle.Handler, error) {
+	if v, exist := d.item[key]; exist {
+		return v, nil
+	}
+	return nil, fmt.Errorf("key %s doesn't exist", key)
+}
+
+// SetItem sets an item of key, replacing the existing one.
+func (d *Dictionary) SetItem(key string, value handle.Handler) {
+	d.item[key] = value
+}
+
+// Keys returns keys of items in sorted order.
+func (d *Dictionary) Keys() []string {
+	keys := make([]string, 0, len(d.item))
+	for k := range d.item {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Owner returns the owner.
+func (d *Dictionary) Owner() handle.Handler {
+	return d.owner
+}
+
+// SetOwner sets an owner.
+func (d *Dictionary) SetOwner(h handle.Handler) {
+	d.owner = h
+}
@@ -9,7 +9,8 @@ import (
 // Group represents GROUP Object.
 type Group struct {
 	Name        string
-	Description string
+	Description string // 300
+	Unnamed     bool   // 70
 	handle      int
 	owner       handle.Handler
 	entities    []entity.Entity
@@ -50,7 +51,11 @@ func (g *Group) Format(f format.Formatter) {
 	f.WriteHex(330, g.owner.Handle())
 	f.WriteString(100, "AcDbGroup")
 	f.WriteString(300, g.Description)
-	f.WriteInt(70, 0)
+	if g.Unnamed {
+		f.WriteInt(70, 1)
+	} else {
+		f.WriteInt(70, 0)
+	}
 	if g.selectable {
 		f.WriteInt(71, 1)
 	} else {
@@ -91,3 +96,26 @@ func (g *Group) AddEntity(es ...entity.Entity) {
 	}
 	g.entities = append(g.entities, es...)
 }
+
+// ReplaceEntity replaces entity e of Group with n.
+// It does nothing if e doesn't belong to Group.
+func (g *Group) ReplaceEntity(e, n entity.Entity) {
+	for i, c := range g.entities {
+		if c == e {
+			n.SetBlockRecord(g)
+			g.entities[i] = n
+		}
+	}
+}
+
+// Entities returns entities of Group.
+func (g *Group) Entities() []entity.Entity {
+	es := make([]entity.Entity, len(g.entities))
+	copy(es, g.entities)
+	return es
+}
+
+// SetSelectable sets whether Group is selectable (code 71).
+func (g *Group) SetSelectable(b bool) {
+	g.selectable = b
+}
@@ -0,0 +1,94 @@
+package object
+
+import (
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/handle"
+)
+
+// Resolution units of IMAGEDEF (code 281)
+const (
+	IMAGE_UNIT_NONE       = 0
+	IMAGE_UNIT_CENTIMETER = 2
+	IMAGE_UNIT_INCH       = 5
+)
+
+// ImageDef represents IMAGEDEF Object.
+type ImageDef struct {
+	handle    int
+	owner     handle.Handler
+	Version   int       // 90: class version
+	FileName  string    // 1
+	Size      []float64 // 10, 20: image size in pixels
+	PixelSize []float64 // 11, 21: default size of one pixel in AutoCAD units
+	Loaded    bool      // 280
+	Units     int       // 281: resolution units
+}
+
+// IsObject is for Object interface.
+func (i *ImageDef) IsObject() bool {
+	return true
+}
+
+// NewImageDef creates a new ImageDef of image file with the size in pixels.
+func NewImageDef(filename string, width, height float64) *ImageDef {
+	i := &ImageDef{
+		FileName:  filename,
+		Size:      []float64{width, height},
+		PixelSize: []float64{1.0, 1.0},
+		Loaded:    true,
+	}
+	return i
+}
+
+// Format writes data to formatter.
+func (i *ImageDef) Format(f format.Formatter) {
+	f.WriteString(0, "IMAGEDEF")
+	f.WriteHex(5, i.handle)
+	if i.owner != nil {
+		f.WriteHex(330, i.owner.Handle())
+	}
+	f.WriteString(100, "AcDbRasterImageDef")
+	f.WriteInt(90, i.Version)
+	f.WriteString(1, i.FileName)
+	writePoint(f, 10, i.Size)
+	writePoint(f, 11, i.PixelSize)
+	if i.Loaded {
+		f.WriteInt(280, 1)
+	} else {
+		f.WriteInt(280, 0)
+	}
+	f.WriteInt(281, i.Units)
+}
+
+// String outputs data using default formatter.
+func (i *ImageDef) String() string {
+	f := format.NewASCII()
+	return i.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (i *ImageDef) FormatString(f format.Formatter) string {
+	i.Format(f)
+	return f.Output()
+}
+
+// Handle returns a handle value.
+func (i *ImageDef) Handle() int {
+	return i.handle
+}
+
+// SetHandle sets a handle.
+func (i *ImageDef) SetHandle(v *int) {
+	i.handle = *v
+	(*v)++
+}
+
+// Owner returns the owner.
+func (i *ImageDef) Owner() handle.Handler {
+	return i.owner
+}
+
+// SetOwner sets an owner.
+func (i *ImageDef) SetOwner(h handle.Handler) {
+	i.owner = h
+}
@@ -0,0 +1,103 @@
+package object
+
+import (
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/handle"
+)
+
+// Layout represents LAYOUT Object.
+// It has plot settings of the layout as PlotSettings.
+type Layout struct {
+	PlotSettings
+	Name        string         // 1
+	LayoutFlag  int            // 70
+	TabOrder    int            // 71
+	LimMin      []float64      // 10, 20
+	LimMax      []float64      // 11, 21
+	InsBase     []float64      // 12, 22, 32
+	ExtMin      []float64      // 14, 24, 34
+	ExtMax      []float64      // 15, 25, 35
+	Elevation   float64        // 146
+	UcsOrigin   []float64      // 13, 23, 33
+	UcsXAxis    []float64      // 16, 26, 36
+	UcsYAxis    []float64      // 17, 27, 37
+	UcsType     int            // 76: orthographic type of UCS
+	BlockRecord handle.Handler // 330: *Model_Space or *Paper_Space
+	Viewport    handle.Handler // 331: last active viewport
+	NamedUcs    handle.Handler // 345
+	BaseUcs     handle.Handler // 346
+}
+
+// NewLayout creates a new Layout of given BLOCK_RECORD.
+func NewLayout(name string, br handle.Handler) *Layout {
+	l := &Layout{
+		PlotSettings: *NewPlotSettings(""),
+		Name:         name,
+		LimMin:       []float64{0.0, 0.0},
+		LimMax:       []float64{420.0, 297.0},
+		InsBase:      []float64{0.0, 0.0, 0.0},
+		ExtMin:       []float64{1e20, 1e20, 1e20},
+		ExtMax:       []float64{-1e20, -1e20, -1e20},
+		UcsOrigin:    []float64{0.0, 0.0, 0.0},
+		UcsXAxis:     []float64{1.0, 0.0, 0.0},
+		UcsYAxis:     []float64{0.0, 1.0, 0.0},
+		UcsType:      1,
+		BlockRecord:  br,
+	}
+	return l
+}
+
+// Format writes data to formatter.
+func (l *Layout) Format(f format.Formatter) {
+	f.WriteString(0, "LAYOUT")
+	f.WriteHex(5, l.handle)
+	if l.owner != nil {
+		f.WriteHex(330, l.owner.Handle())
+	}
+	l.PlotSettings.format(f)
+	f.WriteString(100, "AcDbLayout")
+	f.WriteString(1, l.Name)
+	f.WriteInt(70, l.LayoutFlag)
+	f.WriteInt(71, l.TabOrder)
+	writePoint(f, 10, l.LimMin)
+	writePoint(f, 11, l.LimMax)
+	writePoint(f, 12, l.InsBase)
+	writePoint(f, 14, l.ExtMin)
+	writePoint(f, 15, l.ExtMax)
+	f.WriteFloat(146, l.Elevation)
+	writePoint(f, 13, l.UcsOrigin)
+	writePoint(f, 16, l.UcsXAxis)
+	writePoint(f, 17, l.UcsYAxis)
+	f.WriteInt(76, l.UcsType)
+	if l.BlockRecord != nil {
+		f.WriteHex(330, l.BlockRecord.Handle())
+	}
+	if l.Viewport != nil {
+		f.WriteHex(331, l.Viewport.Handle())
+	}
+	if l.NamedUcs != nil {
+		f.WriteHex(345, l.NamedUcs.Handle())
+	}
+	if l.BaseUcs != nil {
+		f.WriteHex(346, l.BaseUcs.Handle())
+	}
+}
+
+// String outputs data using default formatter.
+func (l *Layout) String() string {
+	f := format.NewASCII()
+	return l.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (l *Layout) FormatString(f format.Formatter) string {
+	l.Format(f)
+	return f.Output()
+}
+
+// writePoint writes 2D or 3D point p with code, code+10 (and code+20).
+func writePoint(f format.Formatter, code int, p []float64) {
+	for i, v := range p {
+		f.WriteFloat(code+i*10, v)
+	}
+}
@@ -0,0 +1,140 @@
+package object
+
+import (
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/handle"
+)
+
+// PlotSettings represents PLOTSETTINGS Object.
+type PlotSettings struct {
+	handle              int
+	owner               handle.Handler
+	PageSetupName       string         // 1
+	PlotConfig          string         // 2: printer or plotter name
+	PaperName           string         // 4
+	PlotView            string         // 6
+	Margins             []float64      // 40, 41, 42, 43: left, bottom, right, top (mm)
+	PaperSize           []float64      // 44, 45: width, height (mm)
+	PlotOrigin          []float64      // 46, 47
+	WindowMin           []float64      // 48, 49
+	WindowMax           []float64      // 140, 141
+	Numerator           float64        // 142: real world units of custom print scale
+	Denominator         float64        // 143: drawing units of custom print scale
+	Flag                int            // 70
+	PaperUnits          int            // 72: 0 = inches, 1 = millimeters, 2 = pixels
+	Rotation            int            // 73: 0 = none, 1 = 90, 2 = 180, 3 = 270 degrees counterclockwise
+	PlotType            int            // 74
+	StyleSheet          string         // 7
+	StandardScale       int            // 75
+	ShadePlotMode       int            // 76
+	ShadePlotResolution int            // 77
+	ShadePlotDPI        int            // 78
+	ScaleFactor         float64        // 147
+	PaperImageOrigin    []float64      // 148, 149
+	ShadePlot           handle.Handler // 333
+}
+
+// IsObject is for Object interface.
+func (p *PlotSettings) IsObject() bool {
+	return true
+}
+
+// NewPlotSettings creates a new PlotSettings.
+func NewPlotSettings(name string) *PlotSettings {
+	p := &PlotSettings{
+		PageSetupName:    name,
+		Margins:          []float64{0.0, 0.0, 0.0, 0.0},
+		PaperSize:        []float64{0.0, 0.0},
+		PlotOrigin:       []float64{0.0, 0.0},
+		WindowMin:        []float64{0.0, 0.0},
+		WindowMax:        []float64{0.0, 0.0},
+		Numerator:        1.0,
+		Denominator:      1.0,
+		Flag:             688,
+		PlotType:         5,
+		StandardScale:    16,
+		ScaleFactor:      1.0,
+		PaperImageOrigin: []float64{0.0, 0.0},
+	}
+	return p
+}
+
+// Format writes data to formatter.
+func (p *PlotSettings) Format(f format.Formatter) {
+	f.WriteString(0, "PLOTSETTINGS")
+	f.WriteHex(5, p.handle)
+	if p.owner != nil {
+		f.WriteHex(330, p.owner.Handle())
+	}
+	p.format(f)
+}
+
+// format writes AcDbPlotSettings subclass data to formatter.
+func (p *PlotSettings) format(f format.Formatter) {
+	f.WriteString(100, "AcDbPlotSettings")
+	f.WriteString(1, p.PageSetupName)
+	f.WriteString(2, p.PlotConfig)
+	f.WriteString(4, p.PaperName)
+	f.WriteString(6, p.PlotView)
+	for i := 0; i < 4; i++ {
+		f.WriteFloat(40+i, p.Margins[i])
+	}
+	f.WriteFloat(44, p.PaperSize[0])
+	f.WriteFloat(45, p.PaperSize[1])
+	f.WriteFloat(46, p.PlotOrigin[0])
+	f.WriteFloat(47, p.PlotOrigin[1])
+	f.WriteFloat(48, p.WindowMin[0])
+	f.WriteFloat(49, p.WindowMin[1])
+	f.WriteFloat(140, p.WindowMax[0])
+	f.WriteFloat(141, p.WindowMax[1])
+	f.WriteFloat(142, p.Numerator)
+	f.WriteFloat(143, p.Denominator)
+	f.WriteInt(70, p.Flag)
+	f.WriteInt(72, p.PaperUnits)
+	f.WriteInt(73, p.Rotation)
+	f.WriteInt(74, p.PlotType)
+	f.WriteString(7, p.StyleSheet)
+	f.WriteInt(75, p.StandardScale)
+	f.WriteInt(76, p.ShadePlotMode)
+	f.WriteInt(77, p.ShadePlotResolution)
+	f.WriteInt(78, p.ShadePlotDPI)
+	f.WriteFloat(147, p.ScaleFactor)
+	f.WriteFloat(148, p.PaperImageOrigin[0])
+	f.WriteFloat(149, p.PaperImageOrigin[1])
+	if p.ShadePlot != nil {
+		f.WriteHex(333, p.ShadePlot.Handle())
+	}
+}
+
+// String outputs data using default formatter.
+func (p *PlotSettings) String() string {
+	f := format.NewASCII()
+	return p.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (p *PlotSettings) FormatString(f format.Formatter) string {
+	p.Format(f)
+	return f.Output()
+}
+
+// Handle returns a handle value.
+func (p *PlotSettings) Handle() int {
+	return p.handle
+}
+
+// SetHandle sets a handle.
+func (p *PlotSettings) SetHandle(v *int) {
+	p.handle = *v
+	(*v)++
+}
+
+// Owner returns the owner.
+func (p *PlotSettings) Owner() handle.Handler {
+	return p.owner
+}
+
+// SetOwner sets an owner.
+func (p *PlotSettings) SetOwner(h handle.Handler) {
+	p.owner = h
+}
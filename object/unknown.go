@@ -0,0 +1,59 @@
+package object
+
+import (
+	"github.com/flywave/go-dxf/format"
+)
+
+// Unknown represents an object which is not supported by this package.
+// Tags after the object type and handle are written back as they are read,
+// except that handle references are remapped to the handles assigned on writing.
+type Unknown struct {
+	handle int
+	Name   string       // 0
+	Tags   []format.Tag // all tags except 0 and 5
+}
+
+// IsObject is for Object interface.
+func (u *Unknown) IsObject() bool {
+	return true
+}
+
+// NewUnknown creates a new Unknown object of given type name.
+func NewUnknown(name string, tags []format.Tag) *Unknown {
+	u := &Unknown{
+		handle: 0,
+		Name:   name,
+		Tags:   tags,
+	}
+	return u
+}
+
+// Format writes data to formatter.
+func (u *Unknown) Format(f format.Formatter) {
+	f.WriteString(0, u.Name)
+	f.WriteHex(5, u.handle)
+	format.WriteTags(f, u.Tags)
+}
+
+// String outputs data using default formatter.
+func (u *Unknown) String() string {
+	f := format.NewASCII()
+	return u.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (u *Unknown) FormatString(f format.Formatter) string {
+	u.Format(f)
+	return f.Output()
+}
+
+// Handle returns a handle value.
+func (u *Unknown) Handle() int {
+	return u.handle
+}
+
+// SetHandle sets a handle.
+func (u *Unknown) SetHandle(v *int) {
+	u.handle = *v
+	(*v)++
+}
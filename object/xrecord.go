@@ -0,0 +1,74 @@
+package object
+
+import (
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/handle"
+)
+
+// XRecord represents XRECORD Object.
+// Its data are arbitrary tags, which may refer to other elements by Ref.
+type XRecord struct {
+	handle  int
+	owner   handle.Handler
+	Cloning int          // 280: duplicate record cloning flag
+	Tags    []format.Tag // data
+}
+
+// IsObject is for Object interface.
+func (x *XRecord) IsObject() bool {
+	return true
+}
+
+// NewXRecord creates a new XRecord with data.
+func NewXRecord(tags ...format.Tag) *XRecord {
+	x := &XRecord{
+		Cloning: 1,
+		Tags:    tags,
+	}
+	return x
+}
+
+// Format writes data to formatter.
+func (x *XRecord) Format(f format.Formatter) {
+	f.WriteString(0, "XRECORD")
+	f.WriteHex(5, x.handle)
+	if x.owner != nil {
+		f.WriteHex(330, x.owner.Handle())
+	}
+	f.WriteString(100, "AcDbXrecord")
+	f.WriteInt(280, x.Cloning)
+	format.WriteTags(f, x.Tags)
+}
+
+// String outputs data using default formatter.
+func (x *XRecord) String() string {
+	f := format.NewASCII()
+	return x.FormatString(f)
+}
+
+// FormatString outputs data using given formatter.
+func (x *XRecord) FormatString(f format.Formatter) string {
+	x.Format(f)
+	return f.Output()
+}
+
+// Handle returns a handle value.
+func (x *XRecord) Handle() int {
+	return x.handle
+}
+
+// SetHandle sets a handle.
+func (x *XRecord) SetHandle(v *int) {
+	x.handle = *v
+	(*v)++
+}
+
+// Owner returns the owner.
+func (x *XRecord) Owner() handle.Handler {
+	return x.owner
+}
+
+// SetOwner sets an owner.
+func (x *XRecord) SetOwner(h handle.Handler) {
+	x.owner = h
+}
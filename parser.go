@@ -3,114 +3,199 @@ package dxf
 import (
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"strconv"
 	"strings"
 
 	"github.com/flywave/go-dxf/block"
+	"github.com/flywave/go-dxf/class"
 	"github.com/flywave/go-dxf/color"
 	"github.com/flywave/go-dxf/drawing"
 	"github.com/flywave/go-dxf/entity"
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/handle"
 	"github.com/flywave/go-dxf/header"
-	"github.com/flywave/go-dxf/insunit"
+	"github.com/flywave/go-dxf/object"
 	"github.com/flywave/go-dxf/table"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // setFloat sets a floating point number to a variable using given function.
-func setFloat(data [2]string, f func(float64)) error {
-	val, err := strconv.ParseFloat(strings.TrimSpace(data[1]), 64)
+func setFloat(data Tag, f func(float64)) error {
+	val, err := data.Float()
 	if err != nil {
-		return fmt.Errorf("code %s: %s", data[0], err.Error())
+		return newTagError(data, err)
 	}
 	f(val)
 	return nil
 }
 
 // setInt sets an integer value to a variable using given function.
-func setInt(data [2]string, f func(int)) error {
-	val, err := strconv.ParseInt(strings.TrimSpace(data[1]), 10, 64)
+func setInt(data Tag, f func(int)) error {
+	val, err := data.Int()
 	if err != nil {
-		return fmt.Errorf("code %s: %s", data[0], err.Error())
+		return newTagError(data, err)
 	}
-	f(int(val))
+	f(val)
+	return nil
+}
+
+// setHandle sets a handle reference to a variable using given function.
+func setHandle(data Tag, f func(handle.Handler)) error {
+	val, err := data.Handle()
+	if err != nil {
+		return newTagError(data, err)
+	}
+	f(handle.Ref(val))
 	return nil
 }
 
+// skipSection skips tags until the end of the current section.
+func skipSection(r *TagReader) error {
+	for {
+		t, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if t.Is(0, "ENDSEC") {
+			return nil
+		}
+	}
+}
+
+// endOfSection reports whether the next tag ends the current section.
+// It consumes "0\nENDSEC\n".
+func endOfSection(r *TagReader) (bool, error) {
+	t, err := r.Peek()
+	if err == io.EOF {
+		return true, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	if t.Is(0, "ENDSEC") {
+		r.Next()
+		return true, nil
+	}
+	return false, nil
+}
+
 // HEADER
 
 // ParseHeader parses HEADER section.
-func ParseHeader(d *drawing.Drawing, line int, data [][2]string) error {
+// Every variable is kept with values typed according to their group codes.
+func ParseHeader(d *drawing.Drawing, r *TagReader) error {
 	h := d.Sections[drawing.HEADER].(*header.Header)
-	var name string
-	var err error
-	for _, dt := range data {
-		switch dt[0] {
-		case "9":
-			name = dt[1]
-		case "1":
-			switch name {
-			case "$ACADVER":
-				h.Version = dt[1]
-			}
-		case "10":
-			switch name {
-			case "$INSBASE":
-				err = setFloat(dt, func(val float64) { h.InsBase[0] = val })
-			case "$EXTMIN":
-				err = setFloat(dt, func(val float64) { h.ExtMin[0] = val })
-			case "$EXTMAX":
-				err = setFloat(dt, func(val float64) { h.ExtMax[0] = val })
-			}
-		case "20":
-			switch name {
-			case "$INSBASE":
-				err = setFloat(dt, func(val float64) { h.InsBase[1] = val })
-			case "$EXTMIN":
-				err = setFloat(dt, func(val float64) { h.ExtMin[1] = val })
-			case "$EXTMAX":
-				err = setFloat(dt, func(val float64) { h.ExtMax[1] = val })
-			}
-		case "30":
-			switch name {
-			case "$INSBASE":
-				err = setFloat(dt, func(val float64) { h.InsBase[2] = val })
-			case "$EXTMIN":
-				err = setFloat(dt, func(val float64) { h.ExtMin[2] = val })
-			case "$EXTMAX":
-				err = setFloat(dt, func(val float64) { h.ExtMax[2] = val })
-			}
-		case "40":
-			switch name {
-			case "$LTSCALE":
-				err = setFloat(dt, func(val float64) { h.LtScale = val })
-			}
-		case "70":
-			switch name {
-			case "$INSUNITS":
-				err = setInt(dt, func(val int) { h.InsUnit = insunit.Unit(val) })
-			case "$LUNITS":
-				// Need to adjust the value back to the constants
-				err = setInt(dt, func(val int) { h.InsLUnit = insunit.Type(val - 2) })
+	var name Tag
+	values := make([]header.Value, 0)
+	set := func() error {
+		if name.Value == "" {
+			return nil
+		}
+		if err := h.Set(name.Value, values...); err != nil {
+			return r.skip("HEADER", []Tag{name}, err)
+		}
+		return nil
+	}
+	for {
+		if end, err := endOfSection(r); end {
+			if err != nil {
+				return err
 			}
+			return set()
 		}
+		dt, err := r.Next()
 		if err != nil {
 			return err
 		}
+		if dt.Code == 9 {
+			if err := set(); err != nil {
+				return err
+			}
+			name = dt
+			values = make([]header.Value, 0)
+			continue
+		}
+		if name.Value == "" {
+			continue
+		}
+		val, err := dt.Typed()
+		if err != nil {
+			if err := r.skip("HEADER", []Tag{name}, newTagError(dt, err)); err != nil {
+				return err
+			}
+			name = Tag{} // the rest of values are ignored
+			continue
+		}
+		values = append(values, header.Value{Code: dt.Code, Value: val})
 	}
-	return nil
 }
 
 // CLASSES
 
 // ParseClasses parses CLASSES section.
-func ParseClasses(d *drawing.Drawing, line int, data [][2]string) error {
-	return nil
+func ParseClasses(d *drawing.Drawing, r *TagReader) error {
+	cs := class.New()
+	defer func() { d.Sections[drawing.CLASSES] = cs }()
+	for {
+		if end, err := endOfSection(r); end {
+			return err
+		}
+		data, err := r.Record()
+		if err != nil {
+			return err
+		}
+		if !data[0].Is(0, "CLASS") {
+			continue
+		}
+		c, err := ParseClass(data)
+		if err != nil {
+			if err := r.skip("CLASSES", data, err); err != nil {
+				return err
+			}
+			continue
+		}
+		cs = cs.Add(c)
+	}
+}
+
+// ParseClass parses each CLASS.
+func ParseClass(data []Tag) (*class.Class, error) {
+	c := class.NewClass("", "", "", false)
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		case 1:
+			c.Name = dt.Value
+		case 2:
+			c.ClassName = dt.Value
+		case 3:
+			c.AppName = dt.Value
+		case 90:
+			err = setInt(dt, func(val int) { c.ProxyFlag = val })
+		case 91:
+			err = setInt(dt, func(val int) { c.Count = val })
+		case 280:
+			err = setInt(dt, func(val int) { c.WasProxy = val != 0 })
+		case 281:
+			err = setInt(dt, func(val int) { c.IsEntity = val != 0 })
+		}
+		if err != nil {
+			return c, err
+		}
+	}
+	return c, nil
 }
 
 // TABLES
 
 // ParseTables parses TABLES section.
-func ParseTables(d *drawing.Drawing, line int, data [][2]string) error {
-	parsers := []func(*drawing.Drawing, [][2]string) (table.SymbolTable, error){
+func ParseTables(d *drawing.Drawing, r *TagReader) error {
+	parsers := []func(*drawing.Drawing, []Tag) (table.SymbolTable, error){
 		ParseViewport,
 		ParseLtype,
 		ParseLayer,
@@ -121,156 +206,159 @@ func ParseTables(d *drawing.Drawing, line int, data [][2]string) error {
 		ParseDimStyle,
 		ParseBlockRecord,
 	}
-	tmpdata := make([][2]string, 0)
-	setparser := false
-	var parser func(*drawing.Drawing, [][2]string) (table.SymbolTable, error)
-	var ind int
-	for i, dt := range data {
-		if setparser {
-			if dt[0] != "2" {
-				return fmt.Errorf("ParseTables line %d: invalid group code: %s", line+2*i, dt[0])
-			}
-			ind = int(table.TableTypeValue(strings.ToUpper(dt[1])))
-			if ind < 0 {
-				return fmt.Errorf("line %d: unknown table type: %s", line+2*i, dt[1])
-			}
-			parser = parsers[ind]
-			setparser = false
-		} else {
-			if dt[0] == "0" {
-				switch strings.ToUpper(dt[1]) {
-				case "TABLE":
-					setparser = true
-				case "ENDTAB":
-					if len(tmpdata) > 0 {
-						err := ParseTable(d, tmpdata, ind, parser)
-						if err != nil {
-							return err
-						}
-						tmpdata = make([][2]string, 0)
-					}
-				default:
-					tmpdata = append(tmpdata, dt)
-				}
-			} else {
-				tmpdata = append(tmpdata, dt)
+	for {
+		if end, err := endOfSection(r); end {
+			return err
+		}
+		dt, err := r.Next()
+		if err != nil {
+			return err
+		}
+		if !dt.Is(0, "TABLE") {
+			continue
+		}
+		dt, err = r.Next()
+		if err != nil {
+			return err
+		}
+		if dt.Code != 2 {
+			if err := r.skip("TABLES", []Tag{dt}, errors.New("expected table name (code 2)")); err != nil {
+				return err
+			}
+			continue
+		}
+		ind := int(table.TableTypeValue(strings.ToUpper(dt.Value)))
+		if ind < 0 {
+			if err := r.skip("TABLES", []Tag{dt}, fmt.Errorf("unknown table type: %s", dt.Value)); err != nil {
+				return err
 			}
+			continue
 		}
-	}
-	if len(tmpdata) > 0 {
-		err := ParseTable(d, tmpdata, ind, parser)
+		err = ParseTable(d, r, ind, parsers[ind])
 		if err != nil {
-			return fmt.Errorf("line %d: %s", line+2*len(data), err.Error())
+			return err
 		}
-		tmpdata = make([][2]string, 0)
 	}
-	return nil
 }
 
 // ParseTable parses each TABLE, which starts with "0\nTABLE\n" and ends with "0\nENDTAB\n".
-func ParseTable(d *drawing.Drawing, data [][2]string, index int, parser func(*drawing.Drawing, [][2]string) (table.SymbolTable, error)) error {
+// The reader is expected to be positioned just after the table name (code 2).
+func ParseTable(d *drawing.Drawing, r *TagReader, index int, parser func(*drawing.Drawing, []Tag) (table.SymbolTable, error)) error {
 	t := d.Sections[drawing.TABLES].(table.Tables)[index]
 	t.Clear()
-	tmpdata := make([][2]string, 0)
-	add := false // skip before first 0-code
-	for _, dt := range data {
-		switch dt[0] {
-		case "0":
-			if len(tmpdata) > 0 {
-				st, err := parser(d, tmpdata)
-				if err != nil {
-					return err
-				}
-				t.Add(st)
-				if layer, ok := st.(*table.Layer); ok {
-					d.Layers[layer.Name()] = layer
-				}
-				tmpdata = make([][2]string, 0)
-			}
-			add = true
-		default:
-			if add {
-				tmpdata = append(tmpdata, dt)
+	for { // skip before first 0-code
+		next, err := r.Peek()
+		if err != nil || next.Code == 0 {
+			break
+		}
+		if next.Code == 5 {
+			if h, err := next.Handle(); err == nil {
+				d.RegisterHandle(h, t)
 			}
 		}
+		r.Next()
 	}
-	if len(tmpdata) > 0 {
-		st, err := parser(d, tmpdata)
+	for {
+		next, err := r.Peek()
+		if err == io.EOF || next.Is(0, "ENDSEC") {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, err := r.Record()
 		if err != nil {
 			return err
 		}
+		if data[0].Is(0, "ENDTAB") {
+			return nil
+		}
+		st, err := parser(d, data)
+		if err == nil {
+			err = parseXData(st, data)
+		}
+		if err != nil {
+			if err := r.skip("TABLES", data, err); err != nil {
+				return err
+			}
+			continue
+		}
 		t.Add(st)
-		if layer, ok := st.(*table.Layer); ok {
-			d.Layers[layer.Name()] = layer
+		d.RegisterHandle(recordHandle(data), st)
+		switch st := st.(type) {
+		case *table.Layer:
+			d.Layers[st.Name()] = st
+		case *table.Style:
+			d.Styles[st.Name()] = st
+		case *table.DimStyle:
+			d.DimStyles[st.Name()] = st
 		}
-		tmpdata = make([][2]string, 0)
 	}
-	return nil
 }
 
 // ParseViewport parses VPORT tables.
-func ParseViewport(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
+func ParseViewport(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
 	v := table.NewViewport("")
 	var err error
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			v.SetName(dt[1])
-		case "10":
+		switch dt.Code {
+		case 2:
+			v.SetName(dt.Value)
+		case 10:
 			err = setFloat(dt, func(val float64) { v.LowerLeft[0] = val })
-		case "20":
+		case 20:
 			err = setFloat(dt, func(val float64) { v.LowerLeft[1] = val })
-		case "11":
+		case 11:
 			err = setFloat(dt, func(val float64) { v.UpperRight[0] = val })
-		case "21":
+		case 21:
 			err = setFloat(dt, func(val float64) { v.UpperRight[1] = val })
-		case "12":
+		case 12:
 			err = setFloat(dt, func(val float64) { v.ViewCenter[0] = val })
-		case "22":
+		case 22:
 			err = setFloat(dt, func(val float64) { v.ViewCenter[1] = val })
-		case "13":
+		case 13:
 			err = setFloat(dt, func(val float64) { v.SnapBase[0] = val })
-		case "23":
+		case 23:
 			err = setFloat(dt, func(val float64) { v.SnapBase[1] = val })
-		case "14":
+		case 14:
 			err = setFloat(dt, func(val float64) {
 				v.SnapSpacing[0] = val
 				v.SnapSpacing[1] = val
 			})
-		case "24":
+		case 24:
 			err = setFloat(dt, func(val float64) { v.SnapSpacing[1] = val })
-		case "15":
+		case 15:
 			err = setFloat(dt, func(val float64) {
 				v.GridSpacing[0] = val
 				v.GridSpacing[1] = val
 			})
-		case "25":
+		case 25:
 			err = setFloat(dt, func(val float64) { v.GridSpacing[1] = val })
-		case "16":
+		case 16:
 			err = setFloat(dt, func(val float64) { v.ViewDirection[0] = val })
-		case "26":
+		case 26:
 			err = setFloat(dt, func(val float64) { v.ViewDirection[1] = val })
-		case "36":
+		case 36:
 			err = setFloat(dt, func(val float64) { v.ViewDirection[2] = val })
-		case "17":
+		case 17:
 			err = setFloat(dt, func(val float64) { v.ViewTarget[0] = val })
-		case "27":
+		case 27:
 			err = setFloat(dt, func(val float64) { v.ViewTarget[1] = val })
-		case "37":
+		case 37:
 			err = setFloat(dt, func(val float64) { v.ViewTarget[2] = val })
-		case "40":
+		case 40:
 			err = setFloat(dt, func(val float64) { v.Height = val })
-		case "41":
+		case 41:
 			err = setFloat(dt, func(val float64) { v.AspectRatio = val })
-		case "42":
+		case 42:
 			err = setFloat(dt, func(val float64) { v.LensLength = val })
-		case "43":
+		case 43:
 			err = setFloat(dt, func(val float64) { v.FrontClip = val })
-		case "44":
+		case 44:
 			err = setFloat(dt, func(val float64) { v.BackClip = val })
-		case "50":
+		case 50:
 			err = setFloat(dt, func(val float64) { v.SnapAngle = val })
-		case "51":
+		case 51:
 			err = setFloat(dt, func(val float64) { v.TwistAngle = val })
 		}
 		if err != nil {
@@ -281,27 +369,27 @@ func ParseViewport(d *drawing.Drawing, data [][2]string) (table.SymbolTable, err
 }
 
 // ParseLtype parses LTYPE tables.
-func ParseLtype(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
+func ParseLtype(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
 	var name, desc string
 	var lengths []float64
 	ind := 0
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			name = dt[1]
-		case "3":
-			desc = dt[1]
-		case "73":
-			l, err := strconv.ParseInt(strings.TrimSpace(dt[1]), 10, 64)
+		switch dt.Code {
+		case 2:
+			name = dt.Value
+		case 3:
+			desc = dt.Value
+		case 73:
+			l, err := strconv.ParseInt(strings.TrimSpace(dt.Value), 10, 64)
 			if err != nil {
 				return nil, err
 			}
 			lengths = make([]float64, int(l))
-		case "49":
+		case 49:
 			if ind >= len(lengths) {
 				return nil, fmt.Errorf("ltype too long")
 			}
-			val, err := strconv.ParseFloat(dt[1], 64)
+			val, err := strconv.ParseFloat(dt.Value, 64)
 			if err != nil {
 				return nil, err
 			}
@@ -313,41 +401,41 @@ func ParseLtype(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error)
 }
 
 // ParseLayer parses LAYER tables.
-func ParseLayer(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
+func ParseLayer(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
 	var name string
 	var flag int
 	var col color.ColorNumber
 	var lt *table.LineType
 	var lw int
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			name = dt[1]
-		case "70":
-			val, err := strconv.ParseInt(strings.TrimSpace(dt[1]), 10, 64)
+		switch dt.Code {
+		case 2:
+			name = dt.Value
+		case 70:
+			val, err := strconv.ParseInt(strings.TrimSpace(dt.Value), 10, 64)
 			if err != nil {
 				return nil, err
 			}
 			flag = int(val)
-		case "62":
-			val, err := strconv.ParseInt(strings.TrimSpace(dt[1]), 10, 64)
+		case 62:
+			val, err := strconv.ParseInt(strings.TrimSpace(dt.Value), 10, 64)
 			if err != nil {
 				return nil, err
 			}
 			col = color.ColorNumber(val)
-		case "6":
-			l, err := d.LineType(dt[1])
+		case 6:
+			l, err := d.LineType(dt.Value)
 			if err != nil {
 				return nil, err
 			}
 			lt = l
-		case "370":
-			val, err := strconv.ParseInt(strings.TrimSpace(dt[1]), 10, 64)
+		case 370:
+			val, err := strconv.ParseInt(strings.TrimSpace(dt.Value), 10, 64)
 			if err != nil {
 				return nil, err
 			}
 			lw = int(val)
-		case "390":
+		case 390:
 			// plotstyle
 		}
 	}
@@ -359,16 +447,16 @@ func ParseLayer(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error)
 }
 
 // ParseStyle parses STYLE tables.
-func ParseStyle(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
+func ParseStyle(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
 	var name, font, bigfont string
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			name = dt[1]
-		case "3":
-			font = dt[1]
-		case "4":
-			bigfont = dt[1]
+		switch dt.Code {
+		case 2:
+			name = dt.Value
+		case 3:
+			font = dt.Value
+		case 4:
+			bigfont = dt.Value
 		}
 	}
 	s := table.NewStyle(name)
@@ -378,12 +466,12 @@ func ParseStyle(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error)
 }
 
 // ParseView parses VIEW tables.
-func ParseView(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
+func ParseView(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
 	var name string
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			name = dt[1]
+		switch dt.Code {
+		case 2:
+			name = dt.Value
 		}
 	}
 	v := table.NewView(name)
@@ -391,12 +479,12 @@ func ParseView(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error)
 }
 
 // ParseUCS parses UCS tables.
-func ParseUCS(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
+func ParseUCS(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
 	var name string
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			name = dt[1]
+		switch dt.Code {
+		case 2:
+			name = dt.Value
 		}
 	}
 	u := table.NewUCS(name)
@@ -404,12 +492,12 @@ func ParseUCS(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
 }
 
 // ParseAppID parses APPID tables.
-func ParseAppID(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
+func ParseAppID(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
 	var name string
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			name = dt[1]
+		switch dt.Code {
+		case 2:
+			name = dt.Value
 		}
 	}
 	a := table.NewAppID(name)
@@ -417,25 +505,42 @@ func ParseAppID(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error)
 }
 
 // ParseDimStyle parses DIMSTYLE tables.
-func ParseDimStyle(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
-	var name string
+func ParseDimStyle(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
+	ds := table.NewDimStyle("")
+	var err error
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			name = dt[1]
+		switch dt.Code {
+		case 2:
+			ds = table.NewDimStyle(dt.Value)
+		case 40:
+			err = setFloat(dt, func(val float64) { ds.Scale = val })
+		case 41:
+			err = setFloat(dt, func(val float64) { ds.ArrowSize = val })
+		case 42:
+			err = setFloat(dt, func(val float64) { ds.ExtensionOffset = val })
+		case 44:
+			err = setFloat(dt, func(val float64) { ds.ExtensionExtend = val })
+		case 140:
+			err = setFloat(dt, func(val float64) { ds.TextHeight = val })
+		case 147:
+			err = setFloat(dt, func(val float64) { ds.TextGap = val })
+		case 271:
+			err = setInt(dt, func(val int) { ds.Decimals = val })
+		}
+		if err != nil {
+			return ds, err
 		}
 	}
-	ds := table.NewDimStyle(name)
 	return ds, nil
 }
 
 // ParseBlockRecord parses BLOCK_RECORD tables.
-func ParseBlockRecord(d *drawing.Drawing, data [][2]string) (table.SymbolTable, error) {
+func ParseBlockRecord(d *drawing.Drawing, data []Tag) (table.SymbolTable, error) {
 	var name string
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			name = dt[1]
+		switch dt.Code {
+		case 2:
+			name = dt.Value
 		}
 	}
 	b := table.NewBlockRecord(name)
@@ -445,348 +550,1927 @@ func ParseBlockRecord(d *drawing.Drawing, data [][2]string) (table.SymbolTable,
 // BLOCKS
 
 // ParseBlocks parses BLOCKS section.
-func ParseBlocks(d *drawing.Drawing, line int, data [][2]string) error {
-	tmpdata := make([][2]string, 0)
-	add := true // skip ENDBLK
-	for i, dt := range data {
-		if dt[0] == "0" {
-			switch strings.ToUpper(dt[1]) {
-			case "BLOCK":
-				add = true
-			case "ENDBLK":
-				if len(tmpdata) > 0 {
-					err := ParseBlock(d, tmpdata)
-					if err != nil {
-						return fmt.Errorf("line %d: %s", line+2*i, err.Error())
-					}
-					tmpdata = make([][2]string, 0)
+// Existing blocks in the drawing are replaced with the parsed ones.
+func ParseBlocks(d *drawing.Drawing, r *TagReader) error {
+	d.Sections[drawing.BLOCKS] = make(block.Blocks, 0)
+	var b *block.Block
+	for {
+		if end, err := endOfSection(r); end {
+			return err
+		}
+		data, err := r.Record()
+		if err != nil {
+			return err
+		}
+		switch {
+		case data[0].Is(0, "BLOCK"):
+			b, err = ParseBlock(d, data)
+			if err != nil {
+				b = nil // entities of the block are skipped
+				if err := r.skip("BLOCKS", data, err); err != nil {
+					return err
 				}
-				add = false
-			default:
-				if add {
-					tmpdata = append(tmpdata, dt)
+				continue
+			}
+			d.Sections[drawing.BLOCKS] = d.Blocks().Add(b)
+			d.RegisterHandle(recordHandle(data), b)
+		case data[0].Is(0, "ENDBLK"):
+			if b != nil {
+				d.RegisterEndHandle(recordHandle(data), b)
+			}
+			b = nil
+		case b != nil:
+			e, err := ParseEntity(d, data)
+			if err != nil {
+				if err := r.skip("BLOCKS", data, err); err != nil {
+					return err
 				}
+				continue
 			}
-		} else {
-			if add {
-				tmpdata = append(tmpdata, dt)
+			if p, ok := e.(*entity.Polyline); ok {
+				if err := readVertices(d, r, p, "BLOCKS"); err != nil {
+					return err
+				}
+			}
+			if i, ok := e.(*entity.Insert); ok {
+				if err := readAttributes(d, r, i, "BLOCKS"); err != nil {
+					return err
+				}
+			}
+			if e != nil {
+				b.AddEntity(e)
+				d.RegisterHandle(recordHandle(data), e)
 			}
 		}
 	}
-	if len(tmpdata) > 0 {
-		err := ParseBlock(d, tmpdata)
-		if err != nil {
-			return fmt.Errorf("line %d: %s", line+2*len(data), err.Error())
-		}
-		tmpdata = make([][2]string, 0)
-	}
-	return nil
 }
 
-// ParseBlock parses each BLOCK, which starts with "0\nBLOCK\n" and ends with "0\nENDBLK\n".
-func ParseBlock(d *drawing.Drawing, data [][2]string) error {
+// ParseBlock parses BLOCK header, which starts with "0\nBLOCK\n".
+// Entities of the block are parsed by ParseBlocks.
+func ParseBlock(d *drawing.Drawing, data []Tag) (*block.Block, error) {
 	b := block.NewBlock("", "")
 	var err error
 	for _, dt := range data {
-		switch dt[0] {
-		case "2":
-			b.Name = dt[1]
-		case "1": // 4?
-			b.Description = dt[1]
-		case "8":
-			layer, err := d.Layer(dt[1], false)
+		switch dt.Code {
+		case 2:
+			b.Name = dt.Value
+		case 1: // 4?
+			b.Description = dt.Value
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
 			if err == nil {
 				b.SetLayer(layer)
 			}
-		case "10":
+		case 10:
 			err = setFloat(dt, func(val float64) { b.Coord[0] = val })
-		case "20":
+		case 20:
 			err = setFloat(dt, func(val float64) { b.Coord[1] = val })
-		case "30":
+		case 30:
 			err = setFloat(dt, func(val float64) { b.Coord[2] = val })
-		case "70":
-			val, err := strconv.ParseInt(strings.TrimSpace(dt[1]), 10, 64)
-			if err != nil {
-				return err
-			}
-			b.Flag = int(val)
+		case 70:
+			err = setInt(dt, func(val int) { b.Flag = val })
 		}
 		if err != nil {
-			return err
+			return b, err
 		}
 	}
-	return nil
+	return b, nil
 }
 
 // ENTITIES
 
 // ParseEntities parses ENTITIES section.
-func ParseEntities(d *drawing.Drawing, line int, data [][2]string) error {
-	tmpdata := make([][2]string, 0)
-	for i, dt := range data {
-		if dt[0] == "0" {
-			if len(tmpdata) > 0 {
-				e, err := ParseEntity(d, tmpdata)
-				if err != nil {
-					return fmt.Errorf("line %d: %s", line+2*i, err.Error())
-				}
-				if e != nil {
-					d.AddEntity(e)
-				}
-				tmpdata = make([][2]string, 0)
-			}
+func ParseEntities(d *drawing.Drawing, r *TagReader) error {
+	return parseEntities(d, r, func(e entity.Entity, h int) error {
+		d.AddEntity(e)
+		d.RegisterHandle(h, e)
+		return nil
+	})
+}
+
+// parseEntities parses ENTITIES section and passes each entity with its handle in the file to fn.
+// An error returned by fn is returned as it is.
+func parseEntities(d *drawing.Drawing, r *TagReader, fn func(entity.Entity, int) error) error {
+	for {
+		if end, err := endOfSection(r); end {
+			return err
 		}
-		tmpdata = append(tmpdata, dt)
-	}
-	if len(tmpdata) > 0 {
-		e, err := ParseEntity(d, tmpdata)
+		e, h, err := readEntity(d, r)
 		if err != nil {
-			return fmt.Errorf("line %d: %s", line+2*len(data), err.Error())
+			return err
 		}
 		if e != nil {
-			d.AddEntity(e)
+			if err := fn(e, h); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
 }
 
-// ParseEntity parses each entity.
-func ParseEntity(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
-	if len(data) < 1 {
-		return nil, fmt.Errorf("no data")
-	}
-	if data[0][0] != "0" {
-		return nil, fmt.Errorf("ParseEntity invalid group code: %q", data[0][0])
+// readEntity reads the next entity record from r, and returns it with its handle in the file.
+// For POLYLINE, following VERTEX and SEQEND records are also read,
+// and so are ATTRIB and SEQEND records for INSERT.
+// In Lenient mode, nil is returned for a malformed entity.
+func readEntity(d *drawing.Drawing, r *TagReader) (entity.Entity, int, error) {
+	data, err := r.Record()
+	if err != nil {
+		return nil, 0, err
 	}
-	f, err := ParseEntityFunc(data[0][1])
+	e, err := ParseEntity(d, data)
 	if err != nil {
-		return nil, err
+		return nil, 0, r.skip("ENTITIES", data, err)
 	}
-	if f == nil {
-		return nil, nil
+	if p, ok := e.(*entity.Polyline); ok {
+		return p, recordHandle(data), readVertices(d, r, p, "ENTITIES")
 	}
-	return f(d, data)
-}
-
-// ParseEntityFunc returns a function for parsing acoording to entity type string.
-func ParseEntityFunc(t string) (func(*drawing.Drawing, [][2]string) (entity.Entity, error), error) {
-	switch t {
-	case "LINE":
-		return ParseLine, nil
-	case "3DFACE":
-		return Parse3DFace, nil
-	case "LWPOLYLINE":
-		return ParseLwPolyline, nil
-	case "CIRCLE":
-		return ParseCircle, nil
-	case "ARC":
-		return ParseArc, nil
-	case "POLYLINE", "VERTEX", "SEQEND", "SPLINE", "ELLIPSE", "MTEXT", "WIPEOUT", "LEADER", "VIEWPORT":
-		return nil, nil
-	// case "VERTEX":
-	// 	return ParseVertex, nil
-	case "POINT":
-		return ParsePoint, nil
-	case "TEXT":
-		return ParseText, nil
-	case "HATCH", "THUMBNAILIMAGE", "INSERT":
-		return nil, nil
-	default:
-		return nil, errors.New("unknown entity type")
+	if i, ok := e.(*entity.Insert); ok {
+		return i, recordHandle(data), readAttributes(d, r, i, "ENTITIES")
 	}
+	return e, recordHandle(data), nil
 }
 
-// ParseLine parses LINE entities.
-func ParseLine(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
-	l := entity.NewLine()
-	var err error
+// recordHandle returns the handle of record (code 5, or 105 for DIMSTYLE),
+// or 0 if it has no handle.
+func recordHandle(data []Tag) int {
 	for _, dt := range data {
-		switch dt[0] {
-		default:
-			continue
-		case "8":
-			layer, err := d.Layer(dt[1], false)
-			if err == nil {
-				l.SetLayer(layer)
+		if dt.Code == 5 || dt.Code == 105 {
+			if h, err := dt.Handle(); err == nil {
+				return h
 			}
-		case "48":
-			err = setFloat(dt, func(val float64) { l.SetLtscale(val) })
-		case "10":
-			err = setFloat(dt, func(val float64) { l.Start[0] = val })
-		case "20":
-			err = setFloat(dt, func(val float64) { l.Start[1] = val })
-		case "30":
-			err = setFloat(dt, func(val float64) { l.Start[2] = val })
-		case "11":
-			err = setFloat(dt, func(val float64) { l.End[0] = val })
-		case "21":
-			err = setFloat(dt, func(val float64) { l.End[1] = val })
-		case "31":
-			err = setFloat(dt, func(val float64) { l.End[2] = val })
-		}
-		if err != nil {
-			return l, err
 		}
 	}
-	return l, nil
+	return 0
 }
 
-// Parse3DFace parses 3DFACE entities.
-func Parse3DFace(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
-	t := entity.New3DFace()
-	fourth := 0
-	var err error
+// recordOwner returns the owner handle of record (code 330 outside of 102 groups),
+// or 0 if it has no owner.
+func recordOwner(data []Tag) int {
+	group := false
 	for _, dt := range data {
-		switch dt[0] {
-		default:
+		switch {
+		case dt.Code == 102:
+			group = strings.HasPrefix(dt.Value, "{")
+		case dt.Code == 330 && !group:
+			h, _ := dt.Handle()
+			return h
+		case dt.Code == 100:
+			return 0
+		}
+	}
+	return 0
+}
+
+// rawTags converts tags of record except type (code 0) and handle (code 5) into format.Tag.
+// Values of handle group codes are kept as references to be resolved after reading.
+func rawTags(data []Tag) []format.Tag {
+	tags := make([]format.Tag, 0, len(data))
+	own := false
+	for _, dt := range data[1:] {
+		if dt.Code == 5 && !own {
+			own = true
 			continue
-		case "8":
-			layer, err := d.Layer(dt[1], false)
-			if err == nil {
-				t.SetLayer(layer)
-			}
-		case "48":
-			err = setFloat(dt, func(val float64) { t.SetLtscale(val) })
-		case "10":
-			err = setFloat(dt, func(val float64) { t.Points[0][0] = val })
-		case "20":
-			err = setFloat(dt, func(val float64) { t.Points[0][1] = val })
-		case "30":
-			err = setFloat(dt, func(val float64) { t.Points[0][2] = val })
-		case "11":
-			err = setFloat(dt, func(val float64) { t.Points[1][0] = val })
-		case "21":
-			err = setFloat(dt, func(val float64) { t.Points[1][1] = val })
-		case "31":
-			err = setFloat(dt, func(val float64) { t.Points[1][2] = val })
-		case "12":
-			err = setFloat(dt, func(val float64) { t.Points[2][0] = val })
-		case "22":
-			err = setFloat(dt, func(val float64) { t.Points[2][1] = val })
-		case "32":
-			err = setFloat(dt, func(val float64) { t.Points[2][2] = val })
-		case "13":
-			err = setFloat(dt, func(val float64) {
-				t.Points[3][0] = val
-				fourth |= 1
-			})
-		case "23":
-			err = setFloat(dt, func(val float64) {
-				t.Points[3][1] = val
-				fourth |= 2
-			})
-		case "33":
-			err = setFloat(dt, func(val float64) {
-				t.Points[3][2] = val
-				fourth |= 4
-			})
-		case "70":
-			err = setInt(dt, func(val int) { t.Flag = val })
 		}
-		if err != nil {
-			return t, err
+		tags = append(tags, rawTag(dt))
+	}
+	return tags
+}
+
+// rawTag converts a tag into format.Tag.
+// A value of handle group code is kept as a reference to be resolved after reading.
+func rawTag(dt Tag) format.Tag {
+	t := format.Tag{Code: dt.Code, Value: dt.Value}
+	if format.CodeType(dt.Code) == format.HANDLE && dt.Code != 105 {
+		if h, err := dt.Handle(); err == nil && h != 0 {
+			t.Ref = handle.Ref(h)
 		}
 	}
-	if fourth != 7 {
-		for i := 0; i < 3; i++ {
-			t.Points[3][i] = t.Points[2][i]
+	return t
+}
+
+// resolve returns the element which had handle of h in the source file.
+// If h is not a reference read from the file or the element is not loaded, h is returned.
+func resolve(d *drawing.Drawing, h handle.Handler) handle.Handler {
+	r, ok := h.(handle.Ref)
+	if !ok {
+		return h
+	}
+	if e, err := d.SourceHandle(int(r)); err == nil {
+		return e
+	}
+	return h
+}
+
+// resolveTags resolves references in tags.
+func resolveTags(d *drawing.Drawing, tags []format.Tag) {
+	for i, t := range tags {
+		if t.Ref != nil {
+			tags[i].Ref = resolve(d, t.Ref)
 		}
 	}
-	return t, nil
 }
 
-// ParseLwPolyline parses LWPOLYLINE entities.
-func ParseLwPolyline(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
-	lw := entity.NewLwPolyline(0)
-	ind := 0
-	read := 0
-	var err error
-	for _, dt := range data {
-		switch dt[0] {
+// resolveReferences replaces handles read from the file with the elements they refer to,
+// so that the references follow handles assigned on writing.
+// References to elements which are not loaded are kept as they are.
+func resolveReferences(d *drawing.Drawing) {
+	es := d.Entities()
+	for _, b := range d.Blocks() {
+		es = append(es[:len(es):len(es)], b.Entities...)
+	}
+	ref := func(h handle.Handler) handle.Handler { return resolve(d, h) }
+	for _, t := range d.Sections[drawing.TABLES].(table.Tables) {
+		for _, st := range t.Records() {
+			resolveXData(st, ref)
+		}
+	}
+	for _, e := range es {
+		resolveXData(e, ref)
+		if o, ok := e.(interface {
+			Owner() handle.Handler
+			SetOwner(handle.Handler)
+		}); ok {
+			o.SetOwner(resolve(d, o.Owner()))
+		}
+		switch e := e.(type) {
+		case *entity.Unknown:
+			resolveTags(d, e.Tags)
+		case *entity.Insert:
+			for _, a := range e.Attributes {
+				resolveXData(a, ref)
+				resolveTags(d, a.Tags)
+			}
+		case *entity.Leader:
+			if e.Annotation != nil {
+				e.Annotation = resolve(d, e.Annotation)
+			}
+		case *entity.MLeader:
+			resolveMLeader(d, e)
+		case *entity.Hatch:
+			for _, p := range e.Paths {
+				for i, s := range p.Sources {
+					p.Sources[i] = resolve(d, s)
+				}
+			}
+		}
+	}
+	for _, o := range d.Sections[drawing.OBJECTS].(object.Objects) {
+		if x, ok := o.(interface {
+			Owner() handle.Handler
+			SetOwner(handle.Handler)
+		}); ok {
+			x.SetOwner(resolve(d, x.Owner()))
+		}
+		switch o := o.(type) {
+		case *object.Unknown:
+			resolveTags(d, o.Tags)
+		case *object.Dictionary:
+			for _, key := range o.Keys() {
+				item, _ := o.Item(key)
+				o.SetItem(key, resolve(d, item))
+			}
+		case *object.XRecord:
+			resolveTags(d, o.Tags)
+		case *object.Layout:
+			o.ShadePlot = resolve(d, o.ShadePlot)
+			o.BlockRecord = resolve(d, o.BlockRecord)
+			o.Viewport = resolve(d, o.Viewport)
+			o.NamedUcs = resolve(d, o.NamedUcs)
+			o.BaseUcs = resolve(d, o.BaseUcs)
+		case *object.PlotSettings:
+			o.ShadePlot = resolve(d, o.ShadePlot)
+		}
+	}
+}
+
+// resolveXData resolves handle values of XDATA if x can hold XDATA.
+func resolveXData(x interface{}, ref func(handle.Handler) handle.Handler) {
+	if h, ok := x.(interface {
+		MapXDataHandles(func(handle.Handler) handle.Handler)
+	}); ok {
+		h.MapXDataHandles(ref)
+	}
+}
+
+// resolveMLeader resolves references to styles and blocks of MULTILEADER.
+func resolveMLeader(d *drawing.Drawing, l *entity.MLeader) {
+	ref := func(h *handle.Handler) {
+		if *h != nil {
+			*h = resolve(d, *h)
+		}
+	}
+	ref(&l.Style)
+	ref(&l.LineType)
+	ref(&l.Arrowhead)
+	ref(&l.TextStyle)
+	ref(&l.Block)
+	for i := range l.Arrowheads {
+		ref(&l.Arrowheads[i].Arrowhead)
+	}
+	for i := range l.Attributes {
+		ref(&l.Attributes[i].Attdef)
+	}
+	if l.Context.Text != nil {
+		ref(&l.Context.Text.Style)
+	}
+	if l.Context.Block != nil {
+		ref(&l.Context.Block.Block)
+	}
+}
+
+// readVertices reads VERTEX records up to SEQEND and appends them to Polyline.
+// Malformed vertices are reported as records of the section.
+func readVertices(d *drawing.Drawing, r *TagReader, p *entity.Polyline, section string) error {
+	for {
+		next, err := r.Peek()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if next.Code != 0 {
+			r.Next()
+			continue
+		}
+		if next.Is(0, "SEQEND") {
+			data, err := r.Record()
+			if err == nil {
+				d.RegisterEndHandle(recordHandle(data), p)
+			}
+			return err
+		}
+		if !next.Is(0, "VERTEX") {
+			return nil // SEQEND is missing
+		}
+		data, err := r.Record()
+		if err != nil {
+			return err
+		}
+		v, err := ParseVertex(d, data)
+		if err == nil {
+			err = parseXData(v, data)
+		}
+		if err != nil {
+			if err := r.skip(section, data, err); err != nil {
+				return err
+			}
+			continue
+		}
+		p.AppendVertex(v)
+		d.RegisterHandle(recordHandle(data), v)
+	}
+}
+
+// readAttributes reads ATTRIB records up to SEQEND and appends them to Insert.
+// Attributes are kept as Unknown entities to be written back as they are read.
+func readAttributes(d *drawing.Drawing, r *TagReader, i *entity.Insert, section string) error {
+	for {
+		next, err := r.Peek()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if next.Code != 0 {
+			r.Next()
+			continue
+		}
+		if next.Is(0, "SEQEND") {
+			data, err := r.Record()
+			if err == nil {
+				d.RegisterEndHandle(recordHandle(data), i)
+			}
+			return err
+		}
+		if !next.Is(0, "ATTRIB") {
+			return nil // INSERT without attributes, or SEQEND is missing
+		}
+		data, err := r.Record()
+		if err != nil {
+			return err
+		}
+		a, err := ParseEntity(d, data)
+		if err != nil {
+			if err := r.skip(section, data, err); err != nil {
+				return err
+			}
+			continue
+		}
+		u, ok := a.(*entity.Unknown)
+		if !ok {
+			if err := r.skip(section, data, fmt.Errorf("ATTRIB parsed as %T", a)); err != nil {
+				return err
+			}
+			continue
+		}
+		i.AddAttribute(u)
+		d.RegisterHandle(recordHandle(data), u)
+	}
+}
+
+// ParseEntity parses each entity.
+func ParseEntity(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("no data")
+	}
+	if data[0].Code != 0 {
+		return nil, fmt.Errorf("ParseEntity invalid group code: %d", data[0].Code)
+	}
+	f, err := ParseEntityFunc(data[0].Value)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, nil
+	}
+	e, err := f(d, data)
+	if err != nil {
+		return e, err
+	}
+	if o, ok := e.(interface{ SetOwner(handle.Handler) }); ok {
+		setOwner(o, data)
+	}
+	if err := parseColor(e, data); err != nil {
+		return e, err
+	}
+	return e, parseXData(e, data)
+}
+
+// parseColor parses color (code 62), true color (code 420) and transparency (code 440)
+// in common part of entity, which ends at the first subclass marker other than AcDbEntity.
+func parseColor(e entity.Entity, data []Tag) error {
+	if _, ok := e.(*entity.Unknown); ok {
+		return nil
+	}
+	c, ok := e.(interface {
+		SetColor(int)
+		SetTrueColor(int)
+		SetTransparency(int)
+	})
+	if !ok {
+		return nil
+	}
+	for _, dt := range data[1:] {
+		if dt.Code == 100 && dt.Value != "AcDbEntity" {
+			break
+		}
+		var setter func(int)
+		switch dt.Code {
+		case 62:
+			setter = c.SetColor
+		case 420:
+			setter = c.SetTrueColor
+		case 440:
+			setter = c.SetTransparency
+		default:
+			continue
+		}
+		v, err := dt.Int()
+		if err != nil {
+			return newTagError(dt, err)
+		}
+		setter(v)
+	}
+	return nil
+}
+
+// parseXData parses XDATA, which starts with an application name (code 1001),
+// and sets them to x if it can hold XDATA.
+// Handle values (code 1005) are kept as references to be resolved after reading.
+func parseXData(x interface{}, data []Tag) error {
+	holder, ok := x.(interface {
+		SetXData(string, ...xdata.Value) error
+	})
+	if !ok {
+		return nil
+	}
+	app := ""
+	values := make([]xdata.Value, 0)
+	flush := func() error {
+		if app == "" {
+			return nil
+		}
+		return holder.SetXData(app, values...)
+	}
+	for _, dt := range data[xdataIndex(data):] {
+		var err error
+		switch {
+		case dt.Code == 1001:
+			if err := flush(); err != nil {
+				return err
+			}
+			app = dt.Value
+			values = make([]xdata.Value, 0)
+			continue
+		case app == "":
+			continue
+		case dt.Code >= 1010 && dt.Code <= 1013:
+			err = setFloat(dt, func(val float64) {
+				values = append(values, xdata.Value{Code: dt.Code, Value: []float64{val, 0.0, 0.0}})
+			})
+		case dt.Code >= 1020 && dt.Code <= 1033:
+			last := len(values) - 1
+			if last < 0 || values[last].Code != 1010+dt.Code%10 {
+				return newTagError(dt, errors.New("xdata point is not started"))
+			}
+			err = setFloat(dt, func(val float64) { values[last].Value.([]float64)[dt.Code/10%10-1] = val })
+		case dt.Code == 1004:
+			b, e := dt.Bytes()
+			if e != nil {
+				return newTagError(dt, e)
+			}
+			values = append(values, xdata.Binary(b))
+		case dt.Code == 1005:
+			h, e := dt.Handle()
+			if e != nil {
+				return newTagError(dt, e)
+			}
+			values = append(values, xdata.Handle(handle.Ref(h)))
+		case dt.Code >= 1040 && dt.Code <= 1042:
+			err = setFloat(dt, func(val float64) { values = append(values, xdata.Value{Code: dt.Code, Value: val}) })
+		case dt.Code == 1070 || dt.Code == 1071:
+			err = setInt(dt, func(val int) { values = append(values, xdata.Value{Code: dt.Code, Value: val}) })
+		default:
+			values = append(values, xdata.Value{Code: dt.Code, Value: dt.Value})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+// xdataIndex returns the index of the first application name (code 1001) of record,
+// or the length of record if it has no XDATA.
+func xdataIndex(data []Tag) int {
+	for i, dt := range data {
+		if dt.Code == 1001 {
+			return i
+		}
+	}
+	return len(data)
+}
+
+// ParseEntityFunc returns a function for parsing acoording to entity type string.
+func ParseEntityFunc(t string) (func(*drawing.Drawing, []Tag) (entity.Entity, error), error) {
+	switch t {
+	case "LINE":
+		return ParseLine, nil
+	case "3DFACE":
+		return Parse3DFace, nil
+	case "LWPOLYLINE":
+		return ParseLwPolyline, nil
+	case "CIRCLE":
+		return ParseCircle, nil
+	case "ARC":
+		return ParseArc, nil
+	case "INSERT":
+		return ParseInsert, nil
+	case "POLYLINE":
+		return ParsePolyline, nil
+	case "ELLIPSE":
+		return ParseEllipse, nil
+	case "SPLINE":
+		return ParseSpline, nil
+	case "MTEXT":
+		return ParseMText, nil
+	case "HATCH":
+		return ParseHatch, nil
+	case "DIMENSION":
+		return ParseDimension, nil
+	case "LEADER":
+		return ParseLeader, nil
+	case "MULTILEADER", "MLEADER":
+		return ParseMLeader, nil
+	case "VERTEX", "SEQEND":
+		return nil, nil
+	// case "VERTEX":
+	// 	return ParseVertex, nil
+	case "POINT":
+		return ParsePoint, nil
+	case "TEXT":
+		return ParseText, nil
+	default:
+		return ParseUnknown, nil
+	}
+}
+
+// ParseUnknown parses entities not supported by this package,
+// keeping their tags to write them back.
+func ParseUnknown(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	u := entity.NewUnknown(data[0].Value, rawTags(data[:xdataIndex(data)]))
+	for _, dt := range data {
+		if dt.Code == 8 {
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				u.SetLayer(layer)
+			}
+			break
+		}
+	}
+	return u, nil
+}
+
+// ParseLine parses LINE entities.
+func ParseLine(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	l := entity.NewLine()
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				l.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { l.SetLtscale(val) })
+		case 10:
+			err = setFloat(dt, func(val float64) { l.Start[0] = val })
+		case 20:
+			err = setFloat(dt, func(val float64) { l.Start[1] = val })
+		case 30:
+			err = setFloat(dt, func(val float64) { l.Start[2] = val })
+		case 11:
+			err = setFloat(dt, func(val float64) { l.End[0] = val })
+		case 21:
+			err = setFloat(dt, func(val float64) { l.End[1] = val })
+		case 31:
+			err = setFloat(dt, func(val float64) { l.End[2] = val })
+		}
+		if err != nil {
+			return l, err
+		}
+	}
+	return l, nil
+}
+
+// Parse3DFace parses 3DFACE entities.
+func Parse3DFace(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	t := entity.New3DFace()
+	fourth := 0
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				t.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { t.SetLtscale(val) })
+		case 10:
+			err = setFloat(dt, func(val float64) { t.Points[0][0] = val })
+		case 20:
+			err = setFloat(dt, func(val float64) { t.Points[0][1] = val })
+		case 30:
+			err = setFloat(dt, func(val float64) { t.Points[0][2] = val })
+		case 11:
+			err = setFloat(dt, func(val float64) { t.Points[1][0] = val })
+		case 21:
+			err = setFloat(dt, func(val float64) { t.Points[1][1] = val })
+		case 31:
+			err = setFloat(dt, func(val float64) { t.Points[1][2] = val })
+		case 12:
+			err = setFloat(dt, func(val float64) { t.Points[2][0] = val })
+		case 22:
+			err = setFloat(dt, func(val float64) { t.Points[2][1] = val })
+		case 32:
+			err = setFloat(dt, func(val float64) { t.Points[2][2] = val })
+		case 13:
+			err = setFloat(dt, func(val float64) {
+				t.Points[3][0] = val
+				fourth |= 1
+			})
+		case 23:
+			err = setFloat(dt, func(val float64) {
+				t.Points[3][1] = val
+				fourth |= 2
+			})
+		case 33:
+			err = setFloat(dt, func(val float64) {
+				t.Points[3][2] = val
+				fourth |= 4
+			})
+		case 70:
+			err = setInt(dt, func(val int) { t.Flag = val })
+		}
+		if err != nil {
+			return t, err
+		}
+	}
+	if fourth != 7 {
+		for i := 0; i < 3; i++ {
+			t.Points[3][i] = t.Points[2][i]
+		}
+	}
+	return t, nil
+}
+
+// ParseLwPolyline parses LWPOLYLINE entities.
+func ParseLwPolyline(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	lw := entity.NewLwPolyline(0)
+	ind := 0
+	read := 0
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				lw.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { lw.SetLtscale(val) })
+		case 90:
+			err = setInt(dt, func(val int) {
+				v := entity.NewLwPolyline(val)
+				lw.Num = val
+				lw.Vertices = v.Vertices
+				lw.Widths = v.Widths
+				lw.Bulges = v.Bulges
+			})
+		case 10:
+			if lw.Num > ind {
+				err = setFloat(dt, func(val float64) {
+					lw.Vertices[ind][0] = val
+					read |= 1
+				})
+			} else {
+				err = newTagError(dt, errors.New("LWPOLYLINE extra vertices"))
+			}
+		case 20:
+			if lw.Num > ind {
+				err = setFloat(dt, func(val float64) {
+					lw.Vertices[ind][1] = val
+					read |= 2
+				})
+			} else {
+				err = newTagError(dt, errors.New("LWPOLYLINE extra vertices"))
+			}
+		case 70:
+			err = setInt(dt, func(val int) {
+				if val&1 == 1 {
+					lw.Close()
+				}
+			})
+		case 38:
+			err = setFloat(dt, func(val float64) { lw.Elevation = val })
+		case 39:
+			err = setFloat(dt, func(val float64) { lw.Thickness = val })
+		case 43:
+			err = setFloat(dt, func(val float64) { lw.ConstantWidth = val })
+		case 40, 41, 42:
+			// per vertex values follow the coordinates of the vertex
+			if ind == 0 {
+				err = newTagError(dt, errors.New("LWPOLYLINE value before vertex"))
+				break
+			}
+			i := ind - 1
+			err = setFloat(dt, func(val float64) {
+				switch dt.Code {
+				case 40:
+					lw.Widths[i][0] = val
+				case 41:
+					lw.Widths[i][1] = val
+				default:
+					lw.Bulges[i] = val
+				}
+			})
+		case 210:
+			err = setFloat(dt, func(val float64) { lw.Direction[0] = val })
+		case 220:
+			err = setFloat(dt, func(val float64) { lw.Direction[1] = val })
+		case 230:
+			err = setFloat(dt, func(val float64) { lw.Direction[2] = val })
+		}
+		if err != nil {
+			return lw, err
+		}
+		if read == 3 {
+			read = 0
+			ind++
+		}
+	}
+	if ind != lw.Num {
+		return lw, fmt.Errorf("LWPOLYLINE not enough vertices")
+	}
+	return lw, nil
+}
+
+// ParsePolyline parses POLYLINE entities.
+// Its vertices are parsed by ParseVertex.
+func ParsePolyline(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	p := entity.NewPolyline()
+	p.Flag = 0
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				p.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { p.SetLtscale(val) })
+		case 30:
+			err = setFloat(dt, func(val float64) { p.Elevation = val })
+		case 39:
+			err = setFloat(dt, func(val float64) { p.Thickness = val })
+		case 70:
+			err = setInt(dt, func(val int) { p.Flag = val })
+		case 40:
+			err = setFloat(dt, func(val float64) { p.StartWidth = val })
+		case 41:
+			err = setFloat(dt, func(val float64) { p.EndWidth = val })
+		case 71:
+			err = setInt(dt, func(val int) { p.MCount = val })
+		case 72:
+			err = setInt(dt, func(val int) { p.NCount = val })
+		case 73:
+			err = setInt(dt, func(val int) { p.MDensity = val })
+		case 74:
+			err = setInt(dt, func(val int) { p.NDensity = val })
+		case 75:
+			err = setInt(dt, func(val int) { p.SmoothType = val })
+		case 210:
+			err = setFloat(dt, func(val float64) { p.Direction[0] = val })
+		case 220:
+			err = setFloat(dt, func(val float64) { p.Direction[1] = val })
+		case 230:
+			err = setFloat(dt, func(val float64) { p.Direction[2] = val })
+		}
+		if err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// ParseVertex parses VERTEX entities of POLYLINE.
+func ParseVertex(d *drawing.Drawing, data []Tag) (*entity.Vertex, error) {
+	v := entity.NewVertex(0.0, 0.0, 0.0)
+	v.Flag = 0
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				v.SetLayer(layer)
+			}
+		case 10:
+			err = setFloat(dt, func(val float64) { v.Coord[0] = val })
+		case 20:
+			err = setFloat(dt, func(val float64) { v.Coord[1] = val })
+		case 30:
+			err = setFloat(dt, func(val float64) { v.Coord[2] = val })
+		case 40:
+			err = setFloat(dt, func(val float64) { v.StartWidth = val })
+		case 41:
+			err = setFloat(dt, func(val float64) { v.EndWidth = val })
+		case 42:
+			err = setFloat(dt, func(val float64) { v.Bulge = val })
+		case 50:
+			err = setFloat(dt, func(val float64) { v.Tangent = val })
+		case 70:
+			err = setInt(dt, func(val int) { v.Flag = val })
+		case 71, 72, 73, 74:
+			err = setInt(dt, func(val int) { v.Indices[dt.Code-71] = val })
+		}
+		if err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// ParseEllipse parses ELLIPSE entities.
+func ParseEllipse(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	e := entity.NewEllipse()
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				e.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { e.SetLtscale(val) })
+		case 10:
+			err = setFloat(dt, func(val float64) { e.Center[0] = val })
+		case 20:
+			err = setFloat(dt, func(val float64) { e.Center[1] = val })
+		case 30:
+			err = setFloat(dt, func(val float64) { e.Center[2] = val })
+		case 11:
+			err = setFloat(dt, func(val float64) { e.MajorAxis[0] = val })
+		case 21:
+			err = setFloat(dt, func(val float64) { e.MajorAxis[1] = val })
+		case 31:
+			err = setFloat(dt, func(val float64) { e.MajorAxis[2] = val })
+		case 40:
+			err = setFloat(dt, func(val float64) { e.Ratio = val })
+		case 41:
+			err = setFloat(dt, func(val float64) { e.Start = val })
+		case 42:
+			err = setFloat(dt, func(val float64) { e.End = val })
+		case 210:
+			err = setFloat(dt, func(val float64) { e.Direction[0] = val })
+		case 220:
+			err = setFloat(dt, func(val float64) { e.Direction[1] = val })
+		case 230:
+			err = setFloat(dt, func(val float64) { e.Direction[2] = val })
+		}
+		if err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+// ParseSpline parses SPLINE entities.
+func ParseSpline(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	s := entity.NewSpline()
+	s.Flag = 0
+	var err error
+	// last returns the last point of ps, or an error if no point is started.
+	last := func(ps [][]float64, code int) ([]float64, error) {
+		if len(ps) == 0 {
+			return nil, fmt.Errorf("SPLINE code %d before point", code)
+		}
+		return ps[len(ps)-1], nil
+	}
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				s.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { s.SetLtscale(val) })
+		case 70:
+			err = setInt(dt, func(val int) { s.Flag = val })
+		case 71:
+			err = setInt(dt, func(val int) { s.Degree = val })
+		case 42, 43, 44:
+			err = setFloat(dt, func(val float64) { s.Tolerance[dt.Code-42] = val })
+		case 40:
+			err = setFloat(dt, func(val float64) { s.Knots = append(s.Knots, val) })
+		case 41:
+			err = setFloat(dt, func(val float64) { s.Weights = append(s.Weights, val) })
+		case 10:
+			err = setFloat(dt, func(val float64) { s.Controls = append(s.Controls, []float64{val, 0.0, 0.0}) })
+		case 11:
+			err = setFloat(dt, func(val float64) { s.Fits = append(s.Fits, []float64{val, 0.0, 0.0}) })
+		case 20, 30:
+			var p []float64
+			if p, err = last(s.Controls, dt.Code); err == nil {
+				err = setFloat(dt, func(val float64) { p[dt.Code/10-1] = val })
+			}
+		case 21, 31:
+			var p []float64
+			if p, err = last(s.Fits, dt.Code); err == nil {
+				err = setFloat(dt, func(val float64) { p[dt.Code/10-1] = val })
+			}
+		case 12, 22, 32:
+			if s.StartTangent == nil {
+				s.StartTangent = make([]float64, 3)
+			}
+			err = setFloat(dt, func(val float64) { s.StartTangent[dt.Code/10-1] = val })
+		case 13, 23, 33:
+			if s.EndTangent == nil {
+				s.EndTangent = make([]float64, 3)
+			}
+			err = setFloat(dt, func(val float64) { s.EndTangent[dt.Code/10-1] = val })
+		case 210:
+			err = setFloat(dt, func(val float64) { s.Normal[0] = val })
+		case 220:
+			err = setFloat(dt, func(val float64) { s.Normal[1] = val })
+		case 230:
+			err = setFloat(dt, func(val float64) { s.Normal[2] = val })
+		}
+		if err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// ParseMText parses MTEXT entities.
+func ParseMText(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	t := entity.NewMText()
+	var err error
+	var value strings.Builder
+	rotation, xaxis := 0.0, false
+loop:
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 101:
+			// embedded object of R2018 and later, whose codes overlap those above
+			break loop
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				t.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { t.SetLtscale(val) })
+		case 10:
+			err = setFloat(dt, func(val float64) { t.Coord[0] = val })
+		case 20:
+			err = setFloat(dt, func(val float64) { t.Coord[1] = val })
+		case 30:
+			err = setFloat(dt, func(val float64) { t.Coord[2] = val })
+		case 11, 21, 31:
+			xaxis = true
+			err = setFloat(dt, func(val float64) { t.XAxis[dt.Code/10-1] = val })
+		case 40:
+			err = setFloat(dt, func(val float64) { t.Height = val })
+		case 41:
+			err = setFloat(dt, func(val float64) { t.Width = val })
+		case 44:
+			err = setFloat(dt, func(val float64) { t.LineSpacingFactor = val })
+		case 50:
+			err = setFloat(dt, func(val float64) { rotation = val })
+		case 71:
+			err = setInt(dt, func(val int) { t.Attachment = val })
+		case 72:
+			err = setInt(dt, func(val int) { t.DrawingDirection = val })
+		case 73:
+			err = setInt(dt, func(val int) { t.LineSpacingStyle = val })
+		case 1, 3:
+			value.WriteString(dt.Value)
+		case 7:
+			if s, ok := d.Styles[dt.Value]; ok {
+				t.Style = s
+			}
+		case 210:
+			err = setFloat(dt, func(val float64) { t.Direction[0] = val })
+		case 220:
+			err = setFloat(dt, func(val float64) { t.Direction[1] = val })
+		case 230:
+			err = setFloat(dt, func(val float64) { t.Direction[2] = val })
+		}
+		if err != nil {
+			return t, err
+		}
+	}
+	t.Value = value.String()
+	if !xaxis && rotation != 0.0 {
+		// code 50 is in Radian, and ignored if code 11 exists
+		t.SetRotation(rotation * 180.0 / math.Pi)
+	}
+	return t, nil
+}
+
+// hatchReader reads tags of HATCH in order,
+// since the same group codes appear in boundary paths, pattern lines and seed points.
+type hatchReader struct {
+	data []Tag
+	pos  int
+	err  error
+}
+
+// next returns the next tag if its code is one of codes.
+func (r *hatchReader) next(codes ...int) (Tag, bool) {
+	if r.err != nil || r.pos >= len(r.data) {
+		return Tag{}, false
+	}
+	for _, c := range codes {
+		if r.data[r.pos].Code == c {
+			r.pos++
+			return r.data[r.pos-1], true
+		}
+	}
+	return Tag{}, false
+}
+
+// float reads the next tag of code as float if exists.
+func (r *hatchReader) float(code int, f func(float64)) {
+	if dt, ok := r.next(code); ok && r.err == nil {
+		r.err = setFloat(dt, f)
+	}
+}
+
+// int reads the next tag of code as int if exists.
+func (r *hatchReader) int(code int, f func(int)) {
+	if dt, ok := r.next(code); ok && r.err == nil {
+		r.err = setInt(dt, f)
+	}
+}
+
+// point reads the next 2D point of code and code+10.
+func (r *hatchReader) point(code int) []float64 {
+	p := []float64{0.0, 0.0}
+	r.float(code, func(val float64) { p[0] = val })
+	r.float(code+10, func(val float64) { p[1] = val })
+	return p
+}
+
+// count reads the next tag of code as the number of following items.
+func (r *hatchReader) count(code int) int {
+	n := 0
+	r.int(code, func(val int) { n = val })
+	return n
+}
+
+// ParseHatch parses HATCH entities.
+func ParseHatch(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	h := entity.NewHatch()
+	r := &hatchReader{data: data}
+	for r.err == nil && r.pos < len(data) {
+		dt := data[r.pos]
+		r.pos++
+		switch dt.Code {
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				h.SetLayer(layer)
+			}
+		case 48:
+			r.err = setFloat(dt, func(val float64) { h.SetLtscale(val) })
+		case 30:
+			r.err = setFloat(dt, func(val float64) { h.Elevation = val })
+		case 210:
+			r.err = setFloat(dt, func(val float64) { h.Direction[0] = val })
+		case 220:
+			r.err = setFloat(dt, func(val float64) { h.Direction[1] = val })
+		case 230:
+			r.err = setFloat(dt, func(val float64) { h.Direction[2] = val })
+		case 2:
+			h.PatternName = dt.Value
+		case 70:
+			r.err = setInt(dt, func(val int) { h.Solid = val != 0 })
+		case 71:
+			r.err = setInt(dt, func(val int) { h.Associative = val != 0 })
+		case 91:
+			n := 0
+			r.err = setInt(dt, func(val int) { n = val })
+			h.Paths = make([]*entity.HatchPath, 0, n)
+			for i := 0; i < n && r.err == nil; i++ {
+				h.Paths = append(h.Paths, r.path())
+			}
+		case 75:
+			r.err = setInt(dt, func(val int) { h.Style = val })
+		case 76:
+			r.err = setInt(dt, func(val int) { h.PatternType = val })
+		case 52:
+			r.err = setFloat(dt, func(val float64) { h.PatternAngle = val })
+		case 41:
+			r.err = setFloat(dt, func(val float64) { h.PatternScale = val })
+		case 77:
+			r.err = setInt(dt, func(val int) { h.PatternDouble = val != 0 })
+		case 78:
+			n := 0
+			r.err = setInt(dt, func(val int) { n = val })
+			for i := 0; i < n && r.err == nil; i++ {
+				h.PatternLines = append(h.PatternLines, r.patternLine())
+			}
+		case 47:
+			r.err = setFloat(dt, func(val float64) { h.PixelSize = val })
+		case 98:
+			n := 0
+			r.err = setInt(dt, func(val int) { n = val })
+			for i := 0; i < n && r.err == nil; i++ {
+				h.Seeds = append(h.Seeds, r.point(10))
+			}
+		case 450:
+			r.err = setInt(dt, func(val int) {
+				if val != 0 {
+					h.Gradient = &entity.Gradient{}
+				}
+			})
+		case 452, 453, 460, 461, 462, 463, 421, 470:
+			if h.Gradient != nil {
+				r.gradient(h.Gradient, dt)
+			}
+		}
+	}
+	if r.err != nil {
+		return h, fmt.Errorf("HATCH: %w", r.err)
+	}
+	return h, nil
+}
+
+// path reads a boundary path.
+func (r *hatchReader) path() *entity.HatchPath {
+	p := &entity.HatchPath{}
+	r.int(92, func(val int) { p.Flag = val })
+	if p.IsPolyline() {
+		bulge := false
+		r.int(72, func(val int) { bulge = val != 0 })
+		r.int(73, func(val int) { p.Closed = val != 0 })
+		n := r.count(93)
+		p.Vertices = make([][]float64, 0, n)
+		p.Bulges = make([]float64, 0, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			p.Vertices = append(p.Vertices, r.point(10))
+			b := 0.0
+			if bulge {
+				r.float(42, func(val float64) { b = val })
+			}
+			p.Bulges = append(p.Bulges, b)
+		}
+	} else {
+		n := r.count(93)
+		p.Edges = make([]entity.HatchEdge, 0, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			if e := r.edge(); e != nil {
+				p.Edges = append(p.Edges, e)
+			}
+		}
+	}
+	n := r.count(97)
+	for i := 0; i < n && r.err == nil; i++ {
+		if dt, ok := r.next(330); ok {
+			r.err = setHandle(dt, func(h handle.Handler) { p.Sources = append(p.Sources, h) })
+		}
+	}
+	return p
+}
+
+// edge reads an edge of boundary path.
+func (r *hatchReader) edge() entity.HatchEdge {
+	t := 0
+	r.int(72, func(val int) { t = val })
+	switch t {
+	case entity.HATCH_EDGE_LINE:
+		return &entity.LineEdge{Start: r.point(10), End: r.point(11)}
+	case entity.HATCH_EDGE_ARC:
+		e := &entity.ArcEdge{Center: r.point(10)}
+		r.float(40, func(val float64) { e.Radius = val })
+		r.float(50, func(val float64) { e.StartAngle = val })
+		r.float(51, func(val float64) { e.EndAngle = val })
+		r.int(73, func(val int) { e.CounterClockwise = val != 0 })
+		return e
+	case entity.HATCH_EDGE_ELLIPSE:
+		e := &entity.EllipseEdge{Center: r.point(10), MajorAxis: r.point(11)}
+		r.float(40, func(val float64) { e.Ratio = val })
+		r.float(50, func(val float64) { e.StartAngle = val })
+		r.float(51, func(val float64) { e.EndAngle = val })
+		r.int(73, func(val int) { e.CounterClockwise = val != 0 })
+		return e
+	case entity.HATCH_EDGE_SPLINE:
+		e := &entity.SplineEdge{}
+		r.int(94, func(val int) { e.Degree = val })
+		r.int(73, func(val int) { e.Rational = val != 0 })
+		r.int(74, func(val int) { e.Periodic = val != 0 })
+		nk := r.count(95)
+		nc := r.count(96)
+		for i := 0; i < nk && r.err == nil; i++ {
+			r.float(40, func(val float64) { e.Knots = append(e.Knots, val) })
+		}
+		for i := 0; i < nc && r.err == nil; i++ {
+			e.Controls = append(e.Controls, r.point(10))
+			r.float(42, func(val float64) { e.Weights = append(e.Weights, val) })
+		}
+		// fit data exists since R2010; otherwise code 97 is the number of source objects
+		nf := 0
+		if r.fitData() {
+			nf = r.count(97)
+		}
+		for i := 0; i < nf && r.err == nil; i++ {
+			e.Fits = append(e.Fits, r.point(11))
+		}
+		if r.pos < len(r.data) && r.data[r.pos].Code == 12 {
+			e.StartTangent = r.point(12)
+		}
+		if r.pos < len(r.data) && r.data[r.pos].Code == 13 {
+			e.EndTangent = r.point(13)
+		}
+		return e
+	default:
+		r.err = fmt.Errorf("unknown edge type: %d", t)
+		return nil
+	}
+}
+
+// fitData reports whether the next code 97 is the number of fit points of spline edge.
+func (r *hatchReader) fitData() bool {
+	if r.pos+1 >= len(r.data) || r.data[r.pos].Code != 97 {
+		return false
+	}
+	switch r.data[r.pos+1].Code {
+	case 11, 12, 13, 72, 97:
+		return true
+	}
+	return false
+}
+
+// patternLine reads a pattern definition line.
+func (r *hatchReader) patternLine() *entity.PatternLine {
+	l := &entity.PatternLine{Base: []float64{0.0, 0.0}, Offset: []float64{0.0, 0.0}}
+	r.float(53, func(val float64) { l.Angle = val })
+	r.float(43, func(val float64) { l.Base[0] = val })
+	r.float(44, func(val float64) { l.Base[1] = val })
+	r.float(45, func(val float64) { l.Offset[0] = val })
+	r.float(46, func(val float64) { l.Offset[1] = val })
+	n := r.count(79)
+	l.Dashes = make([]float64, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		r.float(49, func(val float64) { l.Dashes = append(l.Dashes, val) })
+	}
+	return l
+}
+
+// gradient sets gradient data of tag dt.
+func (r *hatchReader) gradient(g *entity.Gradient, dt Tag) {
+	last := func() *entity.GradientColor {
+		if len(g.Colors) == 0 {
+			g.Colors = append(g.Colors, entity.GradientColor{})
+		}
+		return &g.Colors[len(g.Colors)-1]
+	}
+	switch dt.Code {
+	case 452:
+		r.err = setInt(dt, func(val int) { g.SingleColor = val != 0 })
+	case 453:
+		r.err = setInt(dt, func(val int) { g.Colors = make([]entity.GradientColor, 0, val) })
+	case 460:
+		r.err = setFloat(dt, func(val float64) { g.Angle = val })
+	case 461:
+		r.err = setFloat(dt, func(val float64) { g.Shift = val })
+	case 462:
+		r.err = setFloat(dt, func(val float64) { g.Tint = val })
+	case 463:
+		// each color starts with code 463
+		r.err = setFloat(dt, func(val float64) { g.Colors = append(g.Colors, entity.GradientColor{Value: val}) })
+		if dt, ok := r.next(63); ok && r.err == nil {
+			r.err = setInt(dt, func(val int) { last().Color = val })
+		}
+	case 421:
+		r.err = setInt(dt, func(val int) { last().RGB = val })
+	case 470:
+		g.Name = dt.Value
+	}
+}
+
+// ParseDimension parses DIMENSION entities.
+func ParseDimension(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	dm := entity.NewDimension(entity.DIM_LINEAR)
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
 		default:
 			continue
-		case "8":
-			layer, err := d.Layer(dt[1], false)
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
 			if err == nil {
-				lw.SetLayer(layer)
+				dm.SetLayer(layer)
 			}
-		case "48":
-			err = setFloat(dt, func(val float64) { lw.SetLtscale(val) })
-		case "90":
-			err = setInt(dt, func(val int) {
-				lw.Num = val
-				lw.Vertices = make([][]float64, val)
-				for i := 0; i < val; i++ {
-					lw.Vertices[i] = make([]float64, 2)
-				}
-			})
-		case "10":
-			if lw.Num > ind {
-				err = setFloat(dt, func(val float64) {
-					lw.Vertices[ind][0] = val
-					read |= 1
-				})
+		case 48:
+			err = setFloat(dt, func(val float64) { dm.SetLtscale(val) })
+		case 2:
+			dm.BlockName = dt.Value
+		case 3:
+			if s, err := d.DimStyle(dt.Value); err == nil {
+				dm.DimStyle = s
 			} else {
-				err = fmt.Errorf("LWPOLYLINE extra vertices")
+				dm.DimStyle = table.NewDimStyle(dt.Value)
 			}
-		case "20":
-			if lw.Num > ind {
-				err = setFloat(dt, func(val float64) {
-					lw.Vertices[ind][1] = val
-					read |= 2
-				})
+		case 10, 20, 30:
+			err = setFloat(dt, func(val float64) { dm.DefPoint[dt.Code/10-1] = val })
+		case 11, 21, 31:
+			err = setFloat(dt, func(val float64) { dm.TextPoint[dt.Code/10-1] = val })
+		case 13, 23, 33:
+			err = setFloat(dt, func(val float64) { dm.DefPoint2[dt.Code/10-1] = val })
+		case 14, 24, 34:
+			err = setFloat(dt, func(val float64) { dm.DefPoint3[dt.Code/10-1] = val })
+		case 15, 25, 35:
+			err = setFloat(dt, func(val float64) { dm.DefPoint4[dt.Code/10-1] = val })
+		case 16, 26, 36:
+			err = setFloat(dt, func(val float64) { dm.DefPoint5[dt.Code/10-1] = val })
+		case 70:
+			err = setInt(dt, func(val int) { dm.Type = val })
+		case 71:
+			err = setInt(dt, func(val int) { dm.Attachment = val })
+		case 72:
+			err = setInt(dt, func(val int) { dm.LineSpacingStyle = val })
+		case 41:
+			err = setFloat(dt, func(val float64) { dm.LineSpacingFactor = val })
+		case 42:
+			err = setFloat(dt, func(val float64) { dm.Measurement = val })
+		case 1:
+			dm.Text = dt.Value
+		case 53:
+			err = setFloat(dt, func(val float64) { dm.TextRotation = val })
+		case 51:
+			err = setFloat(dt, func(val float64) { dm.HorizontalAngle = val })
+		case 50:
+			err = setFloat(dt, func(val float64) { dm.Rotation = val })
+		case 52:
+			err = setFloat(dt, func(val float64) { dm.Oblique = val })
+		case 40:
+			err = setFloat(dt, func(val float64) { dm.LeaderLength = val })
+		case 210:
+			err = setFloat(dt, func(val float64) { dm.Direction[0] = val })
+		case 220:
+			err = setFloat(dt, func(val float64) { dm.Direction[1] = val })
+		case 230:
+			err = setFloat(dt, func(val float64) { dm.Direction[2] = val })
+		}
+		if err != nil {
+			return dm, err
+		}
+	}
+	return dm, nil
+}
+
+// ParseLeader parses LEADER entities.
+func ParseLeader(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	l := entity.NewLeader()
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				l.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { l.SetLtscale(val) })
+		case 3:
+			if s, err := d.DimStyle(dt.Value); err == nil {
+				l.DimStyle = s
 			} else {
-				err = fmt.Errorf("LWPOLYLINE extra vertices")
+				l.DimStyle = table.NewDimStyle(dt.Value)
+			}
+		case 71:
+			err = setInt(dt, func(val int) { l.Arrow = val != 0 })
+		case 72:
+			err = setInt(dt, func(val int) { l.PathType = val })
+		case 73:
+			err = setInt(dt, func(val int) { l.AnnotationType = val })
+		case 74:
+			err = setInt(dt, func(val int) { l.HooklineDirection = val != 0 })
+		case 75:
+			err = setInt(dt, func(val int) { l.Hookline = val != 0 })
+		case 40:
+			err = setFloat(dt, func(val float64) { l.TextHeight = val })
+		case 41:
+			err = setFloat(dt, func(val float64) { l.TextWidth = val })
+		case 10:
+			err = setFloat(dt, func(val float64) { l.AddVertex(val, 0.0, 0.0) })
+		case 20, 30:
+			if len(l.Vertices) == 0 {
+				return l, newTagError(dt, errors.New("LEADER value before vertex"))
+			}
+			err = setFloat(dt, func(val float64) { l.Vertices[len(l.Vertices)-1][dt.Code/10-1] = val })
+		case 77:
+			err = setInt(dt, func(val int) { l.Color = val })
+		case 340:
+			err = setHandle(dt, func(val handle.Handler) { l.Annotation = val })
+		case 210, 220, 230:
+			err = setFloat(dt, func(val float64) { l.Direction[dt.Code/10-21] = val })
+		case 211, 221, 231:
+			err = setFloat(dt, func(val float64) { l.HorizontalDirection[(dt.Code-211)/10] = val })
+		case 212, 222, 232:
+			err = setFloat(dt, func(val float64) { l.BlockOffset[(dt.Code-212)/10] = val })
+		case 213, 223, 233:
+			err = setFloat(dt, func(val float64) { l.AnnotationOffset[(dt.Code-213)/10] = val })
+		}
+		if err != nil {
+			return l, err
+		}
+	}
+	return l, nil
+}
+
+// setAxis sets a coordinate of point p according to group code, such as 10, 20 and 30.
+func setAxis(dt Tag, p []float64) error {
+	return setFloat(dt, func(val float64) { p[(dt.Code/10)%10-1] = val })
+}
+
+// ParseMLeader parses MULTILEADER entities.
+// The context data, leaders and leader lines are nested sections
+// which reuse the same group codes, so they are read in order.
+func ParseMLeader(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	l := entity.NewMLeader()
+	body := false
+	var err error
+	for i := 0; i < len(data); i++ {
+		dt := data[i]
+		if !body {
+			switch {
+			case dt.Code == 8:
+				layer, err := d.Layer(dt.Value, false)
+				if err == nil {
+					l.SetLayer(layer)
+				}
+			case dt.Code == 48:
+				err = setFloat(dt, func(val float64) { l.SetLtscale(val) })
+			case dt.Is(100, "AcDbMLeader"):
+				body = true
+			}
+			if err != nil {
+				return l, fmt.Errorf("MULTILEADER: %w", err)
+			}
+			continue
+		}
+		switch dt.Code {
+		case 270:
+			err = setInt(dt, func(val int) { l.Version = val })
+		case 300:
+			l.Context, i, err = parseMLeaderContext(data, i+1)
+		case 340:
+			err = setHandle(dt, func(val handle.Handler) { l.Style = val })
+		case 90:
+			err = setInt(dt, func(val int) { l.PropertyOverride = val })
+		case 170:
+			err = setInt(dt, func(val int) { l.LeaderType = val })
+		case 91:
+			err = setInt(dt, func(val int) { l.LineColor = val })
+		case 341:
+			err = setHandle(dt, func(val handle.Handler) { l.LineType = val })
+		case 171:
+			err = setInt(dt, func(val int) { l.LineWeight = val })
+		case 290:
+			err = setInt(dt, func(val int) { l.Landing = val != 0 })
+		case 291:
+			err = setInt(dt, func(val int) { l.Dogleg = val != 0 })
+		case 41:
+			err = setFloat(dt, func(val float64) { l.DoglegLength = val })
+		case 342:
+			err = setHandle(dt, func(val handle.Handler) { l.Arrowhead = val })
+		case 42:
+			err = setFloat(dt, func(val float64) { l.ArrowSize = val })
+		case 172:
+			err = setInt(dt, func(val int) { l.ContentType = val })
+		case 343:
+			err = setHandle(dt, func(val handle.Handler) { l.TextStyle = val })
+		case 173:
+			err = setInt(dt, func(val int) { l.TextLeftAttachment = val })
+		case 95:
+			err = setInt(dt, func(val int) { l.TextRightAttachment = val })
+		case 174:
+			err = setInt(dt, func(val int) { l.TextAngleType = val })
+		case 175:
+			err = setInt(dt, func(val int) { l.TextAlignment = val })
+		case 92:
+			err = setInt(dt, func(val int) { l.TextColor = val })
+		case 292:
+			err = setInt(dt, func(val int) { l.TextFrame = val != 0 })
+		case 344:
+			err = setHandle(dt, func(val handle.Handler) { l.Block = val })
+		case 93:
+			err = setInt(dt, func(val int) { l.BlockColor = val })
+		case 10, 20, 30:
+			err = setAxis(dt, l.BlockScale)
+		case 43:
+			err = setFloat(dt, func(val float64) { l.BlockRotation = val })
+		case 176:
+			err = setInt(dt, func(val int) { l.BlockConnection = val })
+		case 293:
+			err = setInt(dt, func(val int) { l.Annotative = val != 0 })
+		case 94:
+			err = setInt(dt, func(val int) { l.Arrowheads = append(l.Arrowheads, entity.MLeaderArrowhead{Index: val}) })
+		case 345:
+			if len(l.Arrowheads) == 0 {
+				l.Arrowheads = append(l.Arrowheads, entity.MLeaderArrowhead{})
 			}
-		case "70":
+			err = setHandle(dt, func(val handle.Handler) { l.Arrowheads[len(l.Arrowheads)-1].Arrowhead = val })
+		case 330:
+			err = setHandle(dt, func(val handle.Handler) { l.Attributes = append(l.Attributes, entity.MLeaderAttribute{Attdef: val}) })
+		case 177, 44, 302:
+			if len(l.Attributes) == 0 {
+				return l, newTagError(dt, errors.New("MULTILEADER value before attribute"))
+			}
+			a := &l.Attributes[len(l.Attributes)-1]
+			switch dt.Code {
+			case 177:
+				err = setInt(dt, func(val int) { a.Index = val })
+			case 44:
+				err = setFloat(dt, func(val float64) { a.Width = val })
+			default:
+				a.Text = dt.Value
+			}
+		case 294:
+			err = setInt(dt, func(val int) { l.TextDirectionNegative = val != 0 })
+		case 178:
+			err = setInt(dt, func(val int) { l.TextIPEAlign = val })
+		case 179:
+			err = setInt(dt, func(val int) { l.TextAttachmentPoint = val })
+		case 45:
+			err = setFloat(dt, func(val float64) { l.Scale = val })
+		case 271:
+			err = setInt(dt, func(val int) { l.TextAttachmentDirection = val })
+		case 272:
+			err = setInt(dt, func(val int) { l.TextBottomAttachment = val })
+		case 273:
+			err = setInt(dt, func(val int) { l.TextTopAttachment = val })
+		case 295:
+			err = setInt(dt, func(val int) { l.LeaderExtendToText = val != 0 })
+		}
+		if err != nil {
+			return l, fmt.Errorf("MULTILEADER: %w", err)
+		}
+	}
+	return l, nil
+}
+
+// parseMLeaderContext parses context data of MULTILEADER starting at data[i],
+// and returns it with the index of its closing tag (code 301).
+func parseMLeaderContext(data []Tag, i int) (*entity.MLeaderContext, int, error) {
+	c := entity.NewMLeaderContext()
+	columns := false
+	var err error
+	for ; i < len(data); i++ {
+		dt := data[i]
+		t, b := c.Text, c.Block
+		switch {
+		case dt.Code == 301:
+			return c, i, nil
+		case dt.Code == 302:
+			var br *entity.MLeaderBranch
+			br, i, err = parseMLeaderBranch(data, i+1)
+			c.Branches = append(c.Branches, br)
+		case dt.Code == 40:
+			err = setFloat(dt, func(val float64) { c.Scale = val })
+		case dt.Code == 10 || dt.Code == 20 || dt.Code == 30:
+			err = setAxis(dt, c.BasePoint)
+		case dt.Code == 41:
+			err = setFloat(dt, func(val float64) { c.TextHeight = val })
+		case dt.Code == 140:
+			err = setFloat(dt, func(val float64) { c.ArrowSize = val })
+		case dt.Code == 145:
+			err = setFloat(dt, func(val float64) { c.LandingGap = val })
+		case dt.Code == 174:
+			err = setInt(dt, func(val int) { c.LeftAttachment = val })
+		case dt.Code == 175:
+			err = setInt(dt, func(val int) { c.RightAttachment = val })
+		case dt.Code == 176:
+			err = setInt(dt, func(val int) { c.TextAlign = val })
+		case dt.Code == 177:
+			err = setInt(dt, func(val int) { c.BlockConnection = val })
+		case dt.Code == 290:
 			err = setInt(dt, func(val int) {
-				if val == 1 {
-					lw.Close()
+				c.Text = nil
+				if val != 0 {
+					c.Text = entity.NewMLeaderText("")
 				}
 			})
+		case dt.Code == 296:
+			err = setInt(dt, func(val int) {
+				c.Block = nil
+				if val != 0 {
+					c.Block = entity.NewMLeaderBlock(nil)
+					c.Block.Matrix = nil
+				}
+			})
+		case dt.Code >= 110 && dt.Code <= 132:
+			switch dt.Code % 10 {
+			case 0:
+				err = setAxis(dt, c.PlaneOrigin)
+			case 1:
+				err = setAxis(dt, c.PlaneXAxis)
+			case 2:
+				err = setAxis(dt, c.PlaneYAxis)
+			}
+		case dt.Code == 297:
+			err = setInt(dt, func(val int) { c.PlaneNormalReversed = val != 0 })
+		case dt.Code == 272:
+			err = setInt(dt, func(val int) { c.TopAttachment = val })
+		case dt.Code == 273:
+			err = setInt(dt, func(val int) { c.BottomAttachment = val })
+		case t != nil:
+			err = parseMLeaderText(dt, t, &columns)
+		}
+		if err == nil && b != nil {
+			err = parseMLeaderBlock(dt, b)
 		}
 		if err != nil {
-			return lw, err
+			return c, i, err
 		}
-		if read == 3 {
-			read = 0
-			ind++
+	}
+	return c, i, fmt.Errorf("CONTEXT_DATA is not closed")
+}
+
+// parseMLeaderText sets a value of MTEXT content in context data.
+// Code 142 is the background scale before column type (173), and the column width after it.
+func parseMLeaderText(dt Tag, t *entity.MLeaderText, columns *bool) error {
+	switch dt.Code {
+	case 304:
+		t.Value = dt.Value
+	case 11, 21, 31:
+		return setAxis(dt, t.Direction)
+	case 340:
+		return setHandle(dt, func(val handle.Handler) { t.Style = val })
+	case 12, 22, 32:
+		return setAxis(dt, t.Location)
+	case 13, 23, 33:
+		return setAxis(dt, t.XAxis)
+	case 42:
+		return setFloat(dt, func(val float64) { t.Rotation = val })
+	case 43:
+		return setFloat(dt, func(val float64) { t.Width = val })
+	case 44:
+		return setFloat(dt, func(val float64) { t.DefinedWidth = val })
+	case 45:
+		return setFloat(dt, func(val float64) { t.DefinedHeight = val })
+	case 170:
+		return setInt(dt, func(val int) { t.LineSpacingStyle = val })
+	case 141:
+		return setFloat(dt, func(val float64) { t.LineSpacingFactor = val })
+	case 171:
+		return setInt(dt, func(val int) { t.Alignment = val })
+	case 172:
+		return setInt(dt, func(val int) { t.FlowDirection = val })
+	case 90:
+		return setInt(dt, func(val int) { t.Color = val })
+	case 91:
+		return setInt(dt, func(val int) { t.BackgroundColor = val })
+	case 142:
+		if *columns {
+			return setFloat(dt, func(val float64) { t.ColumnWidth = val })
 		}
+		return setFloat(dt, func(val float64) { t.BackgroundScale = val })
+	case 92:
+		return setInt(dt, func(val int) { t.BackgroundTransp = val })
+	case 291:
+		return setInt(dt, func(val int) { t.BackgroundWindow = val != 0 })
+	case 292:
+		return setInt(dt, func(val int) { t.BackgroundFill = val != 0 })
+	case 173:
+		*columns = true
+		return setInt(dt, func(val int) { t.ColumnType = val })
+	case 293:
+		return setInt(dt, func(val int) { t.AutoHeight = val != 0 })
+	case 143:
+		return setFloat(dt, func(val float64) { t.ColumnGutter = val })
+	case 294:
+		return setInt(dt, func(val int) { t.ColumnFlowReversed = val != 0 })
+	case 144:
+		return setFloat(dt, func(val float64) { t.ColumnSizes = append(t.ColumnSizes, val) })
+	case 295:
+		return setInt(dt, func(val int) { t.WordBreak = val != 0 })
 	}
-	if ind != lw.Num {
-		return lw, fmt.Errorf("LWPOLYLINE not enough vertices")
+	return nil
+}
+
+// parseMLeaderBlock sets a value of block content in context data.
+func parseMLeaderBlock(dt Tag, b *entity.MLeaderBlock) error {
+	switch dt.Code {
+	case 341:
+		return setHandle(dt, func(val handle.Handler) { b.Block = val })
+	case 14, 24, 34:
+		return setAxis(dt, b.Direction)
+	case 15, 25, 35:
+		return setAxis(dt, b.Location)
+	case 16, 26, 36:
+		return setAxis(dt, b.Scale)
+	case 46:
+		return setFloat(dt, func(val float64) { b.Rotation = val })
+	case 93:
+		return setInt(dt, func(val int) { b.Color = val })
+	case 47:
+		return setFloat(dt, func(val float64) { b.Matrix = append(b.Matrix, val) })
 	}
-	return lw, nil
+	return nil
+}
+
+// parseMLeaderBranch parses a leader of MULTILEADER starting at data[i],
+// and returns it with the index of its closing tag (code 303).
+func parseMLeaderBranch(data []Tag, i int) (*entity.MLeaderBranch, int, error) {
+	b := &entity.MLeaderBranch{
+		LastPoint:    []float64{0.0, 0.0, 0.0},
+		DoglegVector: []float64{0.0, 0.0, 0.0},
+	}
+	var err error
+	for ; i < len(data); i++ {
+		dt := data[i]
+		switch dt.Code {
+		case 303:
+			return b, i, nil
+		case 304:
+			var l *entity.MLeaderLine
+			l, i, err = parseMLeaderLine(data, i+1)
+			b.Lines = append(b.Lines, l)
+		case 290:
+			err = setInt(dt, func(val int) { b.HasLastPoint = val != 0 })
+		case 291:
+			err = setInt(dt, func(val int) { b.HasDogleg = val != 0 })
+		case 10, 20, 30:
+			err = setAxis(dt, b.LastPoint)
+		case 11, 21, 31:
+			err = setAxis(dt, b.DoglegVector)
+		case 12:
+			b.Breaks = append(b.Breaks, entity.MLeaderBreak{Start: []float64{0.0, 0.0, 0.0}, End: []float64{0.0, 0.0, 0.0}})
+			fallthrough
+		case 22, 32, 13, 23, 33:
+			if len(b.Breaks) == 0 {
+				return b, i, newTagError(dt, errors.New("LEADER value before break"))
+			}
+			br := b.Breaks[len(b.Breaks)-1]
+			if dt.Code%10 == 2 {
+				err = setAxis(dt, br.Start)
+			} else {
+				err = setAxis(dt, br.End)
+			}
+		case 90:
+			err = setInt(dt, func(val int) { b.Index = val })
+		case 40:
+			err = setFloat(dt, func(val float64) { b.DoglegLength = val })
+		case 271:
+			err = setInt(dt, func(val int) { b.AttachmentDirection = val })
+		}
+		if err != nil {
+			return b, i, err
+		}
+	}
+	return b, i, fmt.Errorf("LEADER is not closed")
+}
+
+// parseMLeaderLine parses a leader line of MULTILEADER starting at data[i],
+// and returns it with the index of its closing tag (code 305).
+func parseMLeaderLine(data []Tag, i int) (*entity.MLeaderLine, int, error) {
+	l := &entity.MLeaderLine{Vertices: make([][]float64, 0)}
+	var err error
+	for ; i < len(data); i++ {
+		dt := data[i]
+		switch dt.Code {
+		case 305:
+			return l, i, nil
+		case 10:
+			l.Vertices = append(l.Vertices, []float64{0.0, 0.0, 0.0})
+			fallthrough
+		case 20, 30:
+			if len(l.Vertices) == 0 {
+				return l, i, newTagError(dt, errors.New("LEADER_LINE value before vertex"))
+			}
+			err = setAxis(dt, l.Vertices[len(l.Vertices)-1])
+		case 90:
+			err = setInt(dt, func(val int) {
+				l.Breaks = append(l.Breaks, entity.MLeaderBreak{Index: val, Start: []float64{0.0, 0.0, 0.0}, End: []float64{0.0, 0.0, 0.0}})
+			})
+		case 11, 21, 31, 12, 22, 32:
+			if len(l.Breaks) == 0 {
+				return l, i, newTagError(dt, errors.New("LEADER_LINE value before break"))
+			}
+			br := l.Breaks[len(l.Breaks)-1]
+			if dt.Code%10 == 1 {
+				err = setAxis(dt, br.Start)
+			} else {
+				err = setAxis(dt, br.End)
+			}
+		case 91:
+			err = setInt(dt, func(val int) { l.Index = val })
+		}
+		if err != nil {
+			return l, i, err
+		}
+	}
+	return l, i, fmt.Errorf("LEADER_LINE is not closed")
 }
 
 // ParseCircle parses CIRCLE entities.
-func ParseCircle(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
+func ParseCircle(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
 	c := entity.NewCircle()
 	var err error
 	for _, dt := range data {
-		switch dt[0] {
+		switch dt.Code {
 		default:
 			continue
-		case "8":
-			layer, err := d.Layer(dt[1], false)
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
 			if err == nil {
 				c.SetLayer(layer)
 			}
-		case "48":
+		case 48:
 			err = setFloat(dt, func(val float64) { c.SetLtscale(val) })
-		case "10":
+		case 10:
 			err = setFloat(dt, func(val float64) { c.Center[0] = val })
-		case "20":
+		case 20:
 			err = setFloat(dt, func(val float64) { c.Center[1] = val })
-		case "30":
+		case 30:
 			err = setFloat(dt, func(val float64) { c.Center[2] = val })
-		case "40":
+		case 40:
 			err = setFloat(dt, func(val float64) { c.Radius = val })
-		case "210":
+		case 210:
 			err = setFloat(dt, func(val float64) { c.Direction[0] = val })
-		case "220":
+		case 220:
 			err = setFloat(dt, func(val float64) { c.Direction[1] = val })
-		case "230":
+		case 230:
 			err = setFloat(dt, func(val float64) { c.Direction[2] = val })
 		}
 		if err != nil {
@@ -797,38 +2481,38 @@ func ParseCircle(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
 }
 
 // ParseArc parses ARC entities.
-func ParseArc(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
+func ParseArc(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
 	c := entity.NewCircle()
 	angle := make([]float64, 2)
 	var err error
 	for _, dt := range data {
-		switch dt[0] {
+		switch dt.Code {
 		default:
 			continue
-		case "8":
-			layer, err := d.Layer(dt[1], false)
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
 			if err == nil {
 				c.SetLayer(layer)
 			}
-		case "48":
+		case 48:
 			err = setFloat(dt, func(val float64) { c.SetLtscale(val) })
-		case "10":
+		case 10:
 			err = setFloat(dt, func(val float64) { c.Center[0] = val })
-		case "20":
+		case 20:
 			err = setFloat(dt, func(val float64) { c.Center[1] = val })
-		case "30":
+		case 30:
 			err = setFloat(dt, func(val float64) { c.Center[2] = val })
-		case "40":
+		case 40:
 			err = setFloat(dt, func(val float64) { c.Radius = val })
-		case "50":
+		case 50:
 			err = setFloat(dt, func(val float64) { angle[0] = val })
-		case "51":
+		case 51:
 			err = setFloat(dt, func(val float64) { angle[1] = val })
-		case "210":
+		case 210:
 			err = setFloat(dt, func(val float64) { c.Direction[0] = val })
-		case "220":
+		case 220:
 			err = setFloat(dt, func(val float64) { c.Direction[1] = val })
-		case "230":
+		case 230:
 			err = setFloat(dt, func(val float64) { c.Direction[2] = val })
 		}
 		if err != nil {
@@ -846,26 +2530,79 @@ func ParseArc(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
 	return a, nil
 }
 
+// ParseInsert parses INSERT entities.
+func ParseInsert(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
+	i := entity.NewInsert("")
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		default:
+			continue
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
+			if err == nil {
+				i.SetLayer(layer)
+			}
+		case 48:
+			err = setFloat(dt, func(val float64) { i.SetLtscale(val) })
+		case 2:
+			i.BlockName = dt.Value
+		case 10:
+			err = setFloat(dt, func(val float64) { i.Coord[0] = val })
+		case 20:
+			err = setFloat(dt, func(val float64) { i.Coord[1] = val })
+		case 30:
+			err = setFloat(dt, func(val float64) { i.Coord[2] = val })
+		case 41:
+			err = setFloat(dt, func(val float64) { i.Scale[0] = val })
+		case 42:
+			err = setFloat(dt, func(val float64) { i.Scale[1] = val })
+		case 43:
+			err = setFloat(dt, func(val float64) { i.Scale[2] = val })
+		case 50:
+			err = setFloat(dt, func(val float64) { i.Rotation = val })
+		case 70:
+			err = setInt(dt, func(val int) { i.Columns = val })
+		case 71:
+			err = setInt(dt, func(val int) { i.Rows = val })
+		case 44:
+			err = setFloat(dt, func(val float64) { i.ColumnSpacing = val })
+		case 45:
+			err = setFloat(dt, func(val float64) { i.RowSpacing = val })
+		case 210:
+			err = setFloat(dt, func(val float64) { i.Direction[0] = val })
+		case 220:
+			err = setFloat(dt, func(val float64) { i.Direction[1] = val })
+		case 230:
+			err = setFloat(dt, func(val float64) { i.Direction[2] = val })
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+	return i, nil
+}
+
 // ParsePoint parses POINT entities.
-func ParsePoint(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
+func ParsePoint(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
 	p := entity.NewPoint()
 	var err error
 	for _, dt := range data {
-		switch dt[0] {
+		switch dt.Code {
 		default:
 			continue
-		case "8":
-			layer, err := d.Layer(dt[1], false)
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
 			if err == nil {
 				p.SetLayer(layer)
 			}
-		case "48":
+		case 48:
 			err = setFloat(dt, func(val float64) { p.SetLtscale(val) })
-		case "10":
+		case 10:
 			err = setFloat(dt, func(val float64) { p.Coord[0] = val })
-		case "20":
+		case 20:
 			err = setFloat(dt, func(val float64) { p.Coord[1] = val })
-		case "30":
+		case 30:
 			err = setFloat(dt, func(val float64) { p.Coord[2] = val })
 		}
 		if err != nil {
@@ -876,47 +2613,57 @@ func ParsePoint(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
 }
 
 // ParseText parses TEXT entities.
-func ParseText(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
+func ParseText(d *drawing.Drawing, data []Tag) (entity.Entity, error) {
 	t := entity.NewText()
 	var err error
 	for _, dt := range data {
-		switch dt[0] {
+		switch dt.Code {
 		default:
 			continue
-		case "8":
-			layer, err := d.Layer(dt[1], false)
+		case 8:
+			layer, err := d.Layer(dt.Value, false)
 			if err == nil {
 				t.SetLayer(layer)
 			}
-		case "48":
+		case 48:
 			err = setFloat(dt, func(val float64) { t.SetLtscale(val) })
-		case "10":
+		case 10:
 			err = setFloat(dt, func(val float64) { t.Coord1[0] = val })
-		case "20":
+		case 20:
 			err = setFloat(dt, func(val float64) { t.Coord1[1] = val })
-		case "30":
+		case 30:
 			err = setFloat(dt, func(val float64) { t.Coord1[2] = val })
-		case "11":
+		case 11:
 			err = setFloat(dt, func(val float64) { t.Coord2[0] = val })
-		case "21":
+		case 21:
 			err = setFloat(dt, func(val float64) { t.Coord2[1] = val })
-		case "31":
+		case 31:
 			err = setFloat(dt, func(val float64) { t.Coord2[2] = val })
-		case "40":
+		case 40:
 			err = setFloat(dt, func(val float64) { t.Height = val })
-		case "50":
+		case 50:
 			err = setFloat(dt, func(val float64) { t.Rotation = val })
-		case "1":
-			t.Value = dt[1]
-		case "7":
-			if s, ok := d.Styles[dt[1]]; ok {
+		case 41:
+			err = setFloat(dt, func(val float64) { t.WidthFactor = val })
+		case 51:
+			err = setFloat(dt, func(val float64) { t.ObliqueAngle = val })
+		case 1:
+			t.Value = dt.Value
+		case 7:
+			if s, ok := d.Styles[dt.Value]; ok {
 				t.Style = s
 			}
-		case "71":
+		case 210:
+			err = setFloat(dt, func(val float64) { t.Direction[0] = val })
+		case 220:
+			err = setFloat(dt, func(val float64) { t.Direction[1] = val })
+		case 230:
+			err = setFloat(dt, func(val float64) { t.Direction[2] = val })
+		case 71:
 			err = setInt(dt, func(val int) { t.GenFlag = val })
-		case "72":
+		case 72:
 			err = setInt(dt, func(val int) { t.HorizontalFlag = val })
-		case "73":
+		case 73:
 			err = setInt(dt, func(val int) { t.VerticalFlag = val })
 		}
 		if err != nil {
@@ -929,6 +2676,435 @@ func ParseText(d *drawing.Drawing, data [][2]string) (entity.Entity, error) {
 // OBJECTS
 
 // ParseObjects parses OBJECTS section.
-func ParseObjects(d *drawing.Drawing, line int, data [][2]string) error {
+// The root dictionary of the file is merged into the root dictionary of the drawing,
+// and dictionaries managed by the drawing (ACAD_GROUP and ACAD_PLOTSTYLENAME)
+// are replaced with the drawing's own ones together with the objects they own.
+// Groups in ACAD_GROUP of the file are added to the drawing with their names,
+// groups owned by other dictionaries are kept in them,
+// and groups not listed by any dictionary are added to the drawing as anonymous groups (*An).
+func ParseObjects(d *drawing.Drawing, r *TagReader) error {
+	type record struct {
+		object object.Object
+		handle int
+		owner  int
+	}
+	records := make([]record, 0)
+	var root *object.Dictionary
+	for {
+		if end, err := endOfSection(r); end {
+			if err != nil {
+				return err
+			}
+			break
+		}
+		data, err := r.Record()
+		if err != nil {
+			return err
+		}
+		o, err := ParseObject(d, data)
+		if err != nil {
+			if err := r.skip("OBJECTS", data, err); err != nil {
+				return err
+			}
+			continue
+		}
+		h := recordHandle(data)
+		if dict, ok := o.(*object.Dictionary); ok && root == nil {
+			root = dict
+			d.RegisterHandle(h, d.RootDictionary())
+			continue
+		}
+		records = append(records, record{object: o, handle: h, owner: recordOwner(data)})
+	}
+	type entry struct {
+		key    string
+		handle int
+	}
+	entries := make([]entry, 0)
+	dropped := make(map[int]bool)
+	groupdict := 0
+	if root != nil {
+		for _, key := range root.Keys() {
+			ref, _ := root.Item(key)
+			if item, err := d.RootDictionary().Item(key); err == nil {
+				d.RegisterHandle(ref.Handle(), item)
+				dropped[ref.Handle()] = true
+				if key == "ACAD_GROUP" {
+					groupdict = ref.Handle()
+				}
+			} else {
+				entries = append(entries, entry{key, ref.Handle()})
+			}
+		}
+	}
+	for changed := true; changed; { // objects owned by dropped ones
+		changed = false
+		for _, rc := range records {
+			if !dropped[rc.handle] && dropped[rc.owner] {
+				dropped[rc.handle] = true
+				changed = true
+			}
+		}
+	}
+	groups := make(map[int]*object.Group)
+	dicts := make(map[int]*object.Dictionary)
+	for _, rc := range records {
+		switch o := rc.object.(type) {
+		case *object.Group:
+			groups[rc.handle] = o
+		case *object.Dictionary:
+			dicts[rc.handle] = o
+		}
+	}
+	added := make(map[int]bool)
+	if gd, exist := dicts[groupdict]; exist {
+		for _, name := range gd.Keys() {
+			ref, _ := gd.Item(name)
+			g, exist := groups[ref.Handle()]
+			if !exist || added[ref.Handle()] {
+				continue
+			}
+			g.Name = name
+			if err := d.AddGroup(g); err == nil {
+				d.RegisterHandle(ref.Handle(), g)
+				added[ref.Handle()] = true
+			}
+		}
+	}
+	for _, rc := range records {
+		g, ok := rc.object.(*object.Group)
+		if !ok || added[rc.handle] {
+			continue
+		}
+		if owner, exist := dicts[rc.owner]; exist && rc.owner != groupdict && !dropped[rc.owner] {
+			if key, exist := dictionaryKey(owner, rc.handle); exist {
+				// owned by another dictionary, which is kept as is
+				g.Name = key
+				g.SetOwner(owner)
+				continue
+			}
+		}
+		// not listed by any dictionary, so listed in ACAD_GROUP of the drawing as anonymous
+		g.Name = anonymousGroupName(d)
+		if err := d.AddGroup(g); err == nil {
+			d.RegisterHandle(rc.handle, g)
+			added[rc.handle] = true
+		}
+	}
+	for _, rc := range records {
+		if added[rc.handle] || dropped[rc.handle] {
+			continue
+		}
+		d.AddObject(rc.object)
+		d.RegisterHandle(rc.handle, rc.object)
+	}
+	for _, e := range entries {
+		if item, err := d.SourceHandle(e.handle); err == nil {
+			d.RootDictionary().AddItem(e.key, item)
+		}
+	}
+	return nil
+}
+
+// dictionaryKey returns the key of dictionary o for the item of handle h.
+func dictionaryKey(o *object.Dictionary, h int) (string, bool) {
+	for _, key := range o.Keys() {
+		if item, err := o.Item(key); err == nil && item.Handle() == h {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// anonymousGroupName returns a name of anonymous group (*An) not used in the drawing.
+func anonymousGroupName(d *drawing.Drawing) string {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("*A%d", n)
+		if _, exist := d.Groups[name]; !exist {
+			return name
+		}
+	}
+}
+
+// ParseObject parses each object.
+func ParseObject(d *drawing.Drawing, data []Tag) (object.Object, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("no data")
+	}
+	if data[0].Code != 0 {
+		return nil, fmt.Errorf("ParseObject invalid group code: %d", data[0].Code)
+	}
+	f, err := ParseObjectFunc(data[0].Value)
+	if err != nil {
+		return nil, err
+	}
+	return f(d, data)
+}
+
+// ParseObjectFunc returns a function for parsing acoording to object type string.
+func ParseObjectFunc(t string) (func(*drawing.Drawing, []Tag) (object.Object, error), error) {
+	switch t {
+	case "DICTIONARY":
+		return ParseDictionary, nil
+	case "GROUP":
+		return ParseGroup, nil
+	case "LAYOUT":
+		return ParseLayout, nil
+	case "PLOTSETTINGS":
+		return ParsePlotSettings, nil
+	case "XRECORD":
+		return ParseXRecord, nil
+	case "IMAGEDEF":
+		return ParseImageDef, nil
+	default:
+		return ParseUnknownObject, nil
+	}
+}
+
+// ParseUnknownObject parses objects not supported by this package,
+// keeping their tags to write them back.
+func ParseUnknownObject(d *drawing.Drawing, data []Tag) (object.Object, error) {
+	return object.NewUnknown(data[0].Value, rawTags(data)), nil
+}
+
+// setOwner sets the owner of record as a reference to be resolved after reading.
+func setOwner(o interface{ SetOwner(handle.Handler) }, data []Tag) {
+	if h := recordOwner(data); h != 0 {
+		o.SetOwner(handle.Ref(h))
+	}
+}
+
+// ParseDictionary parses DICTIONARY objects.
+func ParseDictionary(d *drawing.Drawing, data []Tag) (object.Object, error) {
+	o := object.NewDictionary()
+	setOwner(o, data)
+	var key string
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		case 3:
+			key = dt.Value
+		case 280:
+			err = setInt(dt, func(val int) { o.HardOwner = val != 0 })
+		case 281:
+			err = setInt(dt, func(val int) { o.Cloning = val })
+		case 350, 360:
+			err = setHandle(dt, func(h handle.Handler) { o.SetItem(key, h) })
+		}
+		if err != nil {
+			return o, err
+		}
+	}
+	return o, nil
+}
+
+// ParseGroup parses GROUP objects.
+// The name of group is given by ACAD_GROUP dictionary.
+// Entities in the group are resolved from those already read.
+func ParseGroup(d *drawing.Drawing, data []Tag) (object.Object, error) {
+	g := object.NewGroup("", "")
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		case 300:
+			g.Description = dt.Value
+		case 70:
+			err = setInt(dt, func(val int) { g.Unnamed = val != 0 })
+		case 71:
+			err = setInt(dt, func(val int) { g.SetSelectable(val != 0) })
+		case 340:
+			err = setHandle(dt, func(h handle.Handler) {
+				if e, ok := resolve(d, h).(entity.Entity); ok {
+					g.AddEntity(e)
+				}
+			})
+		}
+		if err != nil {
+			return g, err
+		}
+	}
+	return g, nil
+}
+
+// parsePlotSettings parses a tag of AcDbPlotSettings subclass.
+func parsePlotSettings(p *object.PlotSettings, dt Tag) error {
+	switch dt.Code {
+	case 1:
+		p.PageSetupName = dt.Value
+	case 2:
+		p.PlotConfig = dt.Value
+	case 4:
+		p.PaperName = dt.Value
+	case 6:
+		p.PlotView = dt.Value
+	case 7:
+		p.StyleSheet = dt.Value
+	case 40, 41, 42, 43:
+		return setFloat(dt, func(val float64) { p.Margins[dt.Code-40] = val })
+	case 44, 45:
+		return setFloat(dt, func(val float64) { p.PaperSize[dt.Code-44] = val })
+	case 46, 47:
+		return setFloat(dt, func(val float64) { p.PlotOrigin[dt.Code-46] = val })
+	case 48, 49:
+		return setFloat(dt, func(val float64) { p.WindowMin[dt.Code-48] = val })
+	case 140, 141:
+		return setFloat(dt, func(val float64) { p.WindowMax[dt.Code-140] = val })
+	case 142:
+		return setFloat(dt, func(val float64) { p.Numerator = val })
+	case 143:
+		return setFloat(dt, func(val float64) { p.Denominator = val })
+	case 147:
+		return setFloat(dt, func(val float64) { p.ScaleFactor = val })
+	case 148, 149:
+		return setFloat(dt, func(val float64) { p.PaperImageOrigin[dt.Code-148] = val })
+	case 70:
+		return setInt(dt, func(val int) { p.Flag = val })
+	case 72:
+		return setInt(dt, func(val int) { p.PaperUnits = val })
+	case 73:
+		return setInt(dt, func(val int) { p.Rotation = val })
+	case 74:
+		return setInt(dt, func(val int) { p.PlotType = val })
+	case 75:
+		return setInt(dt, func(val int) { p.StandardScale = val })
+	case 76:
+		return setInt(dt, func(val int) { p.ShadePlotMode = val })
+	case 77:
+		return setInt(dt, func(val int) { p.ShadePlotResolution = val })
+	case 78:
+		return setInt(dt, func(val int) { p.ShadePlotDPI = val })
+	case 333:
+		return setHandle(dt, func(h handle.Handler) { p.ShadePlot = h })
+	}
 	return nil
 }
+
+// ParsePlotSettings parses PLOTSETTINGS objects.
+func ParsePlotSettings(d *drawing.Drawing, data []Tag) (object.Object, error) {
+	p := object.NewPlotSettings("")
+	setOwner(p, data)
+	subclass := ""
+	for _, dt := range data {
+		if dt.Code == 100 {
+			subclass = dt.Value
+			continue
+		}
+		if subclass != "AcDbPlotSettings" {
+			continue
+		}
+		if err := parsePlotSettings(p, dt); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// ParseLayout parses LAYOUT objects.
+func ParseLayout(d *drawing.Drawing, data []Tag) (object.Object, error) {
+	l := object.NewLayout("", nil)
+	setOwner(l, data)
+	subclass := ""
+	var err error
+	for _, dt := range data {
+		if dt.Code == 100 {
+			subclass = dt.Value
+			continue
+		}
+		switch subclass {
+		case "AcDbPlotSettings":
+			err = parsePlotSettings(&l.PlotSettings, dt)
+		case "AcDbLayout":
+			switch dt.Code {
+			case 1:
+				l.Name = dt.Value
+			case 70:
+				err = setInt(dt, func(val int) { l.LayoutFlag = val })
+			case 71:
+				err = setInt(dt, func(val int) { l.TabOrder = val })
+			case 76:
+				err = setInt(dt, func(val int) { l.UcsType = val })
+			case 146:
+				err = setFloat(dt, func(val float64) { l.Elevation = val })
+			case 10, 20:
+				err = setAxis(dt, l.LimMin)
+			case 11, 21:
+				err = setAxis(dt, l.LimMax)
+			case 12, 22, 32:
+				err = setAxis(dt, l.InsBase)
+			case 13, 23, 33:
+				err = setAxis(dt, l.UcsOrigin)
+			case 14, 24, 34:
+				err = setAxis(dt, l.ExtMin)
+			case 15, 25, 35:
+				err = setAxis(dt, l.ExtMax)
+			case 16, 26, 36:
+				err = setAxis(dt, l.UcsXAxis)
+			case 17, 27, 37:
+				err = setAxis(dt, l.UcsYAxis)
+			case 330:
+				err = setHandle(dt, func(h handle.Handler) { l.BlockRecord = h })
+			case 331:
+				err = setHandle(dt, func(h handle.Handler) { l.Viewport = h })
+			case 345:
+				err = setHandle(dt, func(h handle.Handler) { l.NamedUcs = h })
+			case 346:
+				err = setHandle(dt, func(h handle.Handler) { l.BaseUcs = h })
+			}
+		}
+		if err != nil {
+			return l, err
+		}
+	}
+	return l, nil
+}
+
+// ParseXRecord parses XRECORD objects.
+// Tags after the cloning flag (code 280) are kept as data.
+func ParseXRecord(d *drawing.Drawing, data []Tag) (object.Object, error) {
+	x := object.NewXRecord()
+	setOwner(x, data)
+	for i, dt := range data {
+		if !dt.Is(100, "AcDbXrecord") {
+			continue
+		}
+		rest := data[i+1:]
+		if len(rest) > 0 && rest[0].Code == 280 {
+			if err := setInt(rest[0], func(val int) { x.Cloning = val }); err != nil {
+				return x, err
+			}
+			rest = rest[1:]
+		}
+		for _, t := range rest {
+			x.Tags = append(x.Tags, rawTag(t))
+		}
+		break
+	}
+	return x, nil
+}
+
+// ParseImageDef parses IMAGEDEF objects.
+func ParseImageDef(d *drawing.Drawing, data []Tag) (object.Object, error) {
+	i := object.NewImageDef("", 0.0, 0.0)
+	setOwner(i, data)
+	var err error
+	for _, dt := range data {
+		switch dt.Code {
+		case 1:
+			i.FileName = dt.Value
+		case 90:
+			err = setInt(dt, func(val int) { i.Version = val })
+		case 10, 20:
+			err = setAxis(dt, i.Size)
+		case 11, 21:
+			err = setAxis(dt, i.PixelSize)
+		case 280:
+			err = setInt(dt, func(val int) { i.Loaded = val != 0 })
+		case 281:
+			err = setInt(dt, func(val int) { i.Units = val })
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+	return i, nil
+}
@@ -0,0 +1,233 @@
+package dxf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/flywave/go-dxf/format"
+)
+
+// tagScanner reads group code/value pairs one by one.
+type tagScanner interface {
+	Scan() bool
+	Code() int
+	Value() string
+	Line() int
+	Err() error
+}
+
+// newTagScanner returns a binary scanner if r starts with the binary DXF sentinel,
+// otherwise returns an ASCII scanner.
+func newTagScanner(r io.Reader) tagScanner {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(format.BinarySentinel))
+	if string(head) == format.BinarySentinel {
+		br.Discard(len(head))
+		return newBinaryScanner(br)
+	}
+	return newASCIIScanner(br)
+}
+
+// asciiScanner reads group code/value pairs from ASCII DXF.
+type asciiScanner struct {
+	scanner *bufio.Scanner
+	code    int
+	value   string
+	line    int
+	err     error
+}
+
+func newASCIIScanner(r io.Reader) *asciiScanner {
+	return &asciiScanner{scanner: bufio.NewScanner(r)}
+}
+
+// Scan advances to the next pair.
+func (s *asciiScanner) Scan() bool {
+	if s.err != nil || !s.scanner.Scan() {
+		return false
+	}
+	s.line++
+	code, err := strconv.Atoi(strings.TrimSpace(s.scanner.Text()))
+	if err != nil {
+		s.err = &ParseError{Code: -1, Value: s.scanner.Text(), Line: s.line, Err: ErrGroupCode}
+		return false
+	}
+	s.code = code
+	if !s.scanner.Scan() {
+		if s.scanner.Err() == nil {
+			s.err = &ParseError{Code: code, Line: s.line, Err: io.ErrUnexpectedEOF}
+		}
+		return false
+	}
+	s.line++
+	s.value = s.scanner.Text()
+	return true
+}
+
+// Code returns the current group code.
+func (s *asciiScanner) Code() int {
+	return s.code
+}
+
+// Value returns the current value.
+func (s *asciiScanner) Value() string {
+	return s.value
+}
+
+// Line returns the line number of the current group code.
+func (s *asciiScanner) Line() int {
+	return s.line - 1
+}
+
+// Err returns the first error occurred while scanning.
+func (s *asciiScanner) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.scanner.Err()
+}
+
+// binaryScanner reads group code/value pairs from binary DXF.
+// Values are converted to the same string representation as ASCII DXF.
+type binaryScanner struct {
+	reader *bufio.Reader
+	wide   bool // R13 or later uses 2 byte group codes
+	code   int
+	value  string
+	line   int
+	err    error
+}
+
+func newBinaryScanner(r *bufio.Reader) *binaryScanner {
+	s := &binaryScanner{reader: r, wide: true}
+	// R12 binary DXF uses 1 byte group codes, so the first tag (0 SECTION)
+	// begins with a single 0 followed by 'S'.
+	head, err := r.Peek(2)
+	if err == nil && head[0] == 0 && head[1] != 0 {
+		s.wide = false
+	}
+	return s
+}
+
+// Scan advances to the next pair.
+func (s *binaryScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	code, err := s.readCode()
+	if err != nil {
+		if err != io.EOF {
+			s.err = &ParseError{Code: -1, Line: s.line + 1, Err: err}
+		}
+		return false
+	}
+	value, err := s.readValue(code)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		s.err = &ParseError{Code: code, Line: s.line + 1, Err: err}
+		return false
+	}
+	s.line += 2
+	s.code = code
+	s.value = value
+	return true
+}
+
+// readCode reads a group code.
+func (s *binaryScanner) readCode() (int, error) {
+	if !s.wide {
+		b, err := s.reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != 255 {
+			return int(b), nil
+		}
+	}
+	var v int16
+	if err := binary.Read(s.reader, binary.LittleEndian, &v); err != nil {
+		return 0, err
+	}
+	return int(v), nil
+}
+
+// readValue reads a value according to the group code type.
+func (s *binaryScanner) readValue(code int) (string, error) {
+	switch format.CodeType(code) {
+	case format.FLOAT:
+		var v uint64
+		if err := binary.Read(s.reader, binary.LittleEndian, &v); err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(math.Float64frombits(v), 'g', -1, 64), nil
+	case format.BOOL:
+		b, err := s.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(b)), nil
+	case format.INT16:
+		var v int16
+		if err := binary.Read(s.reader, binary.LittleEndian, &v); err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(v)), nil
+	case format.INT32:
+		var v int32
+		if err := binary.Read(s.reader, binary.LittleEndian, &v); err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(v)), nil
+	case format.INT64:
+		var v int64
+		if err := binary.Read(s.reader, binary.LittleEndian, &v); err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(v, 10), nil
+	case format.BINARY:
+		size, err := s.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		data := make([]byte, int(size))
+		if _, err := io.ReadFull(s.reader, data); err != nil {
+			return "", err
+		}
+		return strings.ToUpper(hex.EncodeToString(data)), nil
+	default:
+		str, err := s.reader.ReadBytes(0)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes.TrimSuffix(str, []byte{0})), nil
+	}
+}
+
+// Code returns the current group code.
+func (s *binaryScanner) Code() int {
+	return s.code
+}
+
+// Value returns the current value.
+func (s *binaryScanner) Value() string {
+	return s.value
+}
+
+// Line returns the line number of the current group code
+// as if the file were written in ASCII format.
+func (s *binaryScanner) Line() int {
+	return s.line - 1
+}
+
+// Err returns the first error occurred while scanning.
+func (s *binaryScanner) Err() error {
+	return s.err
+}
@@ -3,13 +3,15 @@ package table
 import (
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // AppID represents APPID SymbolTable.
 type AppID struct {
-	handle int
-	owner  handle.Handler
-	name   string
+	handle      int
+	owner       handle.Handler
+	name        string
+	xdata.XData // 1001-1071
 }
 
 // NewAppID create a new AppID.
@@ -35,6 +37,7 @@ func (a *AppID) Format(f format.Formatter) {
 	f.WriteString(100, "AcDbRegAppTableRecord")
 	f.WriteString(2, a.name)
 	f.WriteInt(70, 0)
+	a.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -3,13 +3,15 @@ package table
 import (
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // BlockRecord represents BLOCK_RECORD SymbolTable.
 type BlockRecord struct {
-	handle int
-	owner  handle.Handler
-	name   string
+	handle      int
+	owner       handle.Handler
+	name        string
+	xdata.XData // 1001-1071
 }
 
 // NewBlockRecord creates a new BlockRecord.
@@ -37,6 +39,7 @@ func (b *BlockRecord) Format(f format.Formatter) {
 	f.WriteInt(70, 0)
 	f.WriteInt(280, 1)
 	f.WriteInt(281, 0)
+	b.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -3,19 +3,37 @@ package table
 import (
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // DimStyle represents DIMSTYLE SymbolTable.
+// Only the variables used for generating dimension geometry are kept.
 type DimStyle struct {
-	handle int
-	owner  handle.Handler
-	name   string
+	handle          int
+	owner           handle.Handler
+	name            string  // 2
+	Scale           float64 // 40: DIMSCALE
+	ArrowSize       float64 // 41: DIMASZ
+	ExtensionOffset float64 // 42: DIMEXO
+	ExtensionExtend float64 // 44: DIMEXE
+	TextHeight      float64 // 140: DIMTXT
+	TextGap         float64 // 147: DIMGAP
+	Decimals        int     // 271: DIMDEC
+	xdata.XData             // 1001-1071
 }
 
-// NewDimStyle creates a new DimStyle.
+// NewDimStyle creates a new DimStyle with AutoCAD default values.
 func NewDimStyle(name string) *DimStyle {
-	d := new(DimStyle)
-	d.name = name
+	d := &DimStyle{
+		name:            name,
+		Scale:           1.0,
+		ArrowSize:       0.18,
+		ExtensionOffset: 0.0625,
+		ExtensionExtend: 0.18,
+		TextHeight:      0.18,
+		TextGap:         0.09,
+		Decimals:        4,
+	}
 	return d
 }
 
@@ -35,6 +53,14 @@ func (d *DimStyle) Format(f format.Formatter) {
 	f.WriteString(100, "AcDbDimStyleTableRecord")
 	f.WriteString(2, d.name)
 	f.WriteInt(70, 0)
+	f.WriteFloat(40, d.Scale)
+	f.WriteFloat(41, d.ArrowSize)
+	f.WriteFloat(42, d.ExtensionOffset)
+	f.WriteFloat(44, d.ExtensionExtend)
+	f.WriteFloat(140, d.TextHeight)
+	f.WriteFloat(147, d.TextGap)
+	f.WriteInt(271, d.Decimals)
+	d.FormatXData(f)
 }
 
 // String outputs data using default formatter.
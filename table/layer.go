@@ -4,6 +4,7 @@ import (
 	"github.com/flywave/go-dxf/color"
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // Default layers.
@@ -13,14 +14,15 @@ var (
 
 // Layer represents LAYER SymbolTable.
 type Layer struct {
-	handle    int
-	owner     handle.Handler
-	name      string
-	flag      int
-	Color     color.ColorNumber
-	LineType  *LineType
-	lineWidth int
-	PlotStyle handle.Handler
+	handle      int
+	owner       handle.Handler
+	name        string
+	flag        int
+	Color       color.ColorNumber
+	LineType    *LineType
+	lineWidth   int
+	PlotStyle   handle.Handler
+	xdata.XData // 1001-1071
 }
 
 // NewLayer creates a new Layer.
@@ -53,6 +55,7 @@ func (l *Layer) Format(f format.Formatter) {
 	f.WriteString(6, l.LineType.Name())
 	f.WriteInt(370, l.lineWidth)
 	f.WriteHex(390, l.PlotStyle.Handle())
+	l.FormatXData(f)
 }
 
 // String outputs data using default formatter.
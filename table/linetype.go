@@ -5,6 +5,7 @@ import (
 
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // Default LineTypes.
@@ -23,6 +24,7 @@ type LineType struct {
 	name        string // 2
 	Description string // 3
 	lengths     []float64
+	xdata.XData // 1001-1071
 }
 
 // NewLineType creates a new LineType.
@@ -62,6 +64,7 @@ func (lt *LineType) Format(f format.Formatter) {
 		f.WriteFloat(49, l)
 		f.WriteInt(74, 0)
 	}
+	lt.FormatXData(f)
 }
 
 // String outputs data using default formatter.
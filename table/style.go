@@ -3,6 +3,7 @@ package table
 import (
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // Default Styles.
@@ -21,6 +22,7 @@ type Style struct {
 	WidthFactor     float64 // 41
 	LastHeightUsed  float64 // 42
 	ObliqueAngle    float64 // 50
+	xdata.XData             // 1001-1071
 }
 
 // NewStyle create a new Style.
@@ -58,6 +60,7 @@ func (st *Style) Format(f format.Formatter) {
 	f.WriteFloat(42, st.LastHeightUsed)
 	f.WriteString(3, st.FontName)
 	f.WriteString(4, st.BigFontName)
+	st.FormatXData(f)
 }
 
 // String outputs data using default formatter.
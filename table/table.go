@@ -81,6 +81,13 @@ func (t *Table) Clear() {
 	t.size = 0
 }
 
+// Records returns SymbolTables of TABLE.
+func (t *Table) Records() []SymbolTable {
+	sts := make([]SymbolTable, t.size)
+	copy(sts, t.tables)
+	return sts
+}
+
 // Contains reports if TABLE has the named SymbolTable.
 func (t *Table) Contains(name string) (SymbolTable, error) {
 	for _, st := range t.tables {
@@ -3,13 +3,15 @@ package table
 import (
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // UCS represents UCS SymbolTable.
 type Ucs struct {
-	handle int
-	owner  handle.Handler
-	name   string // 2
+	handle      int
+	owner       handle.Handler
+	name        string // 2
+	xdata.XData        // 1001-1071
 }
 
 // NewUCS creates a new Ucs.
@@ -34,6 +36,7 @@ func (u *Ucs) Format(f format.Formatter) {
 	f.WriteString(100, "AcDbSymbolTableRecord")
 	f.WriteString(100, "AcDbUCSTableRecord")
 	f.WriteString(2, u.name)
+	u.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -3,13 +3,15 @@ package table
 import (
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // View represents VIEW SymbolTable.
 type View struct {
-	handle int
-	owner  handle.Handler
-	name   string // 2
+	handle      int
+	owner       handle.Handler
+	name        string // 2
+	xdata.XData        // 1001-1071
 }
 
 // NewView creates a new View.
@@ -34,6 +36,7 @@ func (v *View) Format(f format.Formatter) {
 	f.WriteString(100, "AcDbSymbolTableRecord")
 	f.WriteString(100, "AcDbViewTableRecord")
 	f.WriteString(2, v.name)
+	v.FormatXData(f)
 }
 
 // String outputs data using default formatter.
@@ -3,6 +3,7 @@ package table
 import (
 	"github.com/flywave/go-dxf/format"
 	"github.com/flywave/go-dxf/handle"
+	"github.com/flywave/go-dxf/xdata"
 )
 
 // Viewport represents VPORT SymbolTable.
@@ -25,6 +26,7 @@ type Viewport struct {
 	BackClip      float64
 	SnapAngle     float64
 	TwistAngle    float64
+	xdata.XData   // 1001-1071
 }
 
 // NewViewport creates a new Viewport.
@@ -97,6 +99,7 @@ func (v *Viewport) Format(f format.Formatter) {
 	f.WriteFloat(44, v.BackClip)
 	f.WriteFloat(50, v.SnapAngle)
 	f.WriteFloat(51, v.TwistAngle)
+	v.FormatXData(f)
 }
 
 // String outputs data using default formatter.
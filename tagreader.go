@@ -0,0 +1,226 @@
+package dxf
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/flywave/go-dxf/format"
+)
+
+// Tag represents a group code/value pair.
+type Tag struct {
+	Code  int    // group code
+	Value string // raw value as written in ASCII DXF
+	Line  int    // 1-based line number of the group code
+}
+
+// String returns the raw value.
+func (t Tag) String() string {
+	return t.Value
+}
+
+// Float returns the value as a floating point number.
+func (t Tag) Float() (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(t.Value), 64)
+}
+
+// Int returns the value as an integer.
+func (t Tag) Int() (int, error) {
+	val, err := strconv.ParseInt(strings.TrimSpace(t.Value), 10, 64)
+	return int(val), err
+}
+
+// Bool returns the value as a boolean flag.
+func (t Tag) Bool() (bool, error) {
+	val, err := t.Int()
+	return val != 0, err
+}
+
+// Handle returns the value as a handle.
+func (t Tag) Handle() (int, error) {
+	val, err := strconv.ParseUint(strings.TrimSpace(t.Value), 16, 64)
+	return int(val), err
+}
+
+// Bytes returns the value as a binary chunk.
+func (t Tag) Bytes() ([]byte, error) {
+	return hex.DecodeString(strings.TrimSpace(t.Value))
+}
+
+// Typed returns the value converted according to the group code:
+// float64, int, int64, bool, []byte, or string (also for handles).
+func (t Tag) Typed() (interface{}, error) {
+	switch format.CodeType(t.Code) {
+	case format.FLOAT:
+		return t.Float()
+	case format.INT16, format.INT32:
+		return t.Int()
+	case format.INT64:
+		return strconv.ParseInt(strings.TrimSpace(t.Value), 10, 64)
+	case format.BOOL:
+		return t.Bool()
+	case format.BINARY:
+		return t.Bytes()
+	default:
+		return t.Value, nil
+	}
+}
+
+// Is reports whether the tag has given code and value.
+// Value is compared case-insensitively.
+func (t Tag) Is(code int, value string) bool {
+	return t.Code == code && strings.EqualFold(strings.TrimSpace(t.Value), value)
+}
+
+// TagReader reads group code/value pairs from ASCII or binary DXF one by one,
+// so that a drawing can be processed in constant memory.
+// Values are converted into UTF-8 according to $ACADVER and $DWGCODEPAGE,
+// and \U+XXXX and \M+nXXXX sequences are translated into characters.
+type TagReader struct {
+	scanner  tagScanner
+	peeked   *Tag
+	binary   bool
+	decoder  *textDecoder
+	section  string // current section
+	started  bool   // the last tag is "0\nSECTION"
+	variable string // current header variable
+	mode     ParseMode
+	warnings []Warning
+}
+
+// NewTagReader creates a new TagReader.
+// Binary DXF is detected by its sentinel.
+func NewTagReader(r io.Reader) *TagReader {
+	s := newTagScanner(r)
+	_, binary := s.(*binaryScanner)
+	return &TagReader{
+		scanner: s,
+		binary:  binary,
+		decoder: newTextDecoder(),
+	}
+}
+
+// SetCodePage sets the code page (e.g. "ANSI_936") to decode values,
+// overriding $DWGCODEPAGE and $ACADVER of the file.
+// It is for files whose $DWGCODEPAGE is wrong.
+func (r *TagReader) SetCodePage(cp string) error {
+	if _, err := format.CodePage(cp); err != nil {
+		return err
+	}
+	r.decoder.codepage = cp
+	r.decoder.fixed = true
+	r.decoder.update()
+	return nil
+}
+
+// Binary reports whether the underlying data is binary DXF.
+func (r *TagReader) Binary() bool {
+	return r.binary
+}
+
+// Next returns the next tag.
+// It returns io.EOF when there are no more tags.
+func (r *TagReader) Next() (Tag, error) {
+	if r.peeked != nil {
+		t := *r.peeked
+		r.peeked = nil
+		return t, nil
+	}
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			if pe, ok := err.(*ParseError); ok && pe.Section == "" {
+				pe.Section = r.section
+			}
+			return Tag{}, err
+		}
+		return Tag{}, io.EOF
+	}
+	t := Tag{
+		Code:  r.scanner.Code(),
+		Value: r.decoder.decode(r.scanner.Code(), r.scanner.Value()),
+		Line:  r.scanner.Line(),
+	}
+	r.track(t)
+	return t, nil
+}
+
+// track follows the current section for errors,
+// and $ACADVER and $DWGCODEPAGE to decode the rest of values.
+func (r *TagReader) track(t Tag) {
+	started := r.started
+	r.started = false
+	switch {
+	case t.Code == 0:
+		r.variable = ""
+		r.started = t.Is(0, "SECTION")
+		if t.Is(0, "ENDSEC") {
+			r.section = ""
+		}
+	case t.Code == 2 && started:
+		r.section = strings.ToUpper(strings.TrimSpace(t.Value))
+	case t.Code == 9:
+		r.variable = strings.TrimSpace(t.Value)
+	case t.Code == 1 && r.variable == "$ACADVER":
+		r.decoder.version = t.Value
+		r.decoder.update()
+	case t.Code == 3 && r.variable == "$DWGCODEPAGE" && !r.decoder.fixed:
+		r.decoder.codepage = t.Value
+		r.decoder.update()
+	}
+}
+
+// Peek returns the next tag without advancing the reader.
+func (r *TagReader) Peek() (Tag, error) {
+	if r.peeked != nil {
+		return *r.peeked, nil
+	}
+	t, err := r.Next()
+	if err != nil {
+		return t, err
+	}
+	r.peeked = &t
+	return t, nil
+}
+
+// Record returns the next record, which is the next tag followed by
+// every tag up to (not including) the next 0 code.
+func (r *TagReader) Record() ([]Tag, error) {
+	t, err := r.Next()
+	if err != nil {
+		return nil, err
+	}
+	data := []Tag{t}
+	for {
+		t, err := r.Peek()
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			return data, err
+		}
+		if t.Code == 0 {
+			return data, nil
+		}
+		r.peeked = nil
+		data = append(data, t)
+	}
+}
+
+// Expect returns the next tag and reports an error
+// if it doesn't have given group code.
+func (r *TagReader) Expect(code int) (Tag, error) {
+	t, err := r.Next()
+	if err == io.EOF {
+		return t, &ParseError{Section: r.section, Code: code, Line: t.Line, Err: io.ErrUnexpectedEOF}
+	}
+	if err != nil {
+		return t, err
+	}
+	if t.Code != code {
+		return t, &ParseError{Section: r.section, Code: t.Code, Value: t.Value, Line: t.Line, Err: fmt.Errorf("expected group code %d", code)}
+	}
+	return t, nil
+}
@@ -0,0 +1,73 @@
+package dxf
+
+import (
+	"fmt"
+)
+
+// ParseMode controls how malformed data are handled on reading.
+type ParseMode int
+
+// Parse modes
+const (
+	Strict  ParseMode = iota // the first error stops reading
+	Lenient                  // malformed records are skipped and reported as warnings
+)
+
+// ParseOptions are options for reading a drawing.
+type ParseOptions struct {
+	Mode     ParseMode
+	CodePage string // overrides $DWGCODEPAGE if not empty (e.g. "ANSI_936")
+}
+
+// Warning represents a record skipped in Lenient mode.
+type Warning struct {
+	Section string // e.g. "ENTITIES"
+	Type    string // entity, table record or object type, or header variable name
+	Handle  int    // handle in the file, 0 if the record has no handle
+	Line    int    // 1-based line number of the offending group code
+	Message string
+}
+
+// String returns a description of the warning.
+func (w Warning) String() string {
+	s := fmt.Sprintf("line %d: %s", w.Line, w.Section)
+	if w.Type != "" {
+		s += " " + w.Type
+	}
+	if w.Handle != 0 {
+		s += fmt.Sprintf(" (handle %X)", w.Handle)
+	}
+	return s + ": " + w.Message
+}
+
+// SetMode sets the parse mode. The default is Strict.
+func (r *TagReader) SetMode(m ParseMode) {
+	r.mode = m
+}
+
+// Warnings returns warnings collected in Lenient mode.
+func (r *TagReader) Warnings() []Warning {
+	return r.warnings
+}
+
+// skip handles err which occurred while parsing the record data in section.
+// In Strict mode, it returns err as *ParseError.
+// In Lenient mode, it collects a warning and returns nil so that the record is skipped.
+func (r *TagReader) skip(section string, data []Tag, err error) error {
+	pe := parseError(section, data, err)
+	if r.mode == Strict {
+		return pe
+	}
+	msg := pe.Err.Error()
+	if pe.Code > 0 {
+		msg = fmt.Sprintf("code %d: %s", pe.Code, msg)
+	}
+	r.warnings = append(r.warnings, Warning{
+		Section: pe.Section,
+		Type:    pe.Type,
+		Handle:  pe.Handle,
+		Line:    pe.Line,
+		Message: msg,
+	})
+	return nil
+}
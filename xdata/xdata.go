@@ -0,0 +1,237 @@
+// Package xdata defines extended data (XDATA) of entities and table records.
+package xdata
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/flywave/go-dxf/format"
+	"github.com/flywave/go-dxf/handle"
+)
+
+// Value represents a value of XDATA with its group code.
+// The type of Value depends on the group code:
+//
+//	1000, 1002, 1003:      string
+//	1004:                  []byte
+//	1005:                  handle.Handler
+//	1010, 1011, 1012, 1013: []float64 (3D point)
+//	1040, 1041, 1042:      float64
+//	1070, 1071:            int
+type Value struct {
+	Code  int
+	Value interface{}
+}
+
+// String creates a string value (code 1000).
+func String(s string) Value {
+	return Value{1000, s}
+}
+
+// Control creates a control string value, "{" or "}" (code 1002).
+func Control(s string) Value {
+	return Value{1002, s}
+}
+
+// Layer creates a layer name value (code 1003).
+func Layer(name string) Value {
+	return Value{1003, name}
+}
+
+// Binary creates a binary chunk value (code 1004).
+func Binary(b []byte) Value {
+	return Value{1004, b}
+}
+
+// Handle creates a database handle value (code 1005).
+func Handle(h handle.Handler) Value {
+	return Value{1005, h}
+}
+
+// Point creates a 3D point value (code 1010).
+func Point(x, y, z float64) Value {
+	return Value{1010, []float64{x, y, z}}
+}
+
+// Position creates a 3D world space position value (code 1011).
+func Position(x, y, z float64) Value {
+	return Value{1011, []float64{x, y, z}}
+}
+
+// Displacement creates a 3D world space displacement value (code 1012).
+func Displacement(x, y, z float64) Value {
+	return Value{1012, []float64{x, y, z}}
+}
+
+// Direction creates a 3D world space direction value (code 1013).
+func Direction(x, y, z float64) Value {
+	return Value{1013, []float64{x, y, z}}
+}
+
+// Real creates a real value (code 1040).
+func Real(v float64) Value {
+	return Value{1040, v}
+}
+
+// Distance creates a distance value (code 1041).
+func Distance(v float64) Value {
+	return Value{1041, v}
+}
+
+// Scale creates a scale factor value (code 1042).
+func Scale(v float64) Value {
+	return Value{1042, v}
+}
+
+// Int16 creates a 16-bit integer value (code 1070).
+func Int16(v int) Value {
+	return Value{1070, v}
+}
+
+// Int32 creates a 32-bit integer value (code 1071).
+func Int32(v int) Value {
+	return Value{1071, v}
+}
+
+// Format writes data to formatter.
+func (v Value) Format(f format.Formatter) {
+	switch val := v.Value.(type) {
+	case string:
+		f.WriteString(v.Code, val)
+	case []byte:
+		f.WriteString(v.Code, strings.ToUpper(hex.EncodeToString(val)))
+	case handle.Handler:
+		f.WriteHex(v.Code, val.Handle())
+	case []float64:
+		for i := 0; i < 3; i++ {
+			f.WriteFloat(v.Code+i*10, val[i])
+		}
+	case float64:
+		f.WriteFloat(v.Code, val)
+	case int:
+		f.WriteInt(v.Code, val)
+	}
+}
+
+// XData holds XDATA grouped by registered application name.
+// It is embedded in entities and table records.
+// Applications are kept in the order they are set.
+type XData struct {
+	apps   []string
+	values map[string][]Value
+}
+
+// GetXData returns the values of application if exists.
+func (x *XData) GetXData(app string) ([]Value, error) {
+	if v, exist := x.values[app]; exist {
+		return v, nil
+	}
+	return nil, fmt.Errorf("xdata of %s doesn't exist", app)
+}
+
+// SetXData sets the values of application, replacing existing ones.
+func (x *XData) SetXData(app string, values ...Value) error {
+	if app == "" {
+		return fmt.Errorf("application name is blank")
+	}
+	for _, v := range values {
+		if err := v.check(); err != nil {
+			return err
+		}
+	}
+	if x.values == nil {
+		x.values = make(map[string][]Value)
+	}
+	if _, exist := x.values[app]; !exist {
+		x.apps = append(x.apps, app)
+	}
+	x.values[app] = values
+	return nil
+}
+
+// DeleteXData deletes the values of application.
+func (x *XData) DeleteXData(app string) {
+	if _, exist := x.values[app]; !exist {
+		return
+	}
+	delete(x.values, app)
+	for i, a := range x.apps {
+		if a == app {
+			x.apps = append(x.apps[:i:i], x.apps[i+1:]...)
+			break
+		}
+	}
+}
+
+// XDataApps returns names of applications which have XDATA.
+func (x *XData) XDataApps() []string {
+	apps := make([]string, len(x.apps))
+	copy(apps, x.apps)
+	return apps
+}
+
+// FormatXData writes XDATA to formatter.
+func (x *XData) FormatXData(f format.Formatter) {
+	for _, app := range x.apps {
+		f.WriteString(1001, app)
+		for _, v := range x.values[app] {
+			v.Format(f)
+		}
+	}
+}
+
+// MapXDataHandles replaces each handle value (code 1005) with the result of fn.
+func (x *XData) MapXDataHandles(fn func(handle.Handler) handle.Handler) {
+	for _, vs := range x.values {
+		for i, v := range vs {
+			if h, ok := v.Value.(handle.Handler); ok {
+				vs[i].Value = fn(h)
+			}
+		}
+	}
+}
+
+// CloneXData returns a copy of XDATA.
+// Handle values refer to the same objects.
+func (x *XData) CloneXData() XData {
+	c := XData{apps: x.XDataApps()}
+	if x.values != nil {
+		c.values = make(map[string][]Value, len(x.values))
+		for app, vs := range x.values {
+			cv := make([]Value, len(vs))
+			for i, v := range vs {
+				cv[i] = v
+				if p, ok := v.Value.([]float64); ok {
+					cv[i].Value = []float64{p[0], p[1], p[2]}
+				}
+			}
+			c.values[app] = cv
+		}
+	}
+	return c
+}
+
+// check reports an error if the type of value doesn't match its group code.
+func (v Value) check() error {
+	ok := false
+	switch v.Code {
+	case 1000, 1002, 1003:
+		_, ok = v.Value.(string)
+	case 1004:
+		_, ok = v.Value.([]byte)
+	case 1005:
+		_, ok = v.Value.(handle.Handler)
+	case 1010, 1011, 1012, 1013:
+		p, isPoint := v.Value.([]float64)
+		ok = isPoint && len(p) == 3
+	case 1040, 1041, 1042:
+		_, ok = v.Value.(float64)
+	case 1070, 1071:
+		_, ok = v.Value.(int)
+	}
+	if !ok {
+		return fmt.Errorf("xdata code %d: invalid value %v", v.Code, v.Value)
+	}
+	return nil
+}